@@ -0,0 +1,66 @@
+// Command config-doc prints every environment variable supported by the application configuration,
+// with its type, default value and whether it is required.
+//
+// Usage:
+//
+//	go run cmd/config-doc/main.go [-prefix APP] [-format table|json]
+//
+// The prefix defaults to the one the application uses, set by CONFIG_PREFIX or "APP".
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+)
+
+func main() {
+	// Default to the prefix the application loads its configuration with
+	prefix := flag.String("prefix", config.Prefix(), "environment variable prefix")
+	format := flag.String("format", "table", "output format (table, json)")
+	flag.Parse()
+
+	fields := config.Describe(*prefix)
+
+	var err error
+
+	switch *format {
+	case "table":
+		err = writeTable(os.Stdout, fields)
+	case "json":
+		err = writeJSON(os.Stdout, fields)
+	default:
+		log.Fatalf("unsupported format: %s", *format)
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to print configuration: %v", err)
+	}
+}
+
+// writeTable writes the fields as an aligned table.
+func writeTable(w io.Writer, fields []config.FieldDescriptor) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tREQUIRED")
+
+	for _, field := range fields {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", field.Name, field.Type, field.Default, field.Required)
+	}
+
+	return tw.Flush()
+}
+
+// writeJSON writes the fields as an indented JSON array.
+func writeJSON(w io.Writer, fields []config.FieldDescriptor) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(fields)
+}
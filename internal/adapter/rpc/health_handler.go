@@ -2,25 +2,83 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"time"
 
 	"connectrpc.com/grpchealth"
-	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
 )
 
+// DatabasePinger verifies the database connection. rdb.Database implements it.
+type DatabasePinger interface {
+	Ping(ctx context.Context) error
+}
+
+// SchemaVersionSource reports the version of the latest migration applied to the database.
+// rdb.Database implements it.
+type SchemaVersionSource interface {
+	AppliedSchemaVersion(ctx context.Context) (string, error)
+}
+
+// StatusDegraded is reported while the database is reachable but too slow to answer a ping within
+// the ping timeout, as opposed to StatusNotServing when it cannot be reached at all.
+// grpc.health.v1 has no degraded status, so it is UNKNOWN, which probes treat as not ready.
+const StatusDegraded = grpchealth.StatusUnknown
+
+// defaultPingTimeout bounds the database ping of a health check by default. It is short, since a
+// ping is trivial and a database slower than this is degraded.
+const defaultPingTimeout = time.Second
+
 // HealthCheckHandler implements grpchealth.Checker interface with database ping.
 type HealthCheckHandler struct {
-	db     *rdb.Database
-	logger *logging.Logger
+	db          DatabasePinger
+	logger      *logging.Logger
+	pingTimeout time.Duration
+
+	// schema is nil when the schema version is not checked
+	schema                SchemaVersionSource
+	expectedSchemaVersion string
+}
+
+// HealthCheckOption defines a function that configures the health check handler.
+type HealthCheckOption func(*HealthCheckHandler)
+
+// WithSchemaVersionCheck makes the health check report NOT_SERVING while the schema version applied
+// to the database is behind expected, e.g. the latest embedded migration, so that a new binary does not
+// serve against an old schema. A newer schema is accepted, since migrations must stay compatible with
+// the previous binary during rollouts.
+func WithSchemaVersionCheck(source SchemaVersionSource, expected string) HealthCheckOption {
+	return func(h *HealthCheckHandler) {
+		h.schema = source
+		h.expectedSchemaVersion = expected
+	}
+}
+
+// WithPingTimeout sets how long the database ping may take before the health check reports
+// StatusDegraded. It defaults to one second.
+func WithPingTimeout(timeout time.Duration) HealthCheckOption {
+	return func(h *HealthCheckHandler) {
+		if timeout > 0 {
+			h.pingTimeout = timeout
+		}
+	}
 }
 
 // NewHealthCheckHandler creates a new health check handler.
-func NewHealthCheckHandler(db *rdb.Database, logger *logging.Logger) *HealthCheckHandler {
-	return &HealthCheckHandler{
-		db:     db,
-		logger: logger,
+func NewHealthCheckHandler(db DatabasePinger, logger *logging.Logger, opts ...HealthCheckOption) *HealthCheckHandler {
+	h := &HealthCheckHandler{
+		db:          db,
+		logger:      logger,
+		pingTimeout: defaultPingTimeout,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // Check implements the grpchealth.Checker interface.
@@ -30,9 +88,11 @@ func (h *HealthCheckHandler) Check(ctx context.Context, req *grpchealth.CheckReq
 	// For service-specific checks, you can add logic here
 	// For now, we'll check the database connection for all services
 
-	if err := h.db.Ping(ctx); err != nil {
-		h.logger.Error(ctx, "Health check failed: database ping failed", err, slog.String("service", service))
+	if status, ok := h.pingDatabase(ctx, service); !ok {
+		return &grpchealth.CheckResponse{Status: status}, nil
+	}
 
+	if h.schema != nil && !h.schemaUpToDate(ctx, service) {
 		return &grpchealth.CheckResponse{Status: grpchealth.StatusNotServing}, nil
 	}
 
@@ -40,3 +100,54 @@ func (h *HealthCheckHandler) Check(ctx context.Context, req *grpchealth.CheckReq
 
 	return &grpchealth.CheckResponse{Status: grpchealth.StatusServing}, nil
 }
+
+// pingDatabase pings the database within the ping timeout and reports whether it answered, and the
+// status to report otherwise: StatusDegraded if the ping timed out, StatusNotServing if it failed,
+// e.g. because the connection was refused.
+func (h *HealthCheckHandler) pingDatabase(ctx context.Context, service string) (grpchealth.Status, bool) {
+	pingCtx, cancel := context.WithTimeout(ctx, h.pingTimeout)
+	defer cancel()
+
+	err := h.db.Ping(pingCtx)
+	if err == nil {
+		return grpchealth.StatusServing, true
+	}
+
+	// The driver may report the timeout as a network error, so the deadline of the ping decides
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(pingCtx.Err(), context.DeadlineExceeded) {
+		h.logger.Warn(ctx, "Health check degraded: database ping timed out",
+			slog.String("service", service),
+			slog.String(attr.Error, err.Error()),
+		)
+
+		return StatusDegraded, false
+	}
+
+	h.logger.Error(ctx, "Health check failed: database ping failed", err, slog.String("service", service))
+
+	return grpchealth.StatusNotServing, false
+}
+
+// schemaUpToDate reports whether the applied schema version is at least the expected one,
+// logging the reason if it is not.
+func (h *HealthCheckHandler) schemaUpToDate(ctx context.Context, service string) bool {
+	applied, err := h.schema.AppliedSchemaVersion(ctx)
+	if err != nil {
+		h.logger.Error(ctx, "Health check failed: schema version unavailable", err, slog.String("service", service))
+
+		return false
+	}
+
+	// Versions are timestamps of the same length, so they compare lexically
+	if applied < h.expectedSchemaVersion {
+		h.logger.Warn(ctx, "Health check failed: database schema is behind",
+			slog.String("service", service),
+			slog.String("applied_version", applied),
+			slog.String("expected_version", h.expectedSchemaVersion),
+		)
+
+		return false
+	}
+
+	return true
+}
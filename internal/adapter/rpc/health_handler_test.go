@@ -0,0 +1,128 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"connectrpc.com/grpchealth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/internal/adapter/rpc"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+// stubDatabase is a database with a stubbed ping result and schema_migrations table.
+type stubDatabase struct {
+	pingErr        error
+	pingHangs      bool // the ping answers only when its context is done, like an overloaded database
+	appliedVersion string
+	versionErr     error
+}
+
+func (d *stubDatabase) Ping(ctx context.Context) error {
+	if d.pingHangs {
+		<-ctx.Done()
+
+		if d.pingErr != nil {
+			return d.pingErr
+		}
+
+		return ctx.Err()
+	}
+
+	return d.pingErr
+}
+
+func (d *stubDatabase) AppliedSchemaVersion(context.Context) (string, error) {
+	return d.appliedVersion, d.versionErr
+}
+
+func TestHealthCheckHandler_Check(t *testing.T) {
+	t.Parallel()
+
+	const expectedVersion = "20250102000000"
+
+	tests := []struct {
+		name        string
+		db          *stubDatabase
+		checkSchema bool
+		want        grpchealth.Status
+	}{
+		{
+			name: "report serving when database is reachable",
+			db:   &stubDatabase{},
+			want: grpchealth.StatusServing,
+		},
+		{
+			name: "report not serving when database ping fails",
+			db:   &stubDatabase{pingErr: errors.New("connection refused")},
+			want: grpchealth.StatusNotServing,
+		},
+		{
+			name: "report degraded when database ping times out",
+			db:   &stubDatabase{pingHangs: true},
+			want: rpc.StatusDegraded,
+		},
+		{
+			name: "report degraded when driver reports ping timeout as network error",
+			db:   &stubDatabase{pingHangs: true, pingErr: errors.New("read tcp: i/o timeout")},
+			want: rpc.StatusDegraded,
+		},
+		{
+			name: "ignore schema version without schema check",
+			db:   &stubDatabase{appliedVersion: "20250101000000"},
+			want: grpchealth.StatusServing,
+		},
+		{
+			name:        "report serving when schema is at expected version",
+			db:          &stubDatabase{appliedVersion: expectedVersion},
+			checkSchema: true,
+			want:        grpchealth.StatusServing,
+		},
+		{
+			name:        "report serving when schema is ahead of expected version",
+			db:          &stubDatabase{appliedVersion: "20250103000000"},
+			checkSchema: true,
+			want:        grpchealth.StatusServing,
+		},
+		{
+			name:        "report not serving when schema is behind expected version",
+			db:          &stubDatabase{appliedVersion: "20250101000000"},
+			checkSchema: true,
+			want:        grpchealth.StatusNotServing,
+		},
+		{
+			name:        "report not serving when no migration is applied",
+			db:          &stubDatabase{appliedVersion: ""},
+			checkSchema: true,
+			want:        grpchealth.StatusNotServing,
+		},
+		{
+			name:        "report not serving when schema version is unavailable",
+			db:          &stubDatabase{versionErr: errors.New(`relation "schema_migrations" does not exist`)},
+			checkSchema: true,
+			want:        grpchealth.StatusNotServing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []rpc.HealthCheckOption{rpc.WithPingTimeout(10 * time.Millisecond)}
+			if tt.checkSchema {
+				opts = append(opts, rpc.WithSchemaVersionCheck(tt.db, expectedVersion))
+			}
+
+			handler := rpc.NewHealthCheckHandler(tt.db, logging.New(logging.WithWriter(io.Discard)), opts...)
+
+			resp, err := handler.Check(context.Background(), &grpchealth.CheckRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Status)
+		})
+	}
+}
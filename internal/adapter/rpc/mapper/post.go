@@ -1,9 +1,8 @@
 package mapper
 
 import (
-	"time"
-
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	proto "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/entity/v1"
 )
 
@@ -24,14 +23,17 @@ func PostToProto(post *entity.Post) *proto.Post {
 }
 
 // PostFromProto converts protobuf Post to domain Post entity.
-func PostFromProto(protoPost *proto.Post) *entity.Post {
+// The timestamps are set to the current time of clk.
+func PostFromProto(protoPost *proto.Post, clk clock.Clock) *entity.Post {
 	if protoPost == nil {
 		return nil
 	}
 
+	now := clk.Now()
+
 	post := &entity.Post{
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	if protoPost.Id != nil {
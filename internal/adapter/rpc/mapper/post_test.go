@@ -0,0 +1,51 @@
+package mapper_test
+
+import (
+	"testing"
+
+	proto "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/entity/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/internal/adapter/rpc/mapper"
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+)
+
+func TestPostFromProto(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		protoPost *proto.Post
+		want      *entity.Post
+	}{
+		{
+			name: "convert post with timestamps from clock",
+			protoPost: &proto.Post{
+				Id:    &proto.PostId{Value: "post-123"},
+				Title: &proto.PostTitle{Value: "Test Post"},
+			},
+			want: &entity.Post{
+				ID:        "post-123",
+				Title:     "Test Post",
+				CreatedAt: fakeTime,
+				UpdatedAt: fakeTime,
+			},
+		},
+		{
+			name:      "return nil for nil post",
+			protoPost: nil,
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := mapper.PostFromProto(tt.protoPost, clock.NewFake(fakeTime))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
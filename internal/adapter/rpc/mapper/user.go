@@ -1,9 +1,8 @@
 package mapper
 
 import (
-	"time"
-
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	proto "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/entity/v1"
 )
 
@@ -27,14 +26,17 @@ func UserToProto(user *entity.User) *proto.User {
 }
 
 // UserFromProto converts protobuf User to domain User entity.
-func UserFromProto(protoUser *proto.User) *entity.User {
+// The timestamps are set to the current time of clk.
+func UserFromProto(protoUser *proto.User, clk clock.Clock) *entity.User {
 	if protoUser == nil {
 		return nil
 	}
 
+	now := clk.Now()
+
 	user := &entity.User{
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	if protoUser.Id != nil {
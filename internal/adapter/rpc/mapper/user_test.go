@@ -0,0 +1,56 @@
+package mapper_test
+
+import (
+	"testing"
+	"time"
+
+	proto "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/entity/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/internal/adapter/rpc/mapper"
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+)
+
+var fakeTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestUserFromProto(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		protoUser *proto.User
+		want      *entity.User
+	}{
+		{
+			name: "convert user with timestamps from clock",
+			protoUser: &proto.User{
+				Id:    &proto.UserId{Value: "user-123"},
+				Name:  &proto.UserName{Value: "John Doe"},
+				Email: &proto.UserEmail{Value: "john@example.com"},
+			},
+			want: &entity.User{
+				ID:        "user-123",
+				Name:      "John Doe",
+				Email:     "john@example.com",
+				CreatedAt: fakeTime,
+				UpdatedAt: fakeTime,
+			},
+		},
+		{
+			name:      "return nil for nil user",
+			protoUser: nil,
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := mapper.UserFromProto(tt.protoUser, clock.NewFake(fakeTime))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
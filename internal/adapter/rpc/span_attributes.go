@@ -0,0 +1,26 @@
+package rpc
+
+import (
+	api "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/api/v1"
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TargetIDSpanAttributes returns the ID of the entity targeted by a Get request as a span attribute,
+// keyed like the log attribute, e.g. post_id for GetPost. Other requests have no attributes.
+// Use it with server.NewSpanAttributesInterceptor.
+func TargetIDSpanAttributes(req connect.AnyRequest) []attribute.KeyValue {
+	switch msg := req.Any().(type) {
+	case *api.GetUserRequest:
+		if id := msg.GetUserId().GetValue(); id != "" {
+			return []attribute.KeyValue{attribute.String(attr.UserIDKey, id)}
+		}
+	case *api.GetPostRequest:
+		if id := msg.GetPostId().GetValue(); id != "" {
+			return []attribute.KeyValue{attribute.String(attr.PostIDKey, id)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package rpc_test
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+
+	api "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/api/v1"
+	proto "buf.build/gen/go/pannpers/scaffold/protocolbuffers/go/pannpers/entity/v1"
+
+	"github.com/pannpers/go-backend-scaffold/internal/adapter/rpc"
+)
+
+func TestTargetIDSpanAttributes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		req  connect.AnyRequest
+		want []attribute.KeyValue
+	}{
+		{
+			name: "extract user ID from GetUser request",
+			req:  connect.NewRequest(&api.GetUserRequest{UserId: &proto.UserId{Value: "user-123"}}),
+			want: []attribute.KeyValue{attribute.String("user_id", "user-123")},
+		},
+		{
+			name: "extract post ID from GetPost request",
+			req:  connect.NewRequest(&api.GetPostRequest{PostId: &proto.PostId{Value: "post-456"}}),
+			want: []attribute.KeyValue{attribute.String("post_id", "post-456")},
+		},
+		{
+			name: "return nothing for Get request without ID",
+			req:  connect.NewRequest(&api.GetPostRequest{}),
+			want: nil,
+		},
+		{
+			name: "return nothing for other requests",
+			req:  connect.NewRequest(&api.CreatePostRequest{}),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, rpc.TargetIDSpanAttributes(tt.req))
+		})
+	}
+}
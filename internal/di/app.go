@@ -9,12 +9,15 @@ import (
 
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/server"
+	"github.com/pannpers/go-backend-scaffold/internal/usecase"
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
 )
 
-func newApp(server *server.ConnectServer, db *rdb.Database, telemetryCloser io.Closer) *App {
+func newApp(server *server.ConnectServer, db *rdb.Database, telemetryCloser io.Closer, watcher *configWatcher, bus *event.Bus, postUseCase *usecase.PostUseCase) *App {
 	return &App{
-		Server:  server,
-		Closers: []io.Closer{db, telemetryCloser},
+		Server: server,
+		// Notifications and the event bus are closed before the database so in-flight work can still use it
+		Closers: []io.Closer{watcher, postUseCase, bus, db, telemetryCloser},
 	}
 }
 
@@ -29,7 +32,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 	var errs error
 
 	// First, stop the server gracefully
-	if err := a.Server.Stop(); err != nil {
+	if err := a.Server.Stop(ctx); err != nil {
 		errs = errors.Join(errs, fmt.Errorf("failed to graceful shutdown server: %w", err))
 	}
 
@@ -2,6 +2,7 @@ package di
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -12,17 +13,24 @@ import (
 	"github.com/pannpers/go-backend-scaffold/internal/adapter/rpc"
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb/migrations"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/repository"
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/server"
 	"github.com/pannpers/go-backend-scaffold/internal/usecase"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
 	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+	"go.opentelemetry.io/otel"
 	v1connect "buf.build/gen/go/pannpers/scaffold/connectrpc/go/pannpers/api/v1/apiv1connect"
 )
 
-// provideConfig creates a new config instance.
+// provideConfig creates a new config instance, loaded with the prefix set by CONFIG_PREFIX or "APP" by default.
 func provideConfig() (*config.Config, error) {
-	return config.Load("")
+	return config.Load(config.Prefix())
 }
 
 // provideLogger creates a new logger instance based on config.
@@ -30,15 +38,8 @@ func provideLogger(cfg *config.Config) *logging.Logger {
 	var opts []logging.Option
 
 	// Set log level based on config
-	switch cfg.Logging.Level {
-	case "debug":
-		opts = append(opts, logging.WithLevel(slog.LevelDebug))
-	case "info":
-		opts = append(opts, logging.WithLevel(slog.LevelInfo))
-	case "warn":
-		opts = append(opts, logging.WithLevel(slog.LevelWarn))
-	case "error":
-		opts = append(opts, logging.WithLevel(slog.LevelError))
+	if level, ok := parseLogLevel(cfg.Logging.Level); ok {
+		opts = append(opts, logging.WithLevel(level))
 	}
 
 	// Set log format based on config
@@ -47,41 +48,138 @@ func provideLogger(cfg *config.Config) *logging.Logger {
 		opts = append(opts, logging.WithFormat(logging.FormatText))
 	case "json":
 		opts = append(opts, logging.WithFormat(logging.FormatJSON))
+	case "gcp":
+		opts = append(opts, logging.WithFormat(logging.FormatGCP), logging.WithGCPProjectID(cfg.Logging.GCPProjectID))
+	}
+
+	// Identify this service in logs aggregated from several services
+	opts = append(opts, logging.WithBaseAttrs(
+		slog.String("service", cfg.Telemetry.ServiceName),
+		slog.String("version", cfg.Telemetry.ServiceVersion),
+		slog.String("environment", cfg.Environment),
+	))
+
+	opts = append(opts, logging.WithTraceAttrs(cfg.Logging.IncludeTrace))
+
+	if cfg.Logging.IncludeCaller {
+		opts = append(opts, logging.WithSource())
 	}
 
 	return logging.New(opts...)
 }
 
-// provideDatabase creates a new database instance.
+// parseLogLevel converts a configured log level into a slog.Level.
+func parseLogLevel(level string) (slog.Level, bool) {
+	switch level {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+
+	return 0, false
+}
+
+// configWatcher stops watching for configuration reloads when closed.
+type configWatcher struct {
+	stop func()
+}
+
+// Close stops watching for configuration reloads.
+func (w *configWatcher) Close() error {
+	w.stop()
+
+	return nil
+}
+
+// provideConfigWatcher reloads the configuration on SIGHUP and applies the new log level to the logger.
+// Invalid reloads are logged at Warn and leave the current configuration in effect.
+func provideConfigWatcher(logger *logging.Logger) *configWatcher {
+	stop := config.Watch(config.Prefix(), func(cfg *config.Config) {
+		if level, ok := parseLogLevel(cfg.Logging.Level); ok {
+			logger.SetLevel(level)
+		}
+	}, func(err error) {
+		logger.Warn(context.Background(), "Ignoring configuration reload",
+			slog.String(attr.Error, err.Error()),
+		)
+	})
+
+	return &configWatcher{stop: stop}
+}
+
+// provideUseCaseOptions returns the options shared by all use cases.
+func provideUseCaseOptions(clk clock.Clock, bus *event.Bus) []usecase.Option {
+	return []usecase.Option{
+		usecase.WithClock(clk),
+		usecase.WithEventPublisher(bus),
+	}
+}
+
+// provideDatabase creates a new database instance, applying pending migrations first if enabled.
 func provideDatabase(ctx context.Context, cfg *config.Config, logger *logging.Logger) (*rdb.Database, error) {
-	return rdb.New(ctx, cfg, logger)
+	db, err := rdb.New(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Database.MigrateOnStart {
+		if err := rdb.Migrate(ctx, db, migrations.Versions()); err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	return db, nil
 }
 
 // provideTelemetry creates a new telemetry instance and returns the closer.
-func provideTelemetry(ctx context.Context, cfg *config.Config) (io.Closer, error) {
-	return telemetry.SetupTelemetry(ctx, cfg)
+func provideTelemetry(ctx context.Context, cfg *config.Config, logger *logging.Logger) (io.Closer, error) {
+	return telemetry.SetupTelemetry(ctx, cfg, telemetry.WithLogger(logger))
 }
 
-func provideHandlerFuncs(logger *logging.Logger, db *rdb.Database, userUseCase *usecase.UserUseCase, postUseCase *usecase.PostUseCase) []server.RPCHandlerFunc {
+func provideHandlerFuncs(cfg *config.Config, logger *logging.Logger, db *rdb.Database, userUseCase *usecase.UserUseCase, postUseCase *usecase.PostUseCase) []server.RPCHandlerFunc {
+	var healthOpts []rpc.HealthCheckOption
+	if cfg.Database.CheckSchemaVersion {
+		healthOpts = append(healthOpts,
+			rpc.WithSchemaVersionCheck(db, rdb.LatestMigrationVersion(migrations.Versions())),
+		)
+	}
+
 	return []server.RPCHandlerFunc{
 		func(opts ...connect.HandlerOption) (string, http.Handler) {
 			return grpchealth.NewHandler(
-				rpc.NewHealthCheckHandler(db, logger),
-				opts...,
-			)
-		},
-		func(opts ...connect.HandlerOption) (string, http.Handler) {
-			return v1connect.NewUserServiceHandler(
-				rpc.NewUserHandler(userUseCase, logger),
-				opts...,
-			)
-		},
-		func(opts ...connect.HandlerOption) (string, http.Handler) {
-			return v1connect.NewPostServiceHandler(
-				rpc.NewPostHandler(postUseCase, logger),
+				rpc.NewHealthCheckHandler(db, logger, healthOpts...),
 				opts...,
 			)
 		},
+		// User account changes are recorded in the audit log
+		server.WithHandlerInterceptors(
+			func(opts ...connect.HandlerOption) (string, http.Handler) {
+				return v1connect.NewUserServiceHandler(
+					rpc.NewUserHandler(userUseCase, logger),
+					opts...,
+				)
+			},
+			server.NewRequiredHeaderInterceptor(tenant.Header),
+			server.NewSpanAttributesInterceptor(rpc.TargetIDSpanAttributes),
+			server.NewAuditLogInterceptor(server.NewLogAuditSink(logger)),
+		),
+		server.WithHandlerInterceptors(
+			func(opts ...connect.HandlerOption) (string, http.Handler) {
+				return v1connect.NewPostServiceHandler(
+					rpc.NewPostHandler(postUseCase, logger),
+					opts...,
+				)
+			},
+			server.NewRequiredHeaderInterceptor(tenant.Header),
+			server.NewSpanAttributesInterceptor(rpc.TargetIDSpanAttributes),
+		),
 	}
 }
 
@@ -111,6 +209,34 @@ func (m *MockUserRepository) Get(ctx context.Context, id string) (*entity.User,
 	}, nil
 }
 
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return &entity.User{
+		ID:        "mock-user-id",
+		Name:      "Mock User",
+		Email:     email,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func (m *MockUserRepository) Upsert(ctx context.Context, params *entity.NewUser) (*entity.User, bool, error) {
+	return &entity.User{
+		ID:        "mock-user-id",
+		Name:      params.Name,
+		Email:     params.Email,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, true, nil
+}
+
+func (m *MockUserRepository) Exists(ctx context.Context, id string) (bool, error) {
+	return true, nil
+}
+
+func (m *MockUserRepository) List(ctx context.Context, params *entity.ListParams) ([]*entity.User, string, error) {
+	return []*entity.User{}, "", nil
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
@@ -138,16 +264,63 @@ func (m *MockPostRepository) Get(ctx context.Context, id string) (*entity.Post,
 	}, nil
 }
 
+func (m *MockPostRepository) GetWithAuthor(ctx context.Context, id string) (*entity.Post, *entity.User, error) {
+	post, _ := m.Get(ctx, id)
+	user, _ := (&MockUserRepository{}).Get(ctx, post.UserID)
+
+	return post, user, nil
+}
+
+func (m *MockPostRepository) List(ctx context.Context, params *entity.ListParams) ([]*entity.Post, string, error) {
+	return []*entity.Post{}, "", nil
+}
+
+func (m *MockPostRepository) Search(ctx context.Context, query string, limit int) ([]*entity.Post, error) {
+	return []*entity.Post{}, nil
+}
+
 func (m *MockPostRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *MockPostRepository) DeleteByOwner(ctx context.Context, postID, userID string) error {
+	return nil
+}
+
+// repositoryMeterName is the name of the meter recording repository metrics.
+const repositoryMeterName = "github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/repository"
+
+// provideRepositoryDecorators returns the decorators enabled by the config, outermost first:
+// metrics measure every call including cache hits, and only cache misses are retried.
+func provideRepositoryDecorators(cfg *config.Config) ([]repository.Decorator, error) {
+	var decorators []repository.Decorator
+
+	if cfg.Telemetry.MetricsEnabled {
+		metrics, err := repository.WithMetrics(otel.GetMeterProvider().Meter(repositoryMeterName))
+		if err != nil {
+			return nil, err
+		}
+
+		decorators = append(decorators, metrics)
+	}
+
+	if cfg.Database.CacheTTL > 0 {
+		decorators = append(decorators, repository.WithCache(cfg.Database.CacheTTL))
+	}
+
+	if cfg.Database.RetryMaxAttempts > 1 {
+		decorators = append(decorators, repository.WithRetry(cfg.Database.RetryMaxAttempts, cfg.Database.RetryBackoff))
+	}
+
+	return decorators, nil
+}
+
 // provideUserRepository creates a user repository implementation using the database.
-func provideUserRepository(db *rdb.Database) entity.UserRepository {
-	return rdb.NewUserRepository(db)
+func provideUserRepository(db *rdb.Database, decorators []repository.Decorator) entity.UserRepository {
+	return repository.DecorateUserRepository(rdb.NewUserRepository(db), decorators...)
 }
 
 // providePostRepository creates a post repository implementation using the database.
-func providePostRepository(db *rdb.Database) entity.PostRepository {
-	return rdb.NewPostRepository(db)
+func providePostRepository(db *rdb.Database, decorators []repository.Decorator) entity.PostRepository {
+	return repository.DecoratePostRepository(rdb.NewPostRepository(db), decorators...)
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/google/wire"
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/server"
 	"github.com/pannpers/go-backend-scaffold/internal/usecase"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
 )
 
 // InitializeApp creates a new App with all dependencies wired up.
@@ -20,12 +22,17 @@ func InitializeApp(ctx context.Context) (*App, error) {
 		provideConfig,
 		provideLogger,
 		provideTelemetry,
+		provideConfigWatcher,
+		event.NewBus,
 
 		// Repository layer
+		provideRepositoryDecorators,
 		provideUserRepository,
 		providePostRepository,
 
 		// Use case layer
+		clock.New,
+		provideUseCaseOptions,
 		usecase.NewUserUseCase,
 		usecase.NewPostUseCase,
 
@@ -10,6 +10,8 @@ import (
 	"context"
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/server"
 	"github.com/pannpers/go-backend-scaffold/internal/usecase"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
 )
 
 // Injectors from wire.go:
@@ -25,16 +27,24 @@ func InitializeApp(ctx context.Context) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	userRepository := provideUserRepository(database)
-	userUseCase := usecase.NewUserUseCase(userRepository, logger)
-	postRepository := providePostRepository(database)
-	postUseCase := usecase.NewPostUseCase(postRepository, logger)
-	v := provideHandlerFuncs(logger, database, userUseCase, postUseCase)
-	connectServer := server.NewConnectServer(config, logger, database, v...)
-	closer, err := provideTelemetry(ctx, config)
+	v, err := provideRepositoryDecorators(config)
 	if err != nil {
 		return nil, err
 	}
-	app := newApp(connectServer, database, closer)
+	userRepository := provideUserRepository(database, v)
+	clockClock := clock.New()
+	bus := event.NewBus(logger)
+	v2 := provideUseCaseOptions(clockClock, bus)
+	userUseCase := usecase.NewUserUseCase(userRepository, logger, v2...)
+	postRepository := providePostRepository(database, v)
+	postUseCase := usecase.NewPostUseCase(postRepository, logger, v2...)
+	v3 := provideHandlerFuncs(config, logger, database, userUseCase, postUseCase)
+	connectServer := server.NewConnectServer(config, logger, database, v3...)
+	closer, err := provideTelemetry(ctx, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	diConfigWatcher := provideConfigWatcher(logger)
+	app := newApp(connectServer, database, closer, diConfigWatcher, bus, postUseCase)
 	return app, nil
 }
@@ -0,0 +1,14 @@
+package entity
+
+// UserCreatedEvent is the name of the UserCreated event.
+const UserCreatedEvent = "user.created"
+
+// UserCreated is published after a user has been created.
+type UserCreated struct {
+	User *User
+}
+
+// EventName returns UserCreatedEvent.
+func (UserCreated) EventName() string {
+	return UserCreatedEvent
+}
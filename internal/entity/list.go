@@ -0,0 +1,27 @@
+package entity
+
+// Page size bounds for list operations.
+const (
+	// DefaultListLimit is the page size used when ListParams.Limit is zero.
+	DefaultListLimit = 50
+	// MaxListLimit is the largest page size; larger limits are capped to it.
+	MaxListLimit = 100
+)
+
+// ListParams represents pagination parameters for list operations.
+//
+// List operations return a page of items and the cursor of the next page, which is empty on
+// the last page. An empty result is not an error: it is an empty slice with an empty cursor,
+// never codes.NotFound.
+//
+// Whether more items exist is whether the cursor is non-empty. Repositories derive it by fetching
+// one item more than the limit rather than counting, so a page that ends exactly at the last item
+// has no cursor. No total is computed, since counting every list call is not cheap.
+type ListParams struct {
+	Limit  int    // Maximum number of items to return
+	Cursor string // Cursor returned with the previous page, empty for the first page
+
+	// IncludeUnpublished also lists drafts and scheduled posts, which are hidden by default.
+	// It is ignored when listing other entities.
+	IncludeUnpublished bool
+}
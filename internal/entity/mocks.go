@@ -161,6 +161,69 @@ func (_c *MockPostRepository_Delete_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// DeleteByOwner provides a mock function for the type MockPostRepository
+func (_mock *MockPostRepository) DeleteByOwner(ctx context.Context, postID string, userID string) error {
+	ret := _mock.Called(ctx, postID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByOwner")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, postID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPostRepository_DeleteByOwner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByOwner'
+type MockPostRepository_DeleteByOwner_Call struct {
+	*mock.Call
+}
+
+// DeleteByOwner is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID string
+//   - userID string
+func (_e *MockPostRepository_Expecter) DeleteByOwner(ctx interface{}, postID interface{}, userID interface{}) *MockPostRepository_DeleteByOwner_Call {
+	return &MockPostRepository_DeleteByOwner_Call{Call: _e.mock.On("DeleteByOwner", ctx, postID, userID)}
+}
+
+func (_c *MockPostRepository_DeleteByOwner_Call) Run(run func(ctx context.Context, postID string, userID string)) *MockPostRepository_DeleteByOwner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostRepository_DeleteByOwner_Call) Return(err error) *MockPostRepository_DeleteByOwner_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPostRepository_DeleteByOwner_Call) RunAndReturn(run func(ctx context.Context, postID string, userID string) error) *MockPostRepository_DeleteByOwner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function for the type MockPostRepository
 func (_mock *MockPostRepository) Get(ctx context.Context, id string) (*Post, error) {
 	ret := _mock.Called(ctx, id)
@@ -229,6 +292,230 @@ func (_c *MockPostRepository_Get_Call) RunAndReturn(run func(ctx context.Context
 	return _c
 }
 
+// GetWithAuthor provides a mock function for the type MockPostRepository
+func (_mock *MockPostRepository) GetWithAuthor(ctx context.Context, id string) (*Post, *User, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWithAuthor")
+	}
+
+	var r0 *Post
+	var r1 *User
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*Post, *User, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *Post); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) *User); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*User)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockPostRepository_GetWithAuthor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWithAuthor'
+type MockPostRepository_GetWithAuthor_Call struct {
+	*mock.Call
+}
+
+// GetWithAuthor is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockPostRepository_Expecter) GetWithAuthor(ctx interface{}, id interface{}) *MockPostRepository_GetWithAuthor_Call {
+	return &MockPostRepository_GetWithAuthor_Call{Call: _e.mock.On("GetWithAuthor", ctx, id)}
+}
+
+func (_c *MockPostRepository_GetWithAuthor_Call) Run(run func(ctx context.Context, id string)) *MockPostRepository_GetWithAuthor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostRepository_GetWithAuthor_Call) Return(post *Post, user *User, err error) *MockPostRepository_GetWithAuthor_Call {
+	_c.Call.Return(post, user, err)
+	return _c
+}
+
+func (_c *MockPostRepository_GetWithAuthor_Call) RunAndReturn(run func(ctx context.Context, id string) (*Post, *User, error)) *MockPostRepository_GetWithAuthor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockPostRepository
+func (_mock *MockPostRepository) List(ctx context.Context, params *ListParams) ([]*Post, string, error) {
+	ret := _mock.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*Post
+	var r1 string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *ListParams) ([]*Post, string, error)); ok {
+		return returnFunc(ctx, params)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *ListParams) []*Post); ok {
+		r0 = returnFunc(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *ListParams) string); ok {
+		r1 = returnFunc(ctx, params)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *ListParams) error); ok {
+		r2 = returnFunc(ctx, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockPostRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockPostRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *ListParams
+func (_e *MockPostRepository_Expecter) List(ctx interface{}, params interface{}) *MockPostRepository_List_Call {
+	return &MockPostRepository_List_Call{Call: _e.mock.On("List", ctx, params)}
+}
+
+func (_c *MockPostRepository_List_Call) Run(run func(ctx context.Context, params *ListParams)) *MockPostRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *ListParams
+		if args[1] != nil {
+			arg1 = args[1].(*ListParams)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostRepository_List_Call) Return(posts []*Post, s string, err error) *MockPostRepository_List_Call {
+	_c.Call.Return(posts, s, err)
+	return _c
+}
+
+func (_c *MockPostRepository_List_Call) RunAndReturn(run func(ctx context.Context, params *ListParams) ([]*Post, string, error)) *MockPostRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function for the type MockPostRepository
+func (_mock *MockPostRepository) Search(ctx context.Context, query string, limit int) ([]*Post, error) {
+	ret := _mock.Called(ctx, query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) ([]*Post, error)); ok {
+		return returnFunc(ctx, query, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) []*Post); ok {
+		r0 = returnFunc(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = returnFunc(ctx, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostRepository_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockPostRepository_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - limit int
+func (_e *MockPostRepository_Expecter) Search(ctx interface{}, query interface{}, limit interface{}) *MockPostRepository_Search_Call {
+	return &MockPostRepository_Search_Call{Call: _e.mock.On("Search", ctx, query, limit)}
+}
+
+func (_c *MockPostRepository_Search_Call) Run(run func(ctx context.Context, query string, limit int)) *MockPostRepository_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostRepository_Search_Call) Return(posts []*Post, err error) *MockPostRepository_Search_Call {
+	_c.Call.Return(posts, err)
+	return _c
+}
+
+func (_c *MockPostRepository_Search_Call) RunAndReturn(run func(ctx context.Context, query string, limit int) ([]*Post, error)) *MockPostRepository_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockUserRepository creates a new instance of MockUserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockUserRepository(t interface {
@@ -381,6 +668,72 @@ func (_c *MockUserRepository_Delete_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// Exists provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) Exists(ctx context.Context, id string) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_Exists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Exists'
+type MockUserRepository_Exists_Call struct {
+	*mock.Call
+}
+
+// Exists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserRepository_Expecter) Exists(ctx interface{}, id interface{}) *MockUserRepository_Exists_Call {
+	return &MockUserRepository_Exists_Call{Call: _e.mock.On("Exists", ctx, id)}
+}
+
+func (_c *MockUserRepository_Exists_Call) Run(run func(ctx context.Context, id string)) *MockUserRepository_Exists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_Exists_Call) Return(b bool, err error) *MockUserRepository_Exists_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockUserRepository_Exists_Call) RunAndReturn(run func(ctx context.Context, id string) (bool, error)) *MockUserRepository_Exists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function for the type MockUserRepository
 func (_mock *MockUserRepository) Get(ctx context.Context, id string) (*User, error) {
 	ret := _mock.Called(ctx, id)
@@ -448,3 +801,219 @@ func (_c *MockUserRepository_Get_Call) RunAndReturn(run func(ctx context.Context
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetByEmail provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByEmail")
+	}
+
+	var r0 *User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*User, error)); ok {
+		return returnFunc(ctx, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *User); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_GetByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByEmail'
+type MockUserRepository_GetByEmail_Call struct {
+	*mock.Call
+}
+
+// GetByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *MockUserRepository_Expecter) GetByEmail(ctx interface{}, email interface{}) *MockUserRepository_GetByEmail_Call {
+	return &MockUserRepository_GetByEmail_Call{Call: _e.mock.On("GetByEmail", ctx, email)}
+}
+
+func (_c *MockUserRepository_GetByEmail_Call) Run(run func(ctx context.Context, email string)) *MockUserRepository_GetByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_GetByEmail_Call) Return(user *User, err error) *MockUserRepository_GetByEmail_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockUserRepository_GetByEmail_Call) RunAndReturn(run func(ctx context.Context, email string) (*User, error)) *MockUserRepository_GetByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) List(ctx context.Context, params *ListParams) ([]*User, string, error) {
+	ret := _mock.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*User
+	var r1 string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *ListParams) ([]*User, string, error)); ok {
+		return returnFunc(ctx, params)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *ListParams) []*User); ok {
+		r0 = returnFunc(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *ListParams) string); ok {
+		r1 = returnFunc(ctx, params)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *ListParams) error); ok {
+		r2 = returnFunc(ctx, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockUserRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockUserRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *ListParams
+func (_e *MockUserRepository_Expecter) List(ctx interface{}, params interface{}) *MockUserRepository_List_Call {
+	return &MockUserRepository_List_Call{Call: _e.mock.On("List", ctx, params)}
+}
+
+func (_c *MockUserRepository_List_Call) Run(run func(ctx context.Context, params *ListParams)) *MockUserRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *ListParams
+		if args[1] != nil {
+			arg1 = args[1].(*ListParams)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_List_Call) Return(users []*User, s string, err error) *MockUserRepository_List_Call {
+	_c.Call.Return(users, s, err)
+	return _c
+}
+
+func (_c *MockUserRepository_List_Call) RunAndReturn(run func(ctx context.Context, params *ListParams) ([]*User, string, error)) *MockUserRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) Upsert(ctx context.Context, params *NewUser) (*User, bool, error) {
+	ret := _mock.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 *User
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *NewUser) (*User, bool, error)); ok {
+		return returnFunc(ctx, params)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *NewUser) *User); ok {
+		r0 = returnFunc(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *NewUser) bool); ok {
+		r1 = returnFunc(ctx, params)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *NewUser) error); ok {
+		r2 = returnFunc(ctx, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockUserRepository_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockUserRepository_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *NewUser
+func (_e *MockUserRepository_Expecter) Upsert(ctx interface{}, params interface{}) *MockUserRepository_Upsert_Call {
+	return &MockUserRepository_Upsert_Call{Call: _e.mock.On("Upsert", ctx, params)}
+}
+
+func (_c *MockUserRepository_Upsert_Call) Run(run func(ctx context.Context, params *NewUser)) *MockUserRepository_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *NewUser
+		if args[1] != nil {
+			arg1 = args[1].(*NewUser)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_Upsert_Call) Return(user *User, created bool, err error) *MockUserRepository_Upsert_Call {
+	_c.Call.Return(user, created, err)
+	return _c
+}
+
+func (_c *MockUserRepository_Upsert_Call) RunAndReturn(run func(ctx context.Context, params *NewUser) (*User, bool, error)) *MockUserRepository_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
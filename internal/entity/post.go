@@ -12,17 +12,75 @@ type Post struct {
 	UserID    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// PublishedAt is when the post is published, nil while it is an unpublished draft.
+	// A post with a future PublishedAt is scheduled, and hidden from lists until then like a draft.
+	PublishedAt *time.Time
+}
+
+// IsPublished reports whether the post is published at now.
+func (p *Post) IsPublished(now time.Time) bool {
+	return p.PublishedAt != nil && !p.PublishedAt.After(now)
+}
+
+// Diff returns the fields that differ between p and other, keyed by their snake_case names
+// and mapped to the values in other. It returns an empty map if both are identical.
+func (p *Post) Diff(other *Post) map[string]any {
+	diff := make(map[string]any)
+
+	if p.ID != other.ID {
+		diff["id"] = other.ID
+	}
+
+	if p.Title != other.Title {
+		diff["title"] = other.Title
+	}
+
+	if p.UserID != other.UserID {
+		diff["user_id"] = other.UserID
+	}
+
+	if !p.CreatedAt.Equal(other.CreatedAt) {
+		diff["created_at"] = other.CreatedAt
+	}
+
+	if !p.UpdatedAt.Equal(other.UpdatedAt) {
+		diff["updated_at"] = other.UpdatedAt
+	}
+
+	if !equalTimes(p.PublishedAt, other.PublishedAt) {
+		diff["published_at"] = other.PublishedAt
+	}
+
+	return diff
+}
+
+// equalTimes reports whether a and b are both nil or the same instant.
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
 }
 
 // NewPost represents data for creating a new post.
 type NewPost struct {
 	Title  string
 	UserID string
+
+	// PublishedAt is when the post is published, e.g. a future time to schedule it. Repositories
+	// store nil as an unpublished draft, while the use case publishes the post when it is created.
+	PublishedAt *time.Time
 }
 
 // PostRepository defines the interface for post data access.
 type PostRepository interface {
 	Create(ctx context.Context, params *NewPost) (*Post, error)
 	Get(ctx context.Context, id string) (*Post, error)
+	GetWithAuthor(ctx context.Context, id string) (*Post, *User, error)
+	List(ctx context.Context, params *ListParams) ([]*Post, string, error)
+	Search(ctx context.Context, query string, limit int) ([]*Post, error)
 	Delete(ctx context.Context, id string) error
+	DeleteByOwner(ctx context.Context, postID, userID string) error
 }
\ No newline at end of file
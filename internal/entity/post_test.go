@@ -0,0 +1,121 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+)
+
+func TestPost_Diff(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	base := entity.Post{
+		ID:        "post-123",
+		Title:     "Test Post",
+		UserID:    "user-123",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *entity.Post)
+		want   map[string]any
+	}{
+		{
+			name:   "return empty diff for identical posts",
+			modify: func(_ *entity.Post) {},
+			want:   map[string]any{},
+		},
+		{
+			name: "return single changed field",
+			modify: func(p *entity.Post) {
+				p.Title = "Updated Post"
+			},
+			want: map[string]any{"title": "Updated Post"},
+		},
+		{
+			name: "return multiple changed fields",
+			modify: func(p *entity.Post) {
+				p.Title = "Updated Post"
+				p.UserID = "user-456"
+				p.UpdatedAt = later
+			},
+			want: map[string]any{
+				"title":      "Updated Post",
+				"user_id":    "user-456",
+				"updated_at": later,
+			},
+		},
+		{
+			name: "return published time when draft is published",
+			modify: func(p *entity.Post) {
+				p.PublishedAt = &later
+			},
+			want: map[string]any{"published_at": &later},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			before := base
+			after := base
+			tt.modify(&after)
+
+			assert.Equal(t, tt.want, before.Diff(&after))
+		})
+	}
+}
+
+func TestPost_IsPublished(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		publishedAt *time.Time
+		want        bool
+	}{
+		{
+			name:        "return false for draft",
+			publishedAt: nil,
+			want:        false,
+		},
+		{
+			name:        "return true for post published in the past",
+			publishedAt: &earlier,
+			want:        true,
+		},
+		{
+			name:        "return true for post published now",
+			publishedAt: &now,
+			want:        true,
+		},
+		{
+			name:        "return false for scheduled post",
+			publishedAt: &later,
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &entity.Post{PublishedAt: tt.publishedAt}
+
+			assert.Equal(t, tt.want, p.IsPublished(now))
+		})
+	}
+}
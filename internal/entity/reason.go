@@ -0,0 +1,30 @@
+package entity
+
+// Reason is a stable, machine-readable code describing why a request is invalid,
+// so clients can react to it without parsing human-readable messages.
+// It is sent to clients in the "reason" metadata of InvalidArgument errors.
+// Values are part of the API: add new ones, but never rename or reuse them.
+type Reason string
+
+// Reasons for invalid users.
+const (
+	ReasonNameRequired  Reason = "NAME_REQUIRED"
+	ReasonNameTooLong   Reason = "NAME_TOO_LONG"
+	ReasonEmailRequired Reason = "EMAIL_REQUIRED"
+	ReasonEmailInvalid  Reason = "EMAIL_INVALID"
+	ReasonEmailTooLong  Reason = "EMAIL_TOO_LONG"
+)
+
+// Reasons for invalid posts.
+const (
+	ReasonTitleRequired  Reason = "TITLE_REQUIRED"
+	ReasonTitleTooLong   Reason = "TITLE_TOO_LONG"
+	ReasonAuthorRequired Reason = "AUTHOR_REQUIRED"
+)
+
+// Field length limits, matching the column sizes of the database.
+const (
+	MaxUserNameLength  = 255
+	MaxUserEmailLength = 255
+	MaxPostTitleLength = 500
+)
@@ -14,6 +14,34 @@ type User struct {
 	UpdatedAt time.Time
 }
 
+// Diff returns the fields that differ between u and other, keyed by their snake_case names
+// and mapped to the values in other. It returns an empty map if both are identical.
+func (u *User) Diff(other *User) map[string]any {
+	diff := make(map[string]any)
+
+	if u.ID != other.ID {
+		diff["id"] = other.ID
+	}
+
+	if u.Name != other.Name {
+		diff["name"] = other.Name
+	}
+
+	if u.Email != other.Email {
+		diff["email"] = other.Email
+	}
+
+	if !u.CreatedAt.Equal(other.CreatedAt) {
+		diff["created_at"] = other.CreatedAt
+	}
+
+	if !u.UpdatedAt.Equal(other.UpdatedAt) {
+		diff["updated_at"] = other.UpdatedAt
+	}
+
+	return diff
+}
+
 // NewUser represents data for creating a new user.
 type NewUser struct {
 	Name  string
@@ -24,5 +52,11 @@ type NewUser struct {
 type UserRepository interface {
 	Create(ctx context.Context, params *NewUser) (*User, error)
 	Get(ctx context.Context, id string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	// Upsert creates a user unless one with the email exists, and returns the user either way,
+	// reporting whether it was created.
+	Upsert(ctx context.Context, params *NewUser) (user *User, created bool, err error)
+	Exists(ctx context.Context, id string) (bool, error)
+	List(ctx context.Context, params *ListParams) ([]*User, string, error)
 	Delete(ctx context.Context, id string) error
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+)
+
+func TestUser_Diff(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	base := entity.User{
+		ID:        "user-123",
+		Name:      "John Doe",
+		Email:     "john@example.com",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(u *entity.User)
+		want   map[string]any
+	}{
+		{
+			name:   "return empty diff for identical users",
+			modify: func(_ *entity.User) {},
+			want:   map[string]any{},
+		},
+		{
+			name: "return empty diff for the same instant in another location",
+			modify: func(u *entity.User) {
+				u.CreatedAt = now.In(time.FixedZone("JST", 9*60*60))
+			},
+			want: map[string]any{},
+		},
+		{
+			name: "return single changed field",
+			modify: func(u *entity.User) {
+				u.Name = "Jane Doe"
+			},
+			want: map[string]any{"name": "Jane Doe"},
+		},
+		{
+			name: "return multiple changed fields",
+			modify: func(u *entity.User) {
+				u.Name = "Jane Doe"
+				u.Email = "jane@example.com"
+				u.UpdatedAt = later
+			},
+			want: map[string]any{
+				"name":       "Jane Doe",
+				"email":      "jane@example.com",
+				"updated_at": later,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			before := base
+			after := base
+			tt.modify(&after)
+
+			assert.Equal(t, tt.want, before.Diff(&after))
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+)
+
+// breakerState is the state of a CircuitBreaker.
+type breakerState int
+
+const (
+	// stateClosed lets every call through.
+	stateClosed breakerState = iota
+	// stateOpen rejects every call until the cooldown has passed.
+	stateOpen
+	// stateHalfOpen lets a single trial call through to probe whether the database recovered.
+	stateHalfOpen
+)
+
+// CircuitBreaker fails database calls fast after repeated failures instead of letting them pile up timeouts.
+// It opens after threshold consecutive failures, rejects calls while open, and lets a single trial call
+// through once the cooldown has passed: success closes it again, failure reopens it for another cooldown.
+// It is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     clock.Clock
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker that opens after threshold consecutive failures
+// and half-opens after cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		clock:     clock.New(),
+	}
+}
+
+// breakerOutcome is what a call that went through tells about the health of the database.
+type breakerOutcome int
+
+const (
+	// outcomeSuccess means the database is healthy, e.g. the call succeeded or failed with a client error.
+	outcomeSuccess breakerOutcome = iota
+	// outcomeFailure means the database is unhealthy.
+	outcomeFailure
+	// outcomeNeutral tells nothing, e.g. the caller canceled the call or fn panicked.
+	outcomeNeutral
+)
+
+// Execute runs fn unless the circuit is open, in which case it returns an Unavailable error without calling fn.
+// Only server errors and statement timeouts count as failures; client errors such as NotFound
+// mean the database is healthy.
+// Cancellations by the caller and panics of fn count as neither, so that a canceled trial call
+// neither closes the circuit nor leaves it half-open, but lets the next call make a new trial.
+func (b *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	// Recorded in a defer so that a panic of fn, which keeps propagating, still releases the trial slot
+	outcome := outcomeNeutral
+	defer func() {
+		b.record(outcome)
+	}()
+
+	err := fn(ctx)
+	outcome = breakerOutcomeOf(err)
+
+	return err
+}
+
+// allow reports whether a call may go through, moving an open breaker to half-open once the cooldown has passed.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.cooldown {
+			return apperr.New(codes.Unavailable, "database circuit open")
+		}
+
+		// The caller becomes the trial call; others are rejected until it completes
+		b.state = stateHalfOpen
+
+		return nil
+	case stateHalfOpen:
+		return apperr.New(codes.Unavailable, "database circuit open")
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of a call that went through.
+func (b *CircuitBreaker) record(outcome breakerOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch outcome {
+	case outcomeSuccess:
+		b.state = stateClosed
+		b.failures = 0
+
+		return
+	case outcomeNeutral:
+		// A half-open breaker returns to open with the cooldown already passed, so the next call
+		// becomes a new trial; a closed breaker keeps its failure count
+		if b.state == stateHalfOpen {
+			b.state = stateOpen
+		}
+
+		return
+	}
+
+	b.failures++
+
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// breakerOutcomeOf returns what err, returned by a call that went through, tells about the database.
+func breakerOutcomeOf(err error) breakerOutcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return outcomeNeutral
+	}
+
+	var appErr *apperr.AppErr
+	if errors.As(err, &appErr) && !apperr.IsServerError(appErr.Code) {
+		// DeadlineExceeded is a client code, but repositories return it for statements exceeding
+		// statement_timeout, which is how an overloaded database shows, see queryError
+		if appErr.Code == codes.DeadlineExceeded {
+			return outcomeFailure
+		}
+
+		return outcomeSuccess
+	}
+
+	return outcomeFailure
+}
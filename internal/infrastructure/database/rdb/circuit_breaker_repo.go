@@ -0,0 +1,159 @@
+package rdb
+
+import (
+	"context"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+)
+
+// breakerUserRepository guards the calls of a UserRepository with a circuit breaker.
+type breakerUserRepository struct {
+	next    entity.UserRepository
+	breaker *CircuitBreaker
+}
+
+// Create creates a new user unless the circuit is open.
+func (r *breakerUserRepository) Create(ctx context.Context, params *entity.NewUser) (user *entity.User, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		user, err = r.next.Create(ctx, params)
+		return err
+	})
+
+	return user, err
+}
+
+// Get retrieves a user by ID unless the circuit is open.
+func (r *breakerUserRepository) Get(ctx context.Context, id string) (user *entity.User, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		user, err = r.next.Get(ctx, id)
+		return err
+	})
+
+	return user, err
+}
+
+// GetByEmail retrieves a user by email unless the circuit is open.
+func (r *breakerUserRepository) GetByEmail(ctx context.Context, email string) (user *entity.User, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		user, err = r.next.GetByEmail(ctx, email)
+		return err
+	})
+
+	return user, err
+}
+
+// Upsert creates or retrieves a user by email unless the circuit is open.
+func (r *breakerUserRepository) Upsert(ctx context.Context, params *entity.NewUser) (user *entity.User, created bool, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		user, created, err = r.next.Upsert(ctx, params)
+		return err
+	})
+
+	return user, created, err
+}
+
+// Exists reports whether a user exists unless the circuit is open.
+func (r *breakerUserRepository) Exists(ctx context.Context, id string) (exists bool, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		exists, err = r.next.Exists(ctx, id)
+		return err
+	})
+
+	return exists, err
+}
+
+// List retrieves a page of users unless the circuit is open.
+func (r *breakerUserRepository) List(
+	ctx context.Context,
+	params *entity.ListParams,
+) (users []*entity.User, nextPageToken string, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		users, nextPageToken, err = r.next.List(ctx, params)
+		return err
+	})
+
+	return users, nextPageToken, err
+}
+
+// Delete removes a user unless the circuit is open.
+func (r *breakerUserRepository) Delete(ctx context.Context, id string) error {
+	return r.breaker.Execute(ctx, func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+// breakerPostRepository guards the calls of a PostRepository with a circuit breaker.
+type breakerPostRepository struct {
+	next    entity.PostRepository
+	breaker *CircuitBreaker
+}
+
+// Create creates a new post unless the circuit is open.
+func (r *breakerPostRepository) Create(ctx context.Context, params *entity.NewPost) (post *entity.Post, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		post, err = r.next.Create(ctx, params)
+		return err
+	})
+
+	return post, err
+}
+
+// Get retrieves a post by ID unless the circuit is open.
+func (r *breakerPostRepository) Get(ctx context.Context, id string) (post *entity.Post, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		post, err = r.next.Get(ctx, id)
+		return err
+	})
+
+	return post, err
+}
+
+// GetWithAuthor retrieves a post and its author unless the circuit is open.
+func (r *breakerPostRepository) GetWithAuthor(
+	ctx context.Context,
+	id string,
+) (post *entity.Post, author *entity.User, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		post, author, err = r.next.GetWithAuthor(ctx, id)
+		return err
+	})
+
+	return post, author, err
+}
+
+// List retrieves a page of posts unless the circuit is open.
+func (r *breakerPostRepository) List(
+	ctx context.Context,
+	params *entity.ListParams,
+) (posts []*entity.Post, nextPageToken string, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		posts, nextPageToken, err = r.next.List(ctx, params)
+		return err
+	})
+
+	return posts, nextPageToken, err
+}
+
+// Search retrieves posts matching query unless the circuit is open.
+func (r *breakerPostRepository) Search(ctx context.Context, query string, limit int) (posts []*entity.Post, err error) {
+	err = r.breaker.Execute(ctx, func(ctx context.Context) error {
+		posts, err = r.next.Search(ctx, query, limit)
+		return err
+	})
+
+	return posts, err
+}
+
+// Delete removes a post unless the circuit is open.
+func (r *breakerPostRepository) Delete(ctx context.Context, id string) error {
+	return r.breaker.Execute(ctx, func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+// DeleteByOwner removes a post of the given owner unless the circuit is open.
+func (r *breakerPostRepository) DeleteByOwner(ctx context.Context, postID, userID string) error {
+	return r.breaker.Execute(ctx, func(ctx context.Context) error {
+		return r.next.DeleteByOwner(ctx, postID, userID)
+	})
+}
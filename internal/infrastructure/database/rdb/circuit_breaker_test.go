@@ -0,0 +1,221 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+)
+
+var errConnRefused = errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+
+func TestCircuitBreaker_StateTransitions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	breaker := NewCircuitBreaker(3, 30*time.Second)
+	breaker.clock = fakeClock
+
+	calls := 0
+	failing := func(context.Context) error {
+		calls++
+		return errConnRefused
+	}
+	succeeding := func(context.Context) error {
+		calls++
+		return nil
+	}
+
+	// Closed: failures below the threshold go through
+	for range 2 {
+		assert.ErrorIs(t, breaker.Execute(ctx, failing), errConnRefused)
+	}
+	assert.Equal(t, stateClosed, breaker.state)
+
+	// Open: the failure reaching the threshold opens the circuit, and calls fail fast
+	assert.ErrorIs(t, breaker.Execute(ctx, failing), errConnRefused)
+	assert.Equal(t, stateOpen, breaker.state)
+
+	err := breaker.Execute(ctx, succeeding)
+	assert.ErrorIs(t, err, apperr.ErrUnavailable)
+	assert.Equal(t, 3, calls, "calls must not go through while open")
+
+	// Half-open: after the cooldown a failing trial call reopens the circuit
+	fakeClock.Advance(30 * time.Second)
+	assert.ErrorIs(t, breaker.Execute(ctx, failing), errConnRefused)
+	assert.Equal(t, stateOpen, breaker.state)
+	assert.ErrorIs(t, breaker.Execute(ctx, succeeding), apperr.ErrUnavailable)
+
+	// Half-open: a succeeding trial call closes the circuit
+	fakeClock.Advance(30 * time.Second)
+	require.NoError(t, breaker.Execute(ctx, succeeding))
+	assert.Equal(t, stateClosed, breaker.state)
+
+	// Closed: the failure count starts over
+	for range 2 {
+		assert.ErrorIs(t, breaker.Execute(ctx, failing), errConnRefused)
+	}
+	assert.Equal(t, stateClosed, breaker.state)
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleTrialCall(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	breaker := NewCircuitBreaker(1, time.Second)
+	breaker.clock = fakeClock
+
+	_ = breaker.Execute(ctx, func(context.Context) error { return errConnRefused })
+	fakeClock.Advance(time.Second)
+
+	err := breaker.Execute(ctx, func(ctx context.Context) error {
+		// Another call while the trial call is in flight is rejected
+		return breaker.Execute(ctx, func(context.Context) error { return nil })
+	})
+
+	assert.ErrorIs(t, err, apperr.ErrUnavailable)
+}
+
+func TestCircuitBreaker_InconclusiveTrialCall(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		trial func(ctx context.Context) error
+	}{
+		{
+			name: "canceled by caller",
+			trial: func(context.Context) error {
+				return context.Canceled
+			},
+		},
+		{
+			name: "panicking",
+			trial: func(context.Context) error {
+				panic("unexpected nil pointer")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+			breaker := NewCircuitBreaker(1, time.Minute)
+			breaker.clock = fakeClock
+
+			_ = breaker.Execute(ctx, func(context.Context) error { return errConnRefused })
+			fakeClock.Advance(time.Minute)
+
+			func() {
+				defer func() {
+					_ = recover()
+				}()
+
+				_ = breaker.Execute(ctx, tt.trial)
+			}()
+
+			// The trial proved nothing, so the circuit stays open, but the next call makes a new trial
+			// without waiting for another cooldown
+			assert.Equal(t, stateOpen, breaker.state)
+
+			calls := 0
+			require.NoError(t, breaker.Execute(ctx, func(context.Context) error {
+				calls++
+				return nil
+			}))
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, stateClosed, breaker.state)
+		})
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "not found",
+			err:  apperr.New(codes.NotFound, "user not found"),
+		},
+		{
+			name: "invalid argument",
+			err:  apperr.New(codes.InvalidArgument, "user ID cannot be empty"),
+		},
+		{
+			name: "canceled by caller",
+			err:  context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			breaker := NewCircuitBreaker(1, time.Minute)
+
+			for range 3 {
+				assert.ErrorIs(t, breaker.Execute(context.Background(), func(context.Context) error { return tt.err }), tt.err)
+			}
+
+			assert.Equal(t, stateClosed, breaker.state)
+		})
+	}
+}
+
+func TestCircuitBreaker_OpensOnStatementTimeouts(t *testing.T) {
+	t.Parallel()
+
+	// Shaped like queryError, since a pgdriver.Error cannot be created outside its package
+	statementTimeout := apperr.Wrap(
+		errors.New("ERROR: canceling statement due to statement timeout (SQLSTATE=57014)"),
+		codes.DeadlineExceeded, "failed to get user: statement timeout exceeded",
+	)
+
+	breaker := NewCircuitBreaker(3, time.Minute)
+
+	for range 3 {
+		err := breaker.Execute(context.Background(), func(context.Context) error { return statementTimeout })
+		assert.ErrorIs(t, err, statementTimeout)
+	}
+
+	assert.Equal(t, stateOpen, breaker.state)
+}
+
+func TestBreakerUserRepository_FailsFastWhenOpen(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	next := entity.NewMockUserRepository(t)
+	next.EXPECT().Get(mock.Anything, "user-1").Return(nil, errConnRefused).Once()
+
+	repo := &breakerUserRepository{next: next, breaker: NewCircuitBreaker(1, time.Minute)}
+
+	_, err := repo.Get(ctx, "user-1")
+	require.ErrorIs(t, err, errConnRefused)
+
+	// The mock fails the test on a second call to Get
+	user, err := repo.Get(ctx, "user-1")
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, apperr.ErrUnavailable)
+}
@@ -2,10 +2,23 @@ package rdb
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
 	"github.com/uptrace/bun/driver/pgdriver"
 )
 
+// checkDatabase returns codes.Internal if db is nil, e.g. when a repository is wired without a database,
+// so that the call fails cleanly instead of panicking inside Bun.
+func checkDatabase(db *Database) error {
+	if db == nil {
+		return apperr.New(codes.Internal, "database not initialized")
+	}
+	return nil
+}
+
 func isForeignKeyViolation(err error) bool {
 	var pgErr pgdriver.Error
 	if errors.As(err, &pgErr) {
@@ -14,6 +27,23 @@ func isForeignKeyViolation(err error) bool {
 	return false
 }
 
+func isUniqueViolation(err error) bool {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C') == "23505" // unique_violation
+	}
+	return false
+}
+
+// checkViolationConstraint returns the name of the check constraint that err violates, if any.
+func checkViolationConstraint(err error) (string, bool) {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) && pgErr.Field('C') == "23514" { // check_violation
+		return pgErr.Field('n'), true // constraint name
+	}
+	return "", false
+}
+
 func isInvalidUUIDFormat(err error) bool {
 	var pgErr pgdriver.Error
 	if errors.As(err, &pgErr) {
@@ -21,3 +51,23 @@ func isInvalidUUIDFormat(err error) bool {
 	}
 	return false
 }
+
+// isStatementTimeout reports whether err is a statement cancelled by Postgres for running longer than
+// statement_timeout, as opposed to one cancelled because its context was done.
+func isStatementTimeout(err error) bool {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C') == "57014" && // query_canceled
+			strings.Contains(pgErr.Field('M'), "statement timeout")
+	}
+	return false
+}
+
+// queryError wraps err of a failed query with msg, reporting a statement that exceeded
+// statement_timeout as codes.DeadlineExceeded.
+func queryError(err error, msg string) error {
+	if isStatementTimeout(err) {
+		return apperr.Wrap(err, codes.DeadlineExceeded, msg+": statement timeout exceeded")
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,98 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/uptrace/bun"
+)
+
+// list fetches a page of rows of model M in the tenant of ctx ordered by ID and converts them to entities.
+// All repository List methods use it so that they behave identically:
+//   - An empty result is an empty slice with an empty cursor and no error, never codes.NotFound.
+//   - One row more than the limit is fetched to tell whether a next page exists without counting,
+//     and the next cursor is the ID of the last returned row.
+//   - A missing tenant, a malformed cursor, or a negative limit returns codes.InvalidArgument.
+//
+// filter, if not nil, narrows the rows listed, e.g. to hide unpublished posts.
+func list[M any, E any](
+	ctx context.Context,
+	db *Database,
+	params *entity.ListParams,
+	resource string,
+	filter func(*bun.SelectQuery) *bun.SelectQuery,
+	idOf func(*M) string,
+	toEntity func(*M) E,
+) ([]E, string, error) {
+	if params == nil {
+		params = &entity.ListParams{}
+	}
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	limit, err := listLimit(params.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rows []*M
+
+	query := db.NewSelect().Model(&rows).Where("tenant_id = ?", tenantID).OrderExpr("id ASC").Limit(limit + 1)
+	if params.Cursor != "" {
+		query = query.Where("id > ?", params.Cursor)
+	}
+
+	if filter != nil {
+		query = filter(query)
+	}
+
+	// Scanning into a slice does not report sql.ErrNoRows, but guard against it so that
+	// an empty result can never surface as an error
+	if err := query.Scan(ctx); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		if isInvalidUUIDFormat(err) {
+			return nil, "", apperr.Wrap(err, codes.InvalidArgument,
+				fmt.Sprintf("invalid cursor: %s", params.Cursor),
+			)
+		}
+
+		return nil, "", queryError(err, fmt.Sprintf("failed to list %s", resource))
+	}
+
+	var nextCursor string
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		nextCursor = idOf(rows[limit-1])
+	}
+
+	items := make([]E, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toEntity(row))
+	}
+
+	return items, nextCursor, nil
+}
+
+// listLimit returns the page size for the requested limit.
+// Zero means entity.DefaultListLimit and limits above entity.MaxListLimit are capped.
+func listLimit(limit int) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, apperr.New(codes.InvalidArgument, "limit cannot be negative", slog.Int("limit", limit))
+	case limit == 0:
+		return entity.DefaultListLimit, nil
+	case limit > entity.MaxListLimit:
+		return entity.MaxListLimit, nil
+	default:
+		return limit, nil
+	}
+}
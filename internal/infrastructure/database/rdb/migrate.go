@@ -0,0 +1,204 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// schemaMigration records a migration applied by Migrate.
+type schemaMigration struct {
+	bun.BaseModel `bun:"table:schema_migrations"`
+
+	Version   string    `bun:",pk,type:varchar(255)"`
+	AppliedAt time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+// Migrate applies the pending SQL migrations in dir to db at startup, e.g. in controlled environments
+// without a separate Atlas deployment step.
+//
+// Migrations are the *.sql files at the root of dir, named <version>_<description>.sql as Atlas creates them,
+// and are applied in version order. Each one runs in its own transaction together with recording its version
+// in the schema_migrations table, so a failed migration leaves no trace and is retried on the next run.
+// Migrations are applied under a transaction-scoped advisory lock, so instances starting together apply
+// each migration only once. Neither migrations nor the wait for the lock are bound by the statement_timeout
+// of the connection.
+// Migrate is a no-op when every migration is recorded as applied.
+//
+// The schema_migrations table is independent of the Atlas revision table, so a database should be migrated
+// either by Migrate or by atlas migrate apply, not both.
+func Migrate(ctx context.Context, db *Database, dir fs.FS) error {
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := liftStatementTimeout(ctx, tx); err != nil {
+			return err
+		}
+
+		// Concurrent CREATE TABLE IF NOT EXISTS may still conflict, so it is serialized as well
+		if err := lockMigrations(ctx, tx); err != nil {
+			return err
+		}
+
+		_, err := tx.NewCreateTable().Model((*schemaMigration)(nil)).IfNotExists().Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	files, err := fs.Glob(dir, "*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	// Versions are timestamps, so the lexical order of the file names is the order to apply them in
+	sort.Strings(files)
+
+	var applied []string
+	if err := db.NewSelect().Model((*schemaMigration)(nil)).Column("version").Scan(ctx, &applied); err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	isApplied := make(map[string]bool, len(applied))
+	for _, version := range applied {
+		isApplied[version] = true
+	}
+
+	for _, file := range files {
+		version := migrationVersion(file)
+		if isApplied[version] {
+			continue
+		}
+
+		applied, err := applyMigration(ctx, db, dir, file, version)
+		if err != nil {
+			return err
+		}
+
+		if !applied {
+			// Another instance applied it while this one was waiting for the lock
+			continue
+		}
+
+		db.logger.Info(ctx, "Applied database migration", slog.String("version", version), slog.String("file", file))
+	}
+
+	return nil
+}
+
+// migrationLockID is the key of the advisory lock serializing migrations across instances.
+// It is arbitrary, the ASCII of "migrate!", but must not be used for other advisory locks of the database.
+const migrationLockID int64 = 0x6d69677261746521
+
+// lockMigrations takes the migration advisory lock for the rest of tx, waiting while another instance holds it.
+func lockMigrations(ctx context.Context, tx bun.Tx) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	return nil
+}
+
+// liftStatementTimeout disables the statement_timeout of the connection for the rest of tx. It must run first,
+// since waiting for the migration lock while another instance applies a long migration is bound by it too,
+// as are migrations rewriting whole tables.
+func liftStatementTimeout(ctx context.Context, tx bun.Tx) error {
+	if _, err := tx.ExecContext(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to lift statement timeout: %w", err)
+	}
+
+	return nil
+}
+
+// applyMigration runs the migration file and records its version in a single transaction holding
+// the migration lock, so that instances starting together do not apply it twice.
+// It reports false if the version was recorded by another instance in the meantime.
+func applyMigration(ctx context.Context, db *Database, dir fs.FS, file, version string) (bool, error) {
+	script, err := fs.ReadFile(dir, file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read migration %s: %w", file, err)
+	}
+
+	var applied bool
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := liftStatementTimeout(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := lockMigrations(ctx, tx); err != nil {
+			return err
+		}
+
+		// Checked again under the lock, since the list of applied versions was read without it
+		exists, err := tx.NewSelect().Model((*schemaMigration)(nil)).Where("version = ?", version).Exists(ctx)
+		if err != nil || exists {
+			return err
+		}
+
+		// Run on the underlying sql.Tx so that bun does not treat ? in the script as placeholders
+		if _, err := tx.Tx.ExecContext(ctx, string(script)); err != nil {
+			return err
+		}
+
+		if _, err := tx.NewInsert().Model(&schemaMigration{Version: version}).Exec(ctx); err != nil {
+			return err
+		}
+
+		applied = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to apply migration %s: %w", file, err)
+	}
+
+	return applied, nil
+}
+
+// AppliedSchemaVersion returns the version of the latest migration applied by Migrate,
+// or an empty string if none was applied.
+func (d *Database) AppliedSchemaVersion(ctx context.Context) (string, error) {
+	var version sql.NullString
+
+	err := d.NewSelect().Model((*schemaMigration)(nil)).ColumnExpr("max(version)").Scan(ctx, &version)
+	if err != nil {
+		return "", fmt.Errorf("failed to get applied schema version: %w", err)
+	}
+
+	return version.String, nil
+}
+
+// LatestMigrationVersion returns the version of the latest migration in dir,
+// or an empty string if dir has no migrations.
+func LatestMigrationVersion(dir fs.FS) string {
+	// fs.Glob only fails for malformed patterns
+	files, _ := fs.Glob(dir, "*.sql")
+
+	var latest string
+
+	for _, file := range files {
+		if version := migrationVersion(file); version > latest {
+			latest = version
+		}
+	}
+
+	return latest
+}
+
+// migrationVersion returns the version of a migration file named <version>_<description>.sql.
+func migrationVersion(file string) string {
+	name := strings.TrimSuffix(path.Base(file), ".sql")
+
+	version, _, _ := strings.Cut(name, "_")
+
+	return version
+}
@@ -0,0 +1,220 @@
+package rdb_test
+
+import (
+	"context"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb/migrations"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	// Versions far in the future and dedicated tables keep the test independent of the real migrations
+	dir := fstest.MapFS{
+		"99990101000000_create_migrate_test.sql": {
+			Data: []byte(`CREATE TABLE "migrate_test" ("id" integer PRIMARY KEY);`),
+		},
+		"99990102000000_add_migrate_test_name.sql": {
+			Data: []byte(`ALTER TABLE "migrate_test" ADD COLUMN "name" text;
+INSERT INTO "migrate_test" ("id", "name") VALUES (1, 'first?');`),
+		},
+		"README.md": {
+			Data: []byte("Not a migration"),
+		},
+	}
+
+	t.Cleanup(func() {
+		_, _ = testDB.ExecContext(ctx, `DROP TABLE IF EXISTS "migrate_test"`)
+		_, _ = testDB.ExecContext(ctx, `DELETE FROM "schema_migrations" WHERE "version" LIKE '9999%'`)
+	})
+
+	appliedVersions := func(t *testing.T) []string {
+		t.Helper()
+
+		var versions []string
+		err := testDB.NewSelect().
+			Table("schema_migrations").
+			Column("version").
+			Where("version LIKE '9999%'").
+			OrderExpr("version ASC").
+			Scan(ctx, &versions)
+		require.NoError(t, err)
+
+		return versions
+	}
+
+	// Apply both migrations in order
+	require.NoError(t, rdb.Migrate(ctx, testDB, dir))
+
+	assert.Equal(t, []string{"99990101000000", "99990102000000"}, appliedVersions(t))
+
+	var name string
+	err := testDB.NewSelect().Table("migrate_test").Column("name").Where("id = 1").Scan(ctx, &name)
+	require.NoError(t, err)
+	assert.Equal(t, "first?", name)
+
+	// Re-running is a no-op; re-applying would fail since the table already exists
+	require.NoError(t, rdb.Migrate(ctx, testDB, dir))
+
+	assert.Equal(t, []string{"99990101000000", "99990102000000"}, appliedVersions(t))
+
+	appliedVersion, err := testDB.AppliedSchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "99990102000000", appliedVersion)
+	assert.Equal(t, rdb.LatestMigrationVersion(dir), appliedVersion)
+}
+
+func TestMigrate_Concurrent(t *testing.T) {
+	ctx := context.Background()
+
+	// The migration fails if applied twice, as instances starting together would without the lock
+	dir := fstest.MapFS{
+		"99990301000000_create_migrate_concurrent_test.sql": {
+			Data: []byte(`CREATE TABLE "migrate_concurrent_test" ("id" integer PRIMARY KEY);`),
+		},
+	}
+
+	t.Cleanup(func() {
+		_, _ = testDB.ExecContext(ctx, `DROP TABLE IF EXISTS "migrate_concurrent_test"`)
+		_, _ = testDB.ExecContext(ctx, `DELETE FROM "schema_migrations" WHERE "version" = '99990301000000'`)
+	})
+
+	const instances = 4
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, instances)
+
+	for range instances {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs <- rdb.Migrate(ctx, testDB, dir)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	count, err := testDB.NewSelect().Table("schema_migrations").Where("version = '99990301000000'").Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestNormalizeUserEmailsMigration(t *testing.T) {
+	// A tenant of its own keeps the migration from touching users of other tests
+	const tenantID = "tenant-normalize-emails"
+
+	ctx := context.Background()
+
+	fixtures := []*rdb.User{
+		{ID: "550e8400-e29b-41d4-a716-446655440030", Name: "Mixed", Email: "  Mixed@Example.com\t", TenantID: tenantID},
+		{ID: "550e8400-e29b-41d4-a716-446655440031", Name: "Normalized", Email: "dup@example.com", TenantID: tenantID},
+		{ID: "550e8400-e29b-41d4-a716-446655440032", Name: "Colliding", Email: "DUP@example.com", TenantID: tenantID},
+	}
+
+	for _, fixture := range fixtures {
+		_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("tenant_id = ?", tenantID).Exec(ctx)
+	})
+
+	script, err := fs.ReadFile(migrations.Versions(), "20261016170000_normalize_user_emails.sql")
+	require.NoError(t, err)
+
+	_, err = testDB.DB.DB.ExecContext(ctx, string(script))
+	require.NoError(t, err)
+
+	emails := make(map[string]string, len(fixtures))
+
+	var users []rdb.User
+	require.NoError(t, testDB.NewSelect().Model(&users).Where("tenant_id = ?", tenantID).Scan(ctx))
+
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+
+	assert.Equal(t, map[string]string{
+		fixtures[0].ID: "mixed@example.com",
+		fixtures[1].ID: "dup@example.com",
+		// Left for manual resolution since it collides with a normalized email
+		fixtures[2].ID: "DUP@example.com",
+	}, emails)
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  fstest.MapFS
+		want string
+	}{
+		{
+			name: "return latest version regardless of file order",
+			dir: fstest.MapFS{
+				"20250102000000_b.sql": {},
+				"20250103000000_c.sql": {},
+				"20250101000000_a.sql": {},
+				"atlas.sum":            {},
+			},
+			want: "20250103000000",
+		},
+		{
+			name: "return empty version without migrations",
+			dir:  fstest.MapFS{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rdb.LatestMigrationVersion(tt.dir))
+		})
+	}
+}
+
+func TestMigrate_FailedMigration(t *testing.T) {
+	ctx := context.Background()
+
+	dir := fstest.MapFS{
+		"99990201000000_invalid.sql": {
+			Data: []byte(`CREATE TABLE "migrate_failed_test" ("id" integer PRIMARY KEY); SELECT no_such_column FROM "migrate_failed_test";`),
+		},
+	}
+
+	t.Cleanup(func() {
+		_, _ = testDB.ExecContext(ctx, `DROP TABLE IF EXISTS "migrate_failed_test"`)
+	})
+
+	require.Error(t, rdb.Migrate(ctx, testDB, dir))
+
+	// The transaction is rolled back, so neither the table nor the version remain
+	exists, err := testDB.NewSelect().Table("schema_migrations").Where("version = ?", "99990201000000").Exists(ctx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	var tableCount int
+	err = testDB.NewSelect().
+		TableExpr("information_schema.tables").
+		ColumnExpr("count(*)").
+		Where("table_name = ?", "migrate_failed_test").
+		Scan(ctx, &tableCount)
+	require.NoError(t, err)
+	assert.Zero(t, tableCount)
+}
@@ -40,6 +40,36 @@ func main() {
 		ddlStatements = append(ddlStatements, formatted)
 	}
 
+	// Create indexes not expressible in model tags
+	indexes := []*bun.CreateIndexQuery{
+		// Full-text search on post titles, matching the expression of PostRepository.Search
+		db.NewCreateIndex().
+			Model((*rdb.Post)(nil)).
+			Index("posts_title_search_idx").
+			IfNotExists().
+			Using("GIN").
+			ColumnExpr("to_tsvector('english', title)"),
+	}
+
+	for _, index := range indexes {
+		ddl, err := index.AppendQuery(db.Formatter(), nil)
+		if err != nil {
+			log.Fatalf("Failed to generate index DDL: %v", err)
+		}
+
+		ddlStatements = append(ddlStatements, string(ddl))
+	}
+
+	// Create check constraints not expressible in model tags
+	checks := []string{
+		// Posts must have a title, backing the validation of the use case.
+		// Dropped first because Postgres has no ADD CONSTRAINT IF NOT EXISTS
+		// and schema.sql must be re-runnable like the CREATE statements above.
+		`ALTER TABLE "posts" DROP CONSTRAINT IF EXISTS "posts_title_not_empty", ADD CONSTRAINT "posts_title_not_empty" CHECK (title <> '')`,
+	}
+
+	ddlStatements = append(ddlStatements, checks...)
+
 	// Generate schema.sql content
 	schemaContent := `-- Auto-generated schema from Bun models
 -- Generated by generate_schema.go
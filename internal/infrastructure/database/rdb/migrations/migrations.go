@@ -0,0 +1,22 @@
+// Package migrations embeds the versioned SQL migrations managed with Atlas,
+// so that they can be applied at startup with rdb.Migrate.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed versions/*.sql
+var versions embed.FS
+
+// Versions returns the migration files, named <version>_<description>.sql.
+func Versions() fs.FS {
+	sub, err := fs.Sub(versions, "versions")
+	if err != nil {
+		// Unreachable since the embedded directory is a valid path
+		panic(err)
+	}
+
+	return sub
+}
@@ -9,18 +9,26 @@ CREATE TABLE IF NOT EXISTS "users" (
 ),
   "name" varchar(255) NOT NULL,
   "email" varchar(255) NOT NULL,
+  "tenant_id" varchar(255) NOT NULL,
   "created_at" TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
   "updated_at" TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
   PRIMARY KEY ("id"),
-  UNIQUE ("email"));
+  CONSTRAINT "users_email_tenant_id_key" UNIQUE ("email",
+  "tenant_id"));
 
 CREATE TABLE IF NOT EXISTS "posts" (
   "id" uuid NOT NULL DEFAULT uuid_generate_v4(
 ),
   "title" varchar(500) NOT NULL,
   "user_id" uuid NOT NULL,
+  "tenant_id" varchar(255) NOT NULL,
   "created_at" TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
   "updated_at" TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+  "published_at" TIMESTAMPTZ,
   PRIMARY KEY ("id"),
   FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
 
+CREATE INDEX IF NOT EXISTS "posts_title_search_idx" ON "posts" USING GIN (to_tsvector('english', title));
+
+ALTER TABLE "posts" DROP CONSTRAINT IF EXISTS "posts_title_not_empty", ADD CONSTRAINT "posts_title_not_empty" CHECK (title <> '');
+
@@ -0,0 +1,7 @@
+-- Existing rows get an empty tenant and stay invisible to every tenant until they are backfilled
+-- Modify "posts" table
+ALTER TABLE "posts" ADD COLUMN "tenant_id" character varying(255) NOT NULL DEFAULT '';
+ALTER TABLE "posts" ALTER COLUMN "tenant_id" DROP DEFAULT;
+-- Modify "users" table
+ALTER TABLE "users" ADD COLUMN "tenant_id" character varying(255) NOT NULL DEFAULT '';
+ALTER TABLE "users" ALTER COLUMN "tenant_id" DROP DEFAULT;
@@ -0,0 +1,2 @@
+-- Create index "posts_title_search_idx" to table: "posts"
+CREATE INDEX "posts_title_search_idx" ON "posts" USING GIN (to_tsvector('english'::regconfig, (title)::text));
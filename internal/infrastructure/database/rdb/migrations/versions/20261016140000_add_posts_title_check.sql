@@ -0,0 +1,4 @@
+-- Backfill empty titles so the constraint can be validated
+UPDATE "posts" SET "title" = '(untitled)' WHERE "title" = '';
+-- Modify "posts" table without scanning it, since the ACCESS EXCLUSIVE lock is held until the transaction commits
+ALTER TABLE "posts" ADD CONSTRAINT "posts_title_not_empty" CHECK (title <> '') NOT VALID;
@@ -0,0 +1,3 @@
+-- Validate existing rows in a transaction of its own, holding only a SHARE UPDATE EXCLUSIVE lock
+-- that lets reads and writes of "posts" proceed during the scan
+ALTER TABLE "posts" VALIDATE CONSTRAINT "posts_title_not_empty";
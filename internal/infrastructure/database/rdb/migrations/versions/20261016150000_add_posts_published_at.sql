@@ -0,0 +1,4 @@
+-- Existing posts were visible before publishing was tracked, so they are published when they were created
+-- Modify "posts" table
+ALTER TABLE "posts" ADD COLUMN "published_at" timestamptz NULL;
+UPDATE "posts" SET "published_at" = "created_at";
@@ -0,0 +1,2 @@
+-- Modify "users" table
+ALTER TABLE "users" DROP CONSTRAINT "users_email_key", ADD CONSTRAINT "users_email_tenant_id_key" UNIQUE ("email", "tenant_id");
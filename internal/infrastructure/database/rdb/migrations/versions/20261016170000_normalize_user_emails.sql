@@ -0,0 +1,17 @@
+-- Normalize emails to lowercase without surrounding whitespace, the form the application stores them in.
+-- Of the users of a tenant whose emails collide once normalized, only one is normalized, preferring one
+-- already normalized, then the earliest created. The others keep their email so that no user is lost,
+-- and must be merged or given another email by hand. List them after migrating with:
+--   SELECT u.* FROM "users" u WHERE u."email" <> lower(btrim(u."email", E' \t\n\r\x0B\f'));
+-- Modify "users" table
+UPDATE "users" AS u SET "email" = lower(btrim(u."email", E' \t\n\r\x0B\f'))
+FROM (
+  SELECT "id", row_number() OVER (
+    PARTITION BY "tenant_id", lower(btrim("email", E' \t\n\r\x0B\f'))
+    ORDER BY ("email" = lower(btrim("email", E' \t\n\r\x0B\f'))) DESC, "created_at", "id"
+  ) AS "rank"
+  FROM "users"
+) AS ranked
+WHERE ranked."id" = u."id"
+  AND ranked."rank" = 1
+  AND u."email" <> lower(btrim(u."email", E' \t\n\r\x0B\f'));
@@ -1,6 +1,7 @@
 package rdb
 
 import (
+	"strings"
 	"time"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
@@ -13,7 +14,8 @@ type User struct {
 
 	ID        string    `bun:",pk,type:uuid,default:uuid_generate_v4()"`
 	Name      string    `bun:",notnull,type:varchar(255)"`
-	Email     string    `bun:",notnull,unique,type:varchar(255)"`
+	Email     string    `bun:",notnull,unique:users_email_tenant_id_key,type:varchar(255)"`
+	TenantID  string    `bun:",notnull,unique:users_email_tenant_id_key,type:varchar(255)"`
 	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp"`
 	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp"`
 }
@@ -39,13 +41,20 @@ func (u *User) FromEntity(user *entity.User) {
 }
 
 // FromNewUser converts NewUser domain object to database model for creation.
+// The email is normalized, so that addresses differing only in case or surrounding spaces collide
+// on the unique constraint.
 func FromNewUser(newUser *entity.NewUser) *User {
 	u := &User{}
 	u.Name = newUser.Name
-	u.Email = newUser.Email
+	u.Email = NormalizeEmail(newUser.Email)
 	return u
 }
 
+// NormalizeEmail returns the form emails are stored and looked up in: lowercase without surrounding spaces.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // Post represents the database model for the posts table.
 type Post struct {
 	bun.BaseModel `bun:"table:posts,alias:p"`
@@ -53,9 +62,13 @@ type Post struct {
 	ID        string    `bun:",pk,type:uuid,default:uuid_generate_v4()"`
 	Title     string    `bun:",notnull,type:varchar(500)"`
 	UserID    string    `bun:",notnull,type:uuid"`
+	TenantID  string    `bun:",notnull,type:varchar(255)"`
 	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp"`
 	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp"`
 
+	// PublishedAt is NULL for unpublished drafts
+	PublishedAt bun.NullTime
+
 	// Relations
 	User *User `bun:"rel:belongs-to,join:user_id=id,on_delete:CASCADE"`
 }
@@ -63,11 +76,12 @@ type Post struct {
 // ToEntity converts database model to domain entity.
 func (p *Post) ToEntity() *entity.Post {
 	return &entity.Post{
-		ID:        p.ID,
-		Title:     p.Title,
-		UserID:    p.UserID,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+		ID:          p.ID,
+		Title:       p.Title,
+		UserID:      p.UserID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		PublishedAt: fromNullTime(p.PublishedAt),
 	}
 }
 
@@ -78,6 +92,7 @@ func (p *Post) FromEntity(post *entity.Post) {
 	p.UserID = post.UserID
 	p.CreatedAt = post.CreatedAt
 	p.UpdatedAt = post.UpdatedAt
+	p.PublishedAt = toNullTime(post.PublishedAt)
 }
 
 // FromNewPost converts NewPost domain object to database model for creation.
@@ -85,5 +100,24 @@ func FromNewPost(newPost *entity.NewPost) *Post {
 	p := &Post{}
 	p.Title = newPost.Title
 	p.UserID = newPost.UserID
+	p.PublishedAt = toNullTime(newPost.PublishedAt)
 	return p
 }
+
+// toNullTime converts an optional time to a nullable column value, NULL for nil.
+func toNullTime(t *time.Time) bun.NullTime {
+	if t == nil {
+		return bun.NullTime{}
+	}
+
+	return bun.NullTime{Time: *t}
+}
+
+// fromNullTime converts a nullable column value to an optional time, nil for NULL.
+func fromNullTime(t bun.NullTime) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t.Time
+}
@@ -0,0 +1,77 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+// poolWaitMonitor warns when queries wait too long to acquire a connection from a saturated pool,
+// which otherwise only shows as slow requests. sql.DBStats only has cumulative wait counters,
+// so it compares them with the previous check to get the average wait of the connections acquired since.
+type poolWaitMonitor struct {
+	threshold time.Duration
+	stop      chan struct{}
+	stopOnce  sync.Once
+
+	mu   sync.Mutex
+	last sql.DBStats
+}
+
+// newPoolWaitMonitor creates a monitor warning when the average wait exceeds threshold.
+func newPoolWaitMonitor(threshold time.Duration) *poolWaitMonitor {
+	return &poolWaitMonitor{
+		threshold: threshold,
+		stop:      make(chan struct{}),
+	}
+}
+
+// run checks the pool stats every interval until close is called.
+func (m *poolWaitMonitor) run(logger *logging.Logger, stats func() sql.DBStats, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.check(context.Background(), logger, stats())
+		}
+	}
+}
+
+// check logs a warning if the connections acquired after waiting since the previous check
+// waited longer than the threshold on average.
+func (m *poolWaitMonitor) check(ctx context.Context, logger *logging.Logger, stats sql.DBStats) {
+	m.mu.Lock()
+	waitCount := stats.WaitCount - m.last.WaitCount
+	waitDuration := stats.WaitDuration - m.last.WaitDuration
+	m.last = stats
+	m.mu.Unlock()
+
+	if waitCount <= 0 {
+		return
+	}
+
+	avgWait := waitDuration / time.Duration(waitCount)
+	if avgWait <= m.threshold {
+		return
+	}
+
+	logger.Warn(ctx, "Database connection pool saturated, queries are waiting for connections",
+		slog.Int64("wait_count", waitCount),
+		slog.Int64("avg_wait_ms", avgWait.Milliseconds()),
+		slog.Int("max_open_conns", stats.MaxOpenConnections),
+		slog.Int("in_use", stats.InUse),
+	)
+}
+
+// close stops run. It is safe to call more than once.
+func (m *poolWaitMonitor) close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+}
@@ -5,38 +5,91 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"github.com/uptrace/bun"
 )
 
+// errAuthorNotFound is returned from the transaction of PostRepository.Create
+// when the author does not exist in the tenant.
+var errAuthorNotFound = errors.New("post author not found")
+
 // PostRepository implements entity.PostRepository interface.
 type PostRepository struct {
 	db *Database
 }
 
 // NewPostRepository creates a new post repository instance.
+// Its calls are guarded by the circuit breaker of db when it is enabled.
+// If db is nil, e.g. when misconfigured, every call returns codes.Internal instead of panicking.
 func NewPostRepository(db *Database) entity.PostRepository {
-	return &PostRepository{db: db}
+	repo := &PostRepository{db: db}
+
+	if db != nil && db.breaker != nil {
+		return &breakerPostRepository{next: repo, breaker: db.breaker}
+	}
+
+	return repo
 }
 
 // Create creates a new post in the database.
 func (r *PostRepository) Create(ctx context.Context, params *entity.NewPost) (*entity.Post, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, err
+	}
+
 	if params == nil {
 		return nil, apperr.New(codes.InvalidArgument, "params cannot be nil")
 	}
 
+	defer r.db.logDuration(ctx, "PostRepository.Create", time.Now(), attr.UserID(params.UserID))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	row := FromNewPost(params)
+	row.TenantID = tenantID
+
+	err = r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// The foreign key does not know about tenants, so make sure the author belongs to the same tenant.
+		// FOR KEY SHARE keeps the author from being deleted until the post is inserted.
+		userExists, err := tx.NewSelect().Model((*User)(nil)).
+			Where("id = ?", params.UserID).
+			Where("tenant_id = ?", tenantID).
+			For("KEY SHARE").
+			Exists(ctx)
+		if err != nil {
+			return err
+		}
+		if !userExists {
+			return errAuthorNotFound
+		}
+
+		// Scan the row back so the ID and timestamps generated by the database are always populated
+		_, err = tx.NewInsert().Model(row).Returning("*").Exec(ctx)
 
-	_, err := r.db.NewInsert().Model(row).Exec(ctx)
+		return err
+	})
 	if err != nil {
-		if isForeignKeyViolation(err) {
+		if errors.Is(err, errAuthorNotFound) || isForeignKeyViolation(err) {
 			return nil, apperr.New(codes.FailedPrecondition,
 				fmt.Sprintf("user with ID %s does not exist", params.UserID),
 			)
 		}
-		return nil, fmt.Errorf("failed to create post: %w", err)
+		if constraint, ok := checkViolationConstraint(err); ok {
+			return nil, apperr.New(codes.InvalidArgument,
+				fmt.Sprintf("post violates check constraint %s", constraint),
+				attr.Constraint(constraint),
+			)
+		}
+		return nil, queryError(err, "failed to create post")
 	}
 
 	// Convert back to domain entity
@@ -45,12 +98,23 @@ func (r *PostRepository) Create(ctx context.Context, params *entity.NewPost) (*e
 
 // Get retrieves a post by ID from the database.
 func (r *PostRepository) Get(ctx context.Context, id string) (*entity.Post, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, err
+	}
+
 	if id == "" {
 		return nil, apperr.New(codes.InvalidArgument, "post ID cannot be empty")
 	}
 
+	defer r.db.logDuration(ctx, "PostRepository.Get", time.Now(), attr.PostID(id))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	row := &Post{}
-	err := r.db.NewSelect().Model(row).Where("id = ?", id).Scan(ctx)
+	err = r.db.NewSelect().Model(row).Where("id = ?", id).Where("tenant_id = ?", tenantID).Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, apperr.Wrap(err, codes.NotFound,
@@ -62,21 +126,160 @@ func (r *PostRepository) Get(ctx context.Context, id string) (*entity.Post, erro
 				fmt.Sprintf("invalid UUID format: %s", id),
 			)
 		}
-		return nil, fmt.Errorf("failed to get post: %w", err)
+		return nil, queryError(err, "failed to get post")
 	}
 
 	return row.ToEntity(), nil
 }
 
+// GetWithAuthor retrieves a post by ID together with its author in a single query.
+func (r *PostRepository) GetWithAuthor(ctx context.Context, id string) (*entity.Post, *entity.User, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, nil, err
+	}
+
+	if id == "" {
+		return nil, nil, apperr.New(codes.InvalidArgument, "post ID cannot be empty")
+	}
+
+	defer r.db.logDuration(ctx, "PostRepository.GetWithAuthor", time.Now(), attr.PostID(id))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Columns are qualified with the table alias since the joined users table has the same column names
+	row := &Post{}
+	err = r.db.NewSelect().Model(row).
+		Relation("User").
+		Where("p.id = ?", id).
+		Where("p.tenant_id = ?", tenantID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, apperr.Wrap(err, codes.NotFound,
+				fmt.Sprintf("post with ID %s not found", id),
+			)
+		}
+		if isInvalidUUIDFormat(err) {
+			return nil, nil, apperr.Wrap(err, codes.InvalidArgument,
+				fmt.Sprintf("invalid UUID format: %s", id),
+			)
+		}
+		return nil, nil, queryError(err, "failed to get post with author")
+	}
+
+	// The foreign key keeps posts from outliving their author, so a missing author means broken data
+	if row.User == nil {
+		return nil, nil, apperr.New(codes.Internal, "post author not found",
+			attr.PostID(id), attr.UserID(row.UserID),
+		)
+	}
+
+	return row.ToEntity(), row.User.ToEntity(), nil
+}
+
+// List retrieves a page of posts ordered by ID from the database.
+// Drafts and scheduled posts are left out unless params.IncludeUnpublished is set.
+// It returns an empty slice, not an error, when no posts match.
+func (r *PostRepository) List(ctx context.Context, params *entity.ListParams) ([]*entity.Post, string, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, "", err
+	}
+
+	defer r.db.logDuration(ctx, "PostRepository.List", time.Now())
+
+	var filter func(*bun.SelectQuery) *bun.SelectQuery
+	if params == nil || !params.IncludeUnpublished {
+		filter = publishedPosts(r.db.clock.Now())
+	}
+
+	return list(ctx, r.db, params, "posts", filter,
+		func(row *Post) string { return row.ID },
+		(*Post).ToEntity,
+	)
+}
+
+// publishedPosts returns a filter narrowing a query to posts published by now, leaving out drafts,
+// whose published_at is NULL, and scheduled posts. now is taken from the application clock like the
+// publish times set by the use case, since the clock of the database server may differ from it.
+func publishedPosts(now time.Time) func(*bun.SelectQuery) *bun.SelectQuery {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("published_at <= ?", now)
+	}
+}
+
+// titleSearchConfig is the text search configuration of the posts title index.
+// Queries must use the same configuration for Postgres to use the index.
+const titleSearchConfig = "english"
+
+// Search retrieves published posts whose title matches the keywords of query, most relevant first.
+// Drafts and scheduled posts are left out like in List.
+// A zero limit means entity.DefaultListLimit and limits above entity.MaxListLimit are capped.
+// It returns an empty slice, not an error, when no posts match.
+func (r *PostRepository) Search(ctx context.Context, query string, limit int) ([]*entity.Post, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, apperr.New(codes.InvalidArgument, "search query cannot be empty")
+	}
+
+	defer r.db.logDuration(ctx, "PostRepository.Search", time.Now())
+
+	limit, err := listLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*Post
+	err = r.db.NewSelect().Model(&rows).
+		Where("tenant_id = ?", tenantID).
+		Apply(publishedPosts(r.db.clock.Now())).
+		Where("to_tsvector(?, title) @@ plainto_tsquery(?, ?)", titleSearchConfig, titleSearchConfig, query).
+		OrderExpr("ts_rank(to_tsvector(?, title), plainto_tsquery(?, ?)) DESC", titleSearchConfig, titleSearchConfig, query).
+		OrderExpr("id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, queryError(err, "failed to search posts")
+	}
+
+	posts := make([]*entity.Post, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, row.ToEntity())
+	}
+
+	return posts, nil
+}
+
 // Delete removes a post from the database.
 func (r *PostRepository) Delete(ctx context.Context, id string) error {
+	if err := checkDatabase(r.db); err != nil {
+		return err
+	}
+
 	if id == "" {
 		return apperr.New(codes.InvalidArgument, "post ID cannot be empty")
 	}
 
-	result, err := r.db.NewDelete().Model((*Post)(nil)).Where("id = ?", id).Exec(ctx)
+	defer r.db.logDuration(ctx, "PostRepository.Delete", time.Now(), attr.PostID(id))
+
+	tenantID, err := tenantFromContext(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+		return err
+	}
+
+	result, err := r.db.NewDelete().Model((*Post)(nil)).Where("id = ?", id).Where("tenant_id = ?", tenantID).Exec(ctx)
+	if err != nil {
+		return queryError(err, "failed to delete post")
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -90,3 +293,52 @@ func (r *PostRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// DeleteByOwner removes a post from the database only if it was created by the given user.
+// It returns codes.NotFound both when the post does not exist and when it belongs to another user,
+// so that callers cannot probe for posts of other users.
+func (r *PostRepository) DeleteByOwner(ctx context.Context, postID, userID string) error {
+	if err := checkDatabase(r.db); err != nil {
+		return err
+	}
+
+	if postID == "" {
+		return apperr.New(codes.InvalidArgument, "post ID cannot be empty")
+	}
+
+	if userID == "" {
+		return apperr.New(codes.InvalidArgument, "user ID cannot be empty")
+	}
+
+	defer r.db.logDuration(ctx, "PostRepository.DeleteByOwner", time.Now(), attr.PostID(postID), attr.UserID(userID))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.NewDelete().Model((*Post)(nil)).
+		Where("id = ?", postID).
+		Where("user_id = ?", userID).
+		Where("tenant_id = ?", tenantID).
+		Exec(ctx)
+	if err != nil {
+		if isInvalidUUIDFormat(err) {
+			return apperr.Wrap(err, codes.InvalidArgument,
+				fmt.Sprintf("invalid UUID format: %s or %s", postID, userID),
+			)
+		}
+		return queryError(err, "failed to delete post")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apperr.New(codes.NotFound, fmt.Sprintf("post with ID %s not found", postID))
+	}
+
+	return nil
+}
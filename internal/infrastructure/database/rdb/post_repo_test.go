@@ -4,14 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/apperrtest"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
 )
 
 func TestPostRepository_Create(t *testing.T) {
@@ -19,11 +25,12 @@ func TestPostRepository_Create(t *testing.T) {
 		params *entity.NewPost
 	}
 	// Create a test user first
-	ctx := context.Background()
+	ctx := tenant.NewContext(context.Background(), testTenantID)
 	testUser := &rdb.User{
-		ID:    "550e8400-e29b-41d4-a716-446655440000",
-		Name:  "Test User",
-		Email: "test@example.com",
+		ID:       "550e8400-e29b-41d4-a716-446655440000",
+		Name:     "Test User",
+		Email:    "test@example.com",
+		TenantID: testTenantID,
 	}
 	_, err := testDB.NewInsert().Model(testUser).Exec(ctx)
 	require.NoError(t, err)
@@ -32,6 +39,9 @@ func TestPostRepository_Create(t *testing.T) {
 		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", testUser.ID).Exec(ctx)
 	})
 
+	// Postgres stores microseconds, so the publish time round-trips only at that precision
+	publishedAt := time.Now().Truncate(time.Microsecond)
+
 	tests := []struct {
 		name    string
 		args    args
@@ -52,6 +62,22 @@ func TestPostRepository_Create(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "create published post with its publish time",
+			args: args{
+				params: &entity.NewPost{
+					Title:       "Published Post",
+					UserID:      testUser.ID,
+					PublishedAt: &publishedAt,
+				},
+			},
+			want: &entity.Post{
+				Title:       "Published Post",
+				UserID:      testUser.ID,
+				PublishedAt: &publishedAt,
+			},
+			wantErr: nil,
+		},
 		{
 			name: "return error when params is nil",
 			args: args{
@@ -97,10 +123,49 @@ func TestPostRepository_Create(t *testing.T) {
 			assert.Equal(t, tt.want.UserID, got.UserID)
 			assert.NotZero(t, got.CreatedAt)
 			assert.NotZero(t, got.UpdatedAt)
+
+			// A nil publish time is stored as NULL and read back as nil, leaving the post a draft
+			if tt.want.PublishedAt == nil {
+				assert.Nil(t, got.PublishedAt)
+			} else {
+				require.NotNil(t, got.PublishedAt)
+				assert.True(t, tt.want.PublishedAt.Equal(*got.PublishedAt),
+					"PublishedAt = %v, want %v", got.PublishedAt, tt.want.PublishedAt)
+			}
+
+			// The returned post reflects the stored row, including generated fields
+			stored, err := rdb.NewPostRepository(testDB).Get(ctx, got.ID)
+			require.NoError(t, err)
+			assert.Equal(t, stored, got)
 		})
 	}
 }
 
+func TestPostRepository_Create_CheckViolation(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+	testUser := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440020",
+		Name:     "Check User",
+		Email:    "check@example.com",
+		TenantID: testTenantID,
+	}
+	_, err := testDB.NewInsert().Model(testUser).Exec(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", testUser.ID).Exec(ctx)
+	})
+
+	// The repository does not validate the title, so the insert reaches the posts_title_not_empty constraint
+	got, err := rdb.NewPostRepository(testDB).Create(ctx, &entity.NewPost{
+		Title:  "",
+		UserID: testUser.ID,
+	})
+	require.ErrorIs(t, err, apperr.ErrInvalidArgument)
+	assert.Nil(t, got)
+	apperrtest.AssertHasAttr(t, err, attr.ConstraintKey, "posts_title_not_empty")
+}
+
 func TestPostRepository_Get(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -121,14 +186,16 @@ func TestPostRepository_Get(t *testing.T) {
 			},
 			fixtures: []any{
 				&rdb.User{
-					ID:    "550e8400-e29b-41d4-a716-446655440000",
-					Name:  "Test User Get",
-					Email: "testget@example.com",
+					ID:       "550e8400-e29b-41d4-a716-446655440000",
+					Name:     "Test User Get",
+					Email:    "testget@example.com",
+					TenantID: testTenantID,
 				},
 				&rdb.Post{
-					ID:     "239e4567-e89b-12d3-a456-426614174000",
-					Title:  "Test Post Get",
-					UserID: "550e8400-e29b-41d4-a716-446655440000",
+					ID:       "239e4567-e89b-12d3-a456-426614174000",
+					Title:    "Test Post Get",
+					UserID:   "550e8400-e29b-41d4-a716-446655440000",
+					TenantID: testTenantID,
 				},
 			},
 			want: &entity.Post{
@@ -168,7 +235,7 @@ func TestPostRepository_Get(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			ctx := context.Background()
+			ctx := tenant.NewContext(context.Background(), testTenantID)
 
 			for _, fixture := range tt.fixtures {
 				_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
@@ -211,11 +278,141 @@ func TestPostRepository_Get(t *testing.T) {
 	}
 }
 
+// queryCounter is a bun.QueryHook that counts executed queries.
+type queryCounter struct {
+	count atomic.Int32
+}
+
+func (c *queryCounter) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (c *queryCounter) AfterQuery(_ context.Context, _ *bun.QueryEvent) {
+	c.count.Add(1)
+}
+
+func TestPostRepository_GetWithAuthor(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	testUser := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440003",
+		Name:     "Test User Author",
+		Email:    "testauthor@example.com",
+		TenantID: testTenantID,
+	}
+	testPost := &rdb.Post{
+		ID:       "239e4567-e89b-12d3-a456-426614174003",
+		Title:    "Test Post Author",
+		UserID:   testUser.ID,
+		TenantID: testTenantID,
+	}
+
+	_, err := testDB.NewInsert().Model(testUser).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = testDB.NewInsert().Model(testPost).Exec(ctx)
+	require.NoError(t, err)
+
+	// An orphaned post can only be stored with foreign key checks off, which the replica role does for the transaction
+	orphanPost := &rdb.Post{
+		ID:       "239e4567-e89b-12d3-a456-426614174004",
+		Title:    "Test Post Orphan",
+		UserID:   "550e8400-e29b-41d4-a716-446655440099",
+		TenantID: testTenantID,
+	}
+	err = testDB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+			return err
+		}
+		_, err := tx.NewInsert().Model(orphanPost).Exec(ctx)
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		// Posts are deleted by cascade
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", testUser.ID).Exec(ctx)
+		_, _ = testDB.NewDelete().Model((*rdb.Post)(nil)).Where("id = ?", orphanPost.ID).Exec(ctx)
+	})
+
+	tests := []struct {
+		name     string
+		id       string
+		wantPost *entity.Post
+		wantUser *entity.User
+		wantErr  error
+	}{
+		{
+			name:     "return post and author",
+			id:       testPost.ID,
+			wantPost: &entity.Post{ID: testPost.ID, Title: testPost.Title, UserID: testUser.ID},
+			wantUser: &entity.User{ID: testUser.ID, Name: testUser.Name, Email: testUser.Email},
+			wantErr:  nil,
+		},
+		{
+			name:    "return error when post ID is empty",
+			id:      "",
+			wantErr: apperr.ErrInvalidArgument,
+		},
+		{
+			name:    "return error when malformed UUID",
+			id:      "not-a-uuid",
+			wantErr: apperr.ErrInvalidArgument,
+		},
+		{
+			name:    "return error when post does not exist",
+			id:      "123e4567-e89b-12d3-a456-426614174000",
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name:    "return error when author is missing",
+			id:      orphanPost.ID,
+			wantErr: apperr.ErrInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Count queries on a copy of the test database so other tests are not affected
+			counter := &queryCounter{}
+			db := &rdb.Database{DB: testDB.WithNamedArg("test", tt.name)}
+			db.AddQueryHook(counter)
+
+			post, user, err := rdb.NewPostRepository(db).GetWithAuthor(ctx, tt.id)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, post)
+				assert.Nil(t, user)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), counter.count.Load(), "expected post and author to be fetched in one query")
+
+			assert.Equal(t, tt.wantPost.ID, post.ID)
+			assert.Equal(t, tt.wantPost.Title, post.Title)
+			assert.Equal(t, tt.wantPost.UserID, post.UserID)
+			assert.False(t, post.CreatedAt.IsZero())
+
+			assert.Equal(t, tt.wantUser.ID, user.ID)
+			assert.Equal(t, tt.wantUser.Name, user.Name)
+			assert.Equal(t, tt.wantUser.Email, user.Email)
+			assert.False(t, user.CreatedAt.IsZero())
+		})
+	}
+}
+
 func TestPostRepository_Get_ContextCancellation(t *testing.T) {
 	t.Parallel()
 
 	// Create a cancelled context
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(tenant.NewContext(context.Background(), testTenantID))
 	cancel()
 
 	postRepo := rdb.NewPostRepository(testDB)
@@ -226,3 +423,372 @@ func TestPostRepository_Get_ContextCancellation(t *testing.T) {
 	assert.Nil(t, got)
 	assert.True(t, errors.Is(err, context.Canceled) || errors.Is(err, sql.ErrNoRows))
 }
+
+func TestPostRepository_List(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	testUser := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440001",
+		Name:     "Test User List",
+		Email:    "testlist@example.com",
+		TenantID: testTenantID,
+	}
+
+	published := bun.NullTime{Time: time.Now().Add(-time.Hour)}
+	scheduled := bun.NullTime{Time: time.Now().Add(time.Hour)}
+
+	// Fixtures use the top of the UUID range and cursors start right below it,
+	// so rows inserted by other tests never appear in the pages
+	fixtures := []*rdb.Post{
+		{ID: "fffffff1-0000-0000-0000-000000000000", Title: "List Post 1", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: published},
+		{ID: "fffffff2-0000-0000-0000-000000000000", Title: "List Post 2", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: published},
+		{ID: "fffffff3-0000-0000-0000-000000000000", Title: "Draft Post", UserID: testUser.ID, TenantID: testTenantID},
+		{ID: "fffffff4-0000-0000-0000-000000000000", Title: "Scheduled Post", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: scheduled},
+	}
+
+	_, err := testDB.NewInsert().Model(testUser).Exec(ctx)
+	require.NoError(t, err)
+
+	for _, fixture := range fixtures {
+		_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		// Posts are deleted by cascade
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", testUser.ID).Exec(ctx)
+	})
+
+	tests := []struct {
+		name           string
+		params         *entity.ListParams
+		wantIDs        []string
+		wantNextCursor string
+		wantErr        error
+	}{
+		{
+			name:           "return first page with next cursor",
+			params:         &entity.ListParams{Limit: 1, Cursor: "fffffff0-0000-0000-0000-000000000000"},
+			wantIDs:        []string{fixtures[0].ID},
+			wantNextCursor: fixtures[0].ID,
+		},
+		{
+			name:           "return last page without next cursor",
+			params:         &entity.ListParams{Limit: 1, Cursor: fixtures[0].ID},
+			wantIDs:        []string{fixtures[1].ID},
+			wantNextCursor: "",
+		},
+		{
+			name:           "leave out drafts and scheduled posts by default",
+			params:         &entity.ListParams{Cursor: "fffffff0-0000-0000-0000-000000000000"},
+			wantIDs:        []string{fixtures[0].ID, fixtures[1].ID},
+			wantNextCursor: "",
+		},
+		{
+			name:           "include drafts and scheduled posts when requested",
+			params:         &entity.ListParams{Cursor: "fffffff0-0000-0000-0000-000000000000", IncludeUnpublished: true},
+			wantIDs:        []string{fixtures[0].ID, fixtures[1].ID, fixtures[2].ID, fixtures[3].ID},
+			wantNextCursor: "",
+		},
+		{
+			name:           "return empty slice without error when no posts match",
+			params:         &entity.ListParams{Cursor: "ffffffff-ffff-ffff-ffff-ffffffffffff"},
+			wantIDs:        []string{},
+			wantNextCursor: "",
+		},
+		{
+			name:    "return error when cursor is malformed",
+			params:  &entity.ListParams{Cursor: "invalid-uuid"},
+			wantErr: apperr.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotNextCursor, err := rdb.NewPostRepository(testDB).List(ctx, tt.params)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, got, "empty results must be an empty slice, not nil")
+
+			gotIDs := make([]string, 0, len(got))
+			for _, post := range got {
+				gotIDs = append(gotIDs, post.ID)
+			}
+
+			assert.Equal(t, tt.wantIDs, gotIDs)
+			assert.Equal(t, tt.wantNextCursor, gotNextCursor)
+		})
+	}
+}
+
+func TestPostRepository_Search(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	testUser := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440040",
+		Name:     "Test User Search",
+		Email:    "testsearch@example.com",
+		TenantID: testTenantID,
+	}
+
+	published := bun.NullTime{Time: time.Now().Add(-time.Hour)}
+	scheduled := bun.NullTime{Time: time.Now().Add(time.Hour)}
+
+	// Titles use uncommon words so that posts inserted by other tests never match
+	fixtures := []*rdb.Post{
+		{ID: "eeeeeee1-0000-0000-0000-000000000000", Title: "Quokka habitats", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: published},
+		{ID: "eeeeeee2-0000-0000-0000-000000000000", Title: "Quokka care: feeding quokkas", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: published},
+		{ID: "eeeeeee3-0000-0000-0000-000000000000", Title: "Wombat burrows", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: published},
+		{ID: "eeeeeee4-0000-0000-0000-000000000000", Title: "Quokka draft", UserID: testUser.ID, TenantID: testTenantID},
+		{ID: "eeeeeee5-0000-0000-0000-000000000000", Title: "Quokka scheduled", UserID: testUser.ID, TenantID: testTenantID, PublishedAt: scheduled},
+	}
+
+	_, err := testDB.NewInsert().Model(testUser).Exec(ctx)
+	require.NoError(t, err)
+
+	for _, fixture := range fixtures {
+		_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		// Posts are deleted by cascade
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", testUser.ID).Exec(ctx)
+	})
+
+	tests := []struct {
+		name    string
+		query   string
+		limit   int
+		wantIDs []string
+		wantErr error
+	}{
+		{
+			name:    "return published matches ranked by relevance",
+			query:   "quokka",
+			wantIDs: []string{fixtures[1].ID, fixtures[0].ID},
+		},
+		{
+			name:    "match stemmed keywords",
+			query:   "burrow",
+			wantIDs: []string{fixtures[2].ID},
+		},
+		{
+			name:    "return most relevant matches up to limit",
+			query:   "quokka",
+			limit:   1,
+			wantIDs: []string{fixtures[1].ID},
+		},
+		{
+			name:    "return empty slice without error when no posts match",
+			query:   "platypus",
+			wantIDs: []string{},
+		},
+		{
+			name:    "return error when query is empty",
+			query:   "  ",
+			wantErr: apperr.ErrInvalidArgument,
+		},
+		{
+			name:    "return error when limit is negative",
+			query:   "quokka",
+			limit:   -1,
+			wantErr: apperr.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rdb.NewPostRepository(testDB).Search(ctx, tt.query, tt.limit)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, got, "empty results must be an empty slice, not nil")
+
+			gotIDs := make([]string, 0, len(got))
+			for _, post := range got {
+				gotIDs = append(gotIDs, post.ID)
+			}
+
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestPostRepository_DeleteByOwner(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	owner := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440010",
+		Name:     "Test Post Owner",
+		Email:    "postowner@example.com",
+		TenantID: testTenantID,
+	}
+	other := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440011",
+		Name:     "Test Other User",
+		Email:    "otheruser@example.com",
+		TenantID: testTenantID,
+	}
+	ownedPost := &rdb.Post{
+		ID:       "d1e4567e-e89b-12d3-a456-426614174000",
+		Title:    "Owned Post",
+		UserID:   owner.ID,
+		TenantID: testTenantID,
+	}
+
+	for _, fixture := range []any{owner, other, ownedPost} {
+		_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.Post)(nil)).Where("id = ?", ownedPost.ID).Exec(ctx)
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id IN (?, ?)", owner.ID, other.ID).Exec(ctx)
+	})
+
+	postExists := func(t *testing.T) bool {
+		t.Helper()
+
+		exists, err := testDB.NewSelect().Model((*rdb.Post)(nil)).Where("id = ?", ownedPost.ID).Exists(ctx)
+		require.NoError(t, err)
+
+		return exists
+	}
+
+	// Cases run in order: the owner deletes the post only after the failed attempts
+	tests := []struct {
+		name           string
+		postID         string
+		userID         string
+		wantErr        error
+		wantPostExists bool
+	}{
+		{
+			name:           "return not found when user is not the owner",
+			postID:         ownedPost.ID,
+			userID:         other.ID,
+			wantErr:        apperr.ErrNotFound,
+			wantPostExists: true,
+		},
+		{
+			name:           "return not found when post does not exist",
+			postID:         "d2e4567e-e89b-12d3-a456-426614174000",
+			userID:         owner.ID,
+			wantErr:        apperr.ErrNotFound,
+			wantPostExists: true,
+		},
+		{
+			name:           "return error when user ID is empty",
+			postID:         ownedPost.ID,
+			userID:         "",
+			wantErr:        apperr.ErrInvalidArgument,
+			wantPostExists: true,
+		},
+		{
+			name:           "delete post when user is the owner",
+			postID:         ownedPost.ID,
+			userID:         owner.ID,
+			wantPostExists: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rdb.NewPostRepository(testDB).DeleteByOwner(ctx, tt.postID, tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantPostExists, postExists(t))
+		})
+	}
+}
+
+func TestPostRepository_NilDatabase(t *testing.T) {
+	t.Parallel()
+
+	repo := rdb.NewPostRepository(nil)
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "Create",
+			call: func() error {
+				_, err := repo.Create(ctx, &entity.NewPost{Title: "Test Post", UserID: uuid.NewString()})
+				return err
+			},
+		},
+		{
+			name: "Get",
+			call: func() error {
+				_, err := repo.Get(ctx, uuid.NewString())
+				return err
+			},
+		},
+		{
+			name: "GetWithAuthor",
+			call: func() error {
+				_, _, err := repo.GetWithAuthor(ctx, uuid.NewString())
+				return err
+			},
+		},
+		{
+			name: "List",
+			call: func() error {
+				_, _, err := repo.List(ctx, &entity.ListParams{Limit: 10})
+				return err
+			},
+		},
+		{
+			name: "Search",
+			call: func() error {
+				_, err := repo.Search(ctx, "go", 10)
+				return err
+			},
+		},
+		{
+			name: "Delete",
+			call: func() error {
+				return repo.Delete(ctx, uuid.NewString())
+			},
+		},
+		{
+			name: "DeleteByOwner",
+			call: func() error {
+				return repo.DeleteByOwner(ctx, uuid.NewString(), uuid.NewString())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			require.NotPanics(t, func() { err = tt.call() })
+
+			assert.ErrorIs(t, err, apperr.ErrInternal)
+			assert.ErrorContains(t, err, "database not initialized")
+		})
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"maps"
+	"strconv"
 	"time"
 
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	"github.com/pannpers/go-backend-scaffold/pkg/config"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -18,47 +22,123 @@ import (
 type Database struct {
 	*bun.DB
 	logger *logging.Logger
+
+	// breaker guards repository calls; nil when the circuit breaker is disabled
+	breaker *CircuitBreaker
+
+	// poolWait warns about saturation of the connection pool; nil when disabled
+	poolWait *poolWaitMonitor
+
+	// clock is the time queries compare against instead of the clock of the database server,
+	// so that they agree with the times set by the use cases
+	clock clock.Clock
 }
 
 // New creates a new database instance with connection and ping verification.
 func New(ctx context.Context, cfg *config.Config, logger *logging.Logger) (*Database, error) {
 	// Create PostgreSQL driver
-	dsn := cfg.Database.GetDSN()
+	dsn := connectionDSN(&cfg.Database)
 	driver := pgdriver.NewConnector(pgdriver.WithDSN(dsn))
 
 	sqldb := sql.OpenDB(driver)
 
 	db := bun.NewDB(sqldb, pgdialect.New())
 
-	// Set connection pool settings
-	sqldb.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	sqldb.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	sqldb.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
+	configurePool(sqldb, &cfg.Database)
 
 	database := &Database{
 		DB:     db,
 		logger: logger,
+		clock:  clock.New(),
+	}
+
+	if cfg.Database.CircuitBreakerEnabled {
+		database.breaker = NewCircuitBreaker(
+			cfg.Database.CircuitBreakerThreshold,
+			time.Duration(cfg.Database.CircuitBreakerCooldown)*time.Second,
+		)
 	}
 
 	if err := database.Ping(ctx); err != nil {
+		// Log before returning so the cause is captured even if the process exits right after;
+		// the password is left out, and the user is not a secret
+		logger.Error(ctx, "Failed to connect to database", err,
+			slog.String("host", cfg.Database.Host),
+			slog.Int("port", cfg.Database.Port),
+			slog.String("database", cfg.Database.Name),
+			slog.String("user", cfg.Database.User),
+			slog.String("ssl_mode", cfg.Database.SSLMode),
+		)
+
+		_ = db.Close()
+
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if cfg.Database.PoolWaitThreshold > 0 {
+		database.poolWait = newPoolWaitMonitor(cfg.Database.PoolWaitThreshold)
+		go database.poolWait.run(logger, database.Stats, cfg.Database.PoolStatsInterval)
+	}
+
 	logger.Info(ctx, "Database connection established successfully",
 		slog.String("host", cfg.Database.Host),
 		slog.Int("port", cfg.Database.Port),
 		slog.String("database", cfg.Database.Name),
 		slog.Int("max_open_conns", cfg.Database.MaxOpenConns),
 		slog.Int("max_idle_conns", cfg.Database.MaxIdleConns),
+		slog.Int("conn_max_idle_time", cfg.Database.ConnMaxIdleTime),
+		slog.Bool("circuit_breaker_enabled", cfg.Database.CircuitBreakerEnabled),
 	)
 
 	return database, nil
 }
 
+// connectionDSN returns the DSN of cfg, setting the statement_timeout of every connection to
+// cfg.StatementTimeout unless it is disabled or Params sets statement_timeout explicitly.
+// Postgres then cancels runaway queries even when the context of a query has no deadline.
+func connectionDSN(cfg *config.DatabaseConfig) string {
+	if _, ok := cfg.Params["statement_timeout"]; ok || cfg.StatementTimeout <= 0 {
+		return cfg.GetDSN()
+	}
+
+	withTimeout := *cfg
+	withTimeout.Params = maps.Clone(cfg.Params)
+	if withTimeout.Params == nil {
+		withTimeout.Params = make(map[string]string, 1)
+	}
+
+	// Postgres reads a statement_timeout without unit as milliseconds. Round up so that a timeout
+	// below a millisecond does not truncate to 0, which would disable it.
+	millis := (cfg.StatementTimeout + time.Millisecond - 1) / time.Millisecond
+	withTimeout.Params["statement_timeout"] = strconv.FormatInt(int64(millis), 10)
+
+	return withTimeout.GetDSN()
+}
+
+// connPool is the subset of *sql.DB used to configure the connection pool.
+type connPool interface {
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+	SetConnMaxIdleTime(d time.Duration)
+}
+
+// configurePool applies the connection pool settings of cfg to pool.
+func configurePool(pool connPool, cfg *config.DatabaseConfig) {
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	// Close idle connections before the database or a load balancer drops them
+	pool.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
+}
+
 const pingTimeout = 5 * time.Second
 
 // Ping verifies the database connection.
+// It gives up after pingTimeout, or at the deadline of ctx if that is earlier, so health probes
+// with a tighter budget are not blocked for the full pingTimeout.
 func (d *Database) Ping(ctx context.Context) error {
+	// WithTimeout keeps the parent deadline when it is earlier than pingTimeout
 	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
 	defer cancel()
 
@@ -69,8 +149,53 @@ func (d *Database) Ping(ctx context.Context) error {
 	return nil
 }
 
+// readTxOptions are the options of the transactions of RunInReadTx.
+var readTxOptions = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+// RunInReadTx runs fn in a read-only REPEATABLE READ transaction, so that all reads made through the
+// bun.IDB passed to fn see the same snapshot of the database, unaffected by concurrent writes,
+// e.g. for reports issuing several queries. Writes through it fail.
+// It returns the error of fn as is.
+func (d *Database) RunInReadTx(ctx context.Context, fn func(ctx context.Context, db bun.IDB) error) error {
+	return d.RunInTx(ctx, readTxOptions, func(ctx context.Context, tx bun.Tx) error {
+		return fn(ctx, tx)
+	})
+}
+
+// WithLogger returns a copy of d that logs to logger, e.g. to capture the logs of repositories in tests.
+// The copy shares the connection pool and circuit breaker of d.
+func (d *Database) WithLogger(logger *logging.Logger) *Database {
+	clone := *d
+	clone.logger = logger
+
+	return &clone
+}
+
+// logDuration logs the time elapsed since start by a repository operation at Debug.
+// Call it deferred with time.Now() so that it also covers operations that fail:
+//
+//	defer r.db.logDuration(ctx, "UserRepository.Get", time.Now(), attr.UserID(id))
+func (d *Database) logDuration(ctx context.Context, operation string, start time.Time, attrs ...slog.Attr) {
+	if d.logger == nil {
+		return
+	}
+
+	d.logger.Debug(ctx, "Database operation completed",
+		append([]slog.Attr{attr.Operation(operation), attr.DurationMs(time.Since(start).Milliseconds())}, attrs...)...,
+	)
+}
+
+// Stats returns the connection pool statistics for diagnostics.
+func (d *Database) Stats() sql.DBStats {
+	return d.DB.DB.Stats()
+}
+
 // Close closes the database connection.
 func (d *Database) Close() error {
+	if d.poolWait != nil {
+		d.poolWait.close()
+	}
+
 	if d.DB != nil {
 		d.logger.Info(context.Background(), "Closing database connection")
 
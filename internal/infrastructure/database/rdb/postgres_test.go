@@ -0,0 +1,232 @@
+package rdb_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+)
+
+func TestDatabase_Ping_RespectsContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	// A server that accepts connections but never answers, so the ping hangs until it times out
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	go func() {
+		var conns []net.Conn
+
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				for _, c := range conns {
+					_ = c.Close()
+				}
+
+				return
+			}
+
+			conns = append(conns, conn)
+		}
+	}()
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:     "127.0.0.1",
+			Port:     ln.Addr().(*net.TCPAddr).Port,
+			Name:     "unreachable",
+			User:     "testuser",
+			Password: "testpassword",
+			SSLMode:  "disable",
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	// New pings the database with ctx
+	db, err := rdb.New(ctx, cfg, logging.New())
+
+	elapsed := time.Since(start)
+
+	assert.Error(t, err)
+	assert.Nil(t, db)
+	assert.Less(t, elapsed, time.Second, "expected ping to give up at the context deadline instead of the ping timeout")
+}
+
+func TestNew_LogsConnectionFailure(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on a port that was just released, so the connection is refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:     "127.0.0.1",
+			Port:     port,
+			Name:     "unreachable",
+			User:     "testuser",
+			Password: "secret-password",
+			SSLMode:  "disable",
+		},
+	}
+
+	logger, buf := logging.NewTestLogger()
+
+	db, err := rdb.New(context.Background(), cfg, logger)
+	require.Error(t, err)
+	assert.Nil(t, db)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "Failed to connect to database", entry["msg"])
+	assert.Equal(t, "127.0.0.1", entry["host"])
+	assert.Equal(t, float64(port), entry["port"])
+	assert.Equal(t, "unreachable", entry["database"])
+	assert.NotEmpty(t, entry["error"])
+	assert.NotContains(t, buf.String(), "secret-password")
+}
+
+func TestDatabase_RunInReadTx(t *testing.T) {
+	t.Parallel()
+
+	// A tenant of its own keeps the counts unaffected by other tests writing users in parallel
+	const tenantID = "tenant-read-tx"
+
+	ctx := tenant.NewContext(context.Background(), tenantID)
+	repo := rdb.NewUserRepository(testDB)
+
+	t.Cleanup(func() {
+		// Deletes the reader and the writer, whose IDs are generated
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).
+			Where("tenant_id = ?", tenantID).
+			Where("email IN (?)", bun.In([]string{"reader@example.com", "writer@example.com"})).
+			Exec(ctx)
+	})
+
+	_, err := repo.Create(ctx, &entity.NewUser{Name: "Reader", Email: "reader@example.com"})
+	require.NoError(t, err)
+
+	countUsers := func(ctx context.Context, db bun.IDB) int {
+		count, err := db.NewSelect().Model((*rdb.User)(nil)).Where("tenant_id = ?", tenantID).Count(ctx)
+		require.NoError(t, err)
+
+		return count
+	}
+
+	t.Run("reads see a consistent snapshot despite a concurrent write", func(t *testing.T) {
+		err := testDB.RunInReadTx(ctx, func(ctx context.Context, db bun.IDB) error {
+			before := countUsers(ctx, db)
+
+			// Written outside the transaction, as by a concurrent request
+			_, err := repo.Create(tenant.NewContext(context.Background(), tenantID),
+				&entity.NewUser{Name: "Writer", Email: "writer@example.com"},
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, before, countUsers(ctx, db))
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, countUsers(ctx, testDB))
+	})
+
+	t.Run("writes fail", func(t *testing.T) {
+		err := testDB.RunInReadTx(ctx, func(ctx context.Context, db bun.IDB) error {
+			_, err := db.NewInsert().Model(&rdb.User{Name: "Blocked", Email: "blocked@example.com", TenantID: tenantID}).Exec(ctx)
+
+			return err
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestNew_StatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:             "localhost",
+			Port:             5432,
+			Name:             "scaffold_test",
+			User:             "testuser",
+			Password:         "testpassword",
+			SSLMode:          "disable",
+			StatementTimeout: 100 * time.Millisecond,
+		},
+	}
+
+	db, err := rdb.New(context.Background(), cfg, logging.New())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	t.Run("Postgres cancels a statement running beyond the timeout", func(t *testing.T) {
+		start := time.Now()
+
+		// The context has no deadline, so only the statement timeout stops the query
+		_, err := db.NewRaw("SELECT pg_sleep(5)").Exec(context.Background())
+
+		var pgErr pgdriver.Error
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "57014", pgErr.Field('C'), "expected query_canceled")
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("repositories report the cancelled statement as DeadlineExceeded", func(t *testing.T) {
+		ctx := tenant.NewContext(context.Background(), "tenant-statement-timeout")
+
+		user, err := rdb.NewUserRepository(testDB).Create(ctx, &entity.NewUser{Name: "Locked", Email: "locked@example.com"})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", user.ID).Exec(ctx)
+		})
+
+		// Lock the row from a connection without the timeout, so that deleting it waits on the lock
+		tx, err := testDB.BeginTx(ctx, nil)
+		require.NoError(t, err)
+
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		_, err = tx.NewSelect().Model((*rdb.User)(nil)).Where("id = ?", user.ID).For("UPDATE").Exec(ctx)
+		require.NoError(t, err)
+
+		err = rdb.NewUserRepository(db).Delete(ctx, user.ID)
+
+		assert.ErrorIs(t, err, apperr.ErrDeadlineExceeded)
+		assert.ErrorContains(t, err, "statement timeout exceeded")
+	})
+}
@@ -13,6 +13,9 @@ import (
 
 var testDB *rdb.Database
 
+// testTenantID is the tenant that scopes repository calls and fixtures in tests.
+const testTenantID = "tenant-test"
+
 func TestMain(m *testing.M) {
 	testDB = setupTestDatabase()
 	testDB.AddQueryHook(bundebug.NewQueryHook(
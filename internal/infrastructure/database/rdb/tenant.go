@@ -0,0 +1,20 @@
+package rdb
+
+import (
+	"context"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+)
+
+// tenantFromContext returns the tenant that scopes queries in ctx.
+// Queries must never run unscoped, so a missing tenant is an InvalidArgument error.
+func tenantFromContext(ctx context.Context) (string, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", apperr.New(codes.InvalidArgument, "tenant is required")
+	}
+
+	return id, nil
+}
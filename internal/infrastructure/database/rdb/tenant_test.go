@@ -0,0 +1,123 @@
+package rdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositories_TenantIsolation(t *testing.T) {
+	const otherTenantID = "tenant-other"
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+	otherCtx := tenant.NewContext(context.Background(), otherTenantID)
+
+	testUser := &rdb.User{
+		ID:       "550e8400-e29b-41d4-a716-446655440002",
+		Name:     "Test User Tenant",
+		Email:    "testtenant@example.com",
+		TenantID: testTenantID,
+	}
+	testPost := &rdb.Post{
+		ID:       "239e4567-e89b-12d3-a456-426614174002",
+		Title:    "Test Post Tenant",
+		UserID:   testUser.ID,
+		TenantID: testTenantID,
+	}
+
+	_, err := testDB.NewInsert().Model(testUser).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = testDB.NewInsert().Model(testPost).Exec(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		// Posts are deleted by cascade
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", testUser.ID).Exec(ctx)
+	})
+
+	userRepo := rdb.NewUserRepository(testDB)
+	postRepo := rdb.NewPostRepository(testDB)
+
+	t.Run("return NotFound when reading user of another tenant", func(t *testing.T) {
+		got, err := userRepo.Get(otherCtx, testUser.ID)
+
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
+		assert.Nil(t, got)
+
+		got, err = userRepo.Get(ctx, testUser.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, testUser.ID, got.ID)
+	})
+
+	t.Run("return NotFound when reading post of another tenant", func(t *testing.T) {
+		got, err := postRepo.Get(otherCtx, testPost.ID)
+
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
+		assert.Nil(t, got)
+	})
+
+	t.Run("exclude rows of another tenant from lists", func(t *testing.T) {
+		users, _, err := userRepo.List(otherCtx, &entity.ListParams{})
+
+		require.NoError(t, err)
+		assert.NotContains(t, userIDs(users), testUser.ID)
+	})
+
+	t.Run("return NotFound when deleting post of another tenant", func(t *testing.T) {
+		err := postRepo.Delete(otherCtx, testPost.ID)
+
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
+	})
+
+	t.Run("return FailedPrecondition when author belongs to another tenant", func(t *testing.T) {
+		got, err := postRepo.Create(otherCtx, &entity.NewPost{Title: "Cross Tenant Post", UserID: testUser.ID})
+
+		assert.ErrorIs(t, err, apperr.ErrFailedPrecondition)
+		assert.Nil(t, got)
+	})
+
+	t.Run("create user with email taken in another tenant", func(t *testing.T) {
+		created, err := userRepo.Create(otherCtx, &entity.NewUser{Name: "Other Tenant User", Email: testUser.Email})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", created.ID).Exec(ctx)
+		})
+
+		assert.NotEqual(t, testUser.ID, created.ID)
+
+		got, err := userRepo.GetByEmail(ctx, testUser.Email)
+
+		require.NoError(t, err)
+		assert.Equal(t, testUser.ID, got.ID)
+	})
+
+	t.Run("return InvalidArgument without tenant", func(t *testing.T) {
+		created, err := userRepo.Create(context.Background(), &entity.NewUser{Name: "No Tenant", Email: "notenant@example.com"})
+
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+		assert.Nil(t, created)
+
+		got, err := userRepo.Get(context.Background(), testUser.ID)
+
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+		assert.Nil(t, got)
+	})
+}
+
+func userIDs(users []*entity.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	return ids
+}
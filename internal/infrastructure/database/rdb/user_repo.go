@@ -2,11 +2,15 @@ package rdb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
 )
 
 // UserRepository implements entity.UserRepository interface.
@@ -15,21 +19,55 @@ type UserRepository struct {
 }
 
 // NewUserRepository creates a new user repository instance.
+// Its calls are guarded by the circuit breaker of db when it is enabled.
+// If db is nil, e.g. when misconfigured, every call returns codes.Internal instead of panicking.
 func NewUserRepository(db *Database) entity.UserRepository {
-	return &UserRepository{db: db}
+	repo := &UserRepository{db: db}
+
+	if db != nil && db.breaker != nil {
+		return &breakerUserRepository{next: repo, breaker: db.breaker}
+	}
+
+	return repo
 }
 
 // Create creates a new user in the database.
+// Emails are unique per tenant and stored normalized, so it returns codes.AlreadyExists for an email
+// that differs from one of another user of the tenant only in case.
 func (r *UserRepository) Create(ctx context.Context, params *entity.NewUser) (*entity.User, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, err
+	}
+
 	if params == nil {
 		return nil, apperr.New(codes.InvalidArgument, "params cannot be nil")
 	}
 
+	// The ID is generated by the insert, so it is not logged
+	defer r.db.logDuration(ctx, "UserRepository.Create", time.Now())
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	row := FromNewUser(params)
+	row.TenantID = tenantID
 
-	_, err := r.db.NewInsert().Model(row).Exec(ctx)
+	// Scan the row back so the ID and timestamps generated by the database are always populated
+	_, err = r.db.NewInsert().Model(row).Returning("*").Exec(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		if isUniqueViolation(err) {
+			// The email is left out since the message may reach clients and logs
+			return nil, apperr.Wrap(err, codes.AlreadyExists, "user with this email already exists")
+		}
+		if constraint, ok := checkViolationConstraint(err); ok {
+			return nil, apperr.New(codes.InvalidArgument,
+				fmt.Sprintf("user violates check constraint %s", constraint),
+				attr.Constraint(constraint),
+			)
+		}
+		return nil, queryError(err, "failed to create user")
 	}
 
 	return row.ToEntity(), nil
@@ -37,31 +75,180 @@ func (r *UserRepository) Create(ctx context.Context, params *entity.NewUser) (*e
 
 // Get retrieves a user by ID from the database.
 func (r *UserRepository) Get(ctx context.Context, id string) (*entity.User, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, err
+	}
+
 	if id == "" {
 		return nil, apperr.New(codes.InvalidArgument, "user ID cannot be empty")
 	}
 
+	defer r.db.logDuration(ctx, "UserRepository.Get", time.Now(), attr.UserID(id))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	row := &User{}
-	err := r.db.NewSelect().Model(row).Where("id = ?", id).Scan(ctx)
+	err = r.db.NewSelect().Model(row).Where("id = ?", id).Where("tenant_id = ?", tenantID).Scan(ctx)
 	if err != nil {
 		if err.Error() == "sql: no rows in result set" {
 			return nil, apperr.New(codes.NotFound, fmt.Sprintf("user with ID %s not found", id))
 		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, queryError(err, "failed to get user")
 	}
 
 	return row.ToEntity(), nil
 }
 
+// GetByEmail retrieves a user by email from the database.
+// The lookup is case-insensitive since emails are stored normalized.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, err
+	}
+
+	email = NormalizeEmail(email)
+	if email == "" {
+		return nil, apperr.New(codes.InvalidArgument, "email cannot be empty")
+	}
+
+	defer r.db.logDuration(ctx, "UserRepository.GetByEmail", time.Now())
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &User{}
+	err = r.db.NewSelect().Model(row).Where("email = ?", email).Where("tenant_id = ?", tenantID).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The email is left out since the message reaches clients, logs, and traces
+			return nil, apperr.Wrap(err, codes.NotFound, "user not found")
+		}
+		return nil, queryError(err, "failed to get user by email")
+	}
+
+	return row.ToEntity(), nil
+}
+
+// upsertedUser is a users row returned by an upsert, with whether the upsert inserted it.
+type upsertedUser struct {
+	User `bun:",extend"`
+
+	Created bool `bun:",scanonly"`
+}
+
+// Upsert creates a user unless one with the email exists, and returns the user either way.
+// created reports whether the user was created; an existing user is returned unchanged.
+// The email is normalized first, so an email that differs from an existing one only in case
+// returns the existing user. Emails are unique per tenant, so a user of another tenant with the
+// same email is never returned.
+func (r *UserRepository) Upsert(ctx context.Context, params *entity.NewUser) (*entity.User, bool, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, false, err
+	}
+
+	if params == nil {
+		return nil, false, apperr.New(codes.InvalidArgument, "params cannot be nil")
+	}
+
+	defer r.db.logDuration(ctx, "UserRepository.Upsert", time.Now())
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	row := &upsertedUser{User: *FromNewUser(params)}
+	row.TenantID = tenantID
+
+	// The no-op update makes the conflicting row returned, and xmax is 0 only for inserted rows
+	err = r.db.NewInsert().Model(row).
+		On("CONFLICT (email, tenant_id) DO UPDATE").
+		Set("email = EXCLUDED.email").
+		Returning("*, (xmax = 0) AS created").
+		Scan(ctx)
+	if err != nil {
+		if constraint, ok := checkViolationConstraint(err); ok {
+			return nil, false, apperr.New(codes.InvalidArgument,
+				fmt.Sprintf("user violates check constraint %s", constraint),
+				attr.Constraint(constraint),
+			)
+		}
+		return nil, false, queryError(err, "failed to upsert user")
+	}
+
+	return row.ToEntity(), row.Created, nil
+}
+
+// Exists reports whether a user with the ID exists, without fetching the row.
+func (r *UserRepository) Exists(ctx context.Context, id string) (bool, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return false, err
+	}
+
+	if id == "" {
+		return false, apperr.New(codes.InvalidArgument, "user ID cannot be empty")
+	}
+
+	defer r.db.logDuration(ctx, "UserRepository.Exists", time.Now(), attr.UserID(id))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := r.db.NewSelect().Model((*User)(nil)).Where("id = ?", id).Where("tenant_id = ?", tenantID).Exists(ctx)
+	if err != nil {
+		if isInvalidUUIDFormat(err) {
+			return false, apperr.Wrap(err, codes.InvalidArgument,
+				fmt.Sprintf("invalid UUID format: %s", id),
+			)
+		}
+		return false, queryError(err, "failed to check user existence")
+	}
+
+	return exists, nil
+}
+
+// List retrieves a page of users ordered by ID from the database.
+// It returns an empty slice, not an error, when no users match.
+func (r *UserRepository) List(ctx context.Context, params *entity.ListParams) ([]*entity.User, string, error) {
+	if err := checkDatabase(r.db); err != nil {
+		return nil, "", err
+	}
+
+	defer r.db.logDuration(ctx, "UserRepository.List", time.Now())
+
+	return list(ctx, r.db, params, "users", nil,
+		func(row *User) string { return row.ID },
+		(*User).ToEntity,
+	)
+}
+
 // Delete removes a user from the database.
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	if err := checkDatabase(r.db); err != nil {
+		return err
+	}
+
 	if id == "" {
 		return apperr.New(codes.InvalidArgument, "user ID cannot be empty")
 	}
 
-	result, err := r.db.NewDelete().Model((*User)(nil)).Where("id = ?", id).Exec(ctx)
+	defer r.db.logDuration(ctx, "UserRepository.Delete", time.Now(), attr.UserID(id))
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.NewDelete().Model((*User)(nil)).Where("id = ?", id).Where("tenant_id = ?", tenantID).Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return queryError(err, "failed to delete user")
 	}
 
 	rowsAffected, err := result.RowsAffected()
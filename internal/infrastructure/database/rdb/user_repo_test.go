@@ -0,0 +1,461 @@
+package rdb_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/rdb"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository_List(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	// Fixtures use the top of the UUID range and cursors start right below it,
+	// so rows inserted by other tests never appear in the pages
+	fixtures := []*rdb.User{
+		{ID: "fffffff1-0000-0000-0000-000000000000", Name: "List User 1", Email: "list1@example.com", TenantID: testTenantID},
+		{ID: "fffffff2-0000-0000-0000-000000000000", Name: "List User 2", Email: "list2@example.com", TenantID: testTenantID},
+		{ID: "fffffff3-0000-0000-0000-000000000000", Name: "List User 3", Email: "list3@example.com", TenantID: testTenantID},
+	}
+
+	for _, fixture := range fixtures {
+		_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		for _, fixture := range fixtures {
+			_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", fixture.ID).Exec(ctx)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		params         *entity.ListParams
+		wantIDs        []string
+		wantNextCursor string
+		wantErr        error
+	}{
+		{
+			name:           "return first page with next cursor",
+			params:         &entity.ListParams{Limit: 2, Cursor: "fffffff0-0000-0000-0000-000000000000"},
+			wantIDs:        []string{fixtures[0].ID, fixtures[1].ID},
+			wantNextCursor: fixtures[1].ID,
+		},
+		{
+			name:           "return last page without next cursor",
+			params:         &entity.ListParams{Limit: 2, Cursor: fixtures[1].ID},
+			wantIDs:        []string{fixtures[2].ID},
+			wantNextCursor: "",
+		},
+		{
+			name:           "return no next cursor when exactly limit users remain",
+			params:         &entity.ListParams{Limit: 3, Cursor: "fffffff0-0000-0000-0000-000000000000"},
+			wantIDs:        []string{fixtures[0].ID, fixtures[1].ID, fixtures[2].ID},
+			wantNextCursor: "",
+		},
+		{
+			name:           "return next cursor when one user more than limit remains",
+			params:         &entity.ListParams{Limit: 1, Cursor: fixtures[0].ID},
+			wantIDs:        []string{fixtures[1].ID},
+			wantNextCursor: fixtures[1].ID,
+		},
+		{
+			name:           "return no next cursor for last user at limit one",
+			params:         &entity.ListParams{Limit: 1, Cursor: fixtures[1].ID},
+			wantIDs:        []string{fixtures[2].ID},
+			wantNextCursor: "",
+		},
+		{
+			name:           "return empty slice without error when no users match",
+			params:         &entity.ListParams{Cursor: "ffffffff-ffff-ffff-ffff-ffffffffffff"},
+			wantIDs:        []string{},
+			wantNextCursor: "",
+		},
+		{
+			name:    "return error when cursor is malformed",
+			params:  &entity.ListParams{Cursor: "invalid-uuid"},
+			wantErr: apperr.ErrInvalidArgument,
+		},
+		{
+			name:    "return error when limit is negative",
+			params:  &entity.ListParams{Limit: -1},
+			wantErr: apperr.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotNextCursor, err := rdb.NewUserRepository(testDB).List(ctx, tt.params)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, got, "empty results must be an empty slice, not nil")
+
+			gotIDs := make([]string, 0, len(got))
+			for _, user := range got {
+				gotIDs = append(gotIDs, user.ID)
+			}
+
+			assert.Equal(t, tt.wantIDs, gotIDs)
+			assert.Equal(t, tt.wantNextCursor, gotNextCursor)
+		})
+	}
+}
+
+func TestUserRepository_Exists(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	fixture := &rdb.User{
+		ID:       "e1e4567e-e89b-12d3-a456-426614174000",
+		Name:     "Exists User",
+		Email:    "exists@example.com",
+		TenantID: testTenantID,
+	}
+
+	_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", fixture.ID).Exec(ctx)
+	})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      string
+		want    bool
+		wantErr error
+	}{
+		{
+			name: "return true when user exists",
+			ctx:  ctx,
+			id:   fixture.ID,
+			want: true,
+		},
+		{
+			name: "return false when user does not exist",
+			ctx:  ctx,
+			id:   "123e4567-e89b-12d3-a456-426614174000",
+			want: false,
+		},
+		{
+			name: "return false when user belongs to another tenant",
+			ctx:  tenant.NewContext(context.Background(), "other-tenant"),
+			id:   fixture.ID,
+			want: false,
+		},
+		{
+			name:    "return error when user ID is empty",
+			ctx:     ctx,
+			id:      "",
+			wantErr: apperr.ErrInvalidArgument,
+		},
+		{
+			name:    "return error when malformed UUID",
+			ctx:     ctx,
+			id:      "not-a-uuid",
+			wantErr: apperr.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := rdb.NewUserRepository(testDB).Exists(tt.ctx, tt.id)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUserRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+	repo := rdb.NewUserRepository(testDB)
+
+	created, err := repo.Create(ctx, &entity.NewUser{Name: "Created User", Email: "created@example.com"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", created.ID).Exec(ctx)
+	})
+
+	// The ID and timestamps are generated by the database
+	_, err = uuid.Parse(created.ID)
+	require.NoError(t, err)
+	assert.NotZero(t, created.CreatedAt)
+	assert.NotZero(t, created.UpdatedAt)
+
+	// The returned user reflects the stored row, including generated fields
+	stored, err := repo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stored, created)
+}
+
+func TestUserRepository_Create_NormalizesEmail(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+	repo := rdb.NewUserRepository(testDB)
+
+	created, err := repo.Create(ctx, &entity.NewUser{Name: "Mixed Case", Email: "  Mixed.Case@Example.com "})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", created.ID).Exec(ctx)
+	})
+
+	assert.Equal(t, "mixed.case@example.com", created.Email)
+
+	// An email differing only in case collides with the stored one
+	_, err = repo.Create(ctx, &entity.NewUser{Name: "Lower Case", Email: "mixed.case@example.com"})
+	assert.ErrorIs(t, err, apperr.ErrAlreadyExists)
+}
+
+func TestUserRepository_Upsert(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+	repo := rdb.NewUserRepository(testDB)
+
+	first, created, err := repo.Upsert(ctx, &entity.NewUser{Name: "Upserted User", Email: "upserted@example.com"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", first.ID).Exec(ctx)
+	})
+
+	assert.True(t, created)
+	_, err = uuid.Parse(first.ID)
+	require.NoError(t, err)
+
+	// The second call returns the stored user unchanged, matching the normalized email
+	second, created, err := repo.Upsert(ctx, &entity.NewUser{Name: "Renamed User", Email: " Upserted@Example.com "})
+	require.NoError(t, err)
+
+	assert.False(t, created)
+	assert.Equal(t, first, second)
+
+	stored, err := repo.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stored, second)
+
+	// Emails are unique per tenant, so another tenant gets its own user
+	otherCtx := tenant.NewContext(context.Background(), "tenant-upsert-other")
+	other, created, err := repo.Upsert(otherCtx, &entity.NewUser{Name: "Other Tenant", Email: "upserted@example.com"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", other.ID).Exec(ctx)
+	})
+
+	assert.True(t, created)
+	assert.NotEqual(t, first.ID, other.ID)
+}
+
+func TestUserRepository_GetByEmail(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	fixture := &rdb.User{
+		ID:       "e2e4567e-e89b-12d3-a456-426614174000",
+		Name:     "Get By Email User",
+		Email:    "getbyemail@example.com",
+		TenantID: testTenantID,
+	}
+
+	_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", fixture.ID).Exec(ctx)
+	})
+
+	tests := []struct {
+		name    string
+		email   string
+		wantID  string
+		wantErr error
+	}{
+		{
+			name:   "return user when email matches",
+			email:  "getbyemail@example.com",
+			wantID: fixture.ID,
+		},
+		{
+			name:   "return user when email differs in case and spaces",
+			email:  " GetByEmail@Example.COM ",
+			wantID: fixture.ID,
+		},
+		{
+			name:    "return error when user does not exist",
+			email:   "missing@example.com",
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name:    "return error when email is empty",
+			email:   "  ",
+			wantErr: apperr.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := rdb.NewUserRepository(testDB).GetByEmail(ctx, tt.email)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.NotContains(t, err.Error(), "missing@example.com", "the email must not be in the message")
+				assert.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, got.ID)
+		})
+	}
+}
+
+func TestUserRepository_Get_LogsDuration(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	fixture := &rdb.User{
+		ID:       "e3e4567e-e89b-12d3-a456-426614174000",
+		Name:     "Duration User",
+		Email:    "duration@example.com",
+		TenantID: testTenantID,
+	}
+
+	_, err := testDB.NewInsert().Model(fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = testDB.NewDelete().Model((*rdb.User)(nil)).Where("id = ?", fixture.ID).Exec(ctx)
+	})
+
+	logBuffer := &bytes.Buffer{}
+	db := testDB.WithLogger(logging.New(
+		logging.WithWriter(logBuffer),
+		logging.WithFormat(logging.FormatJSON),
+		logging.WithLevel(slog.LevelDebug),
+	))
+
+	_, err = rdb.NewUserRepository(db).Get(ctx, fixture.ID)
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &entry))
+
+	assert.Equal(t, "DEBUG", entry["level"])
+	assert.Equal(t, "UserRepository.Get", entry["operation"])
+	assert.Equal(t, fixture.ID, entry["user_id"])
+	assert.Contains(t, entry, "duration_ms")
+}
+
+func TestUserRepository_NilDatabase(t *testing.T) {
+	t.Parallel()
+
+	repo := rdb.NewUserRepository(nil)
+	ctx := tenant.NewContext(context.Background(), testTenantID)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "Create",
+			call: func() error {
+				_, err := repo.Create(ctx, &entity.NewUser{Name: "John Doe", Email: "john@example.com"})
+				return err
+			},
+		},
+		{
+			name: "Get",
+			call: func() error {
+				_, err := repo.Get(ctx, uuid.NewString())
+				return err
+			},
+		},
+		{
+			name: "GetByEmail",
+			call: func() error {
+				_, err := repo.GetByEmail(ctx, "john@example.com")
+				return err
+			},
+		},
+		{
+			name: "Upsert",
+			call: func() error {
+				_, _, err := repo.Upsert(ctx, &entity.NewUser{Name: "John Doe", Email: "john@example.com"})
+				return err
+			},
+		},
+		{
+			name: "Exists",
+			call: func() error {
+				_, err := repo.Exists(ctx, uuid.NewString())
+				return err
+			},
+		},
+		{
+			name: "List",
+			call: func() error {
+				_, _, err := repo.List(ctx, &entity.ListParams{Limit: 10})
+				return err
+			},
+		},
+		{
+			name: "Delete",
+			call: func() error {
+				return repo.Delete(ctx, uuid.NewString())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			require.NotPanics(t, func() { err = tt.call() })
+
+			assert.ErrorIs(t, err, apperr.ErrInternal)
+			assert.ErrorContains(t, err, "database not initialized")
+		})
+	}
+}
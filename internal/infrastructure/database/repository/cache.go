@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+)
+
+// maxCacheEntries bounds the entries of each cache, so that lookups of many distinct IDs
+// cannot grow it without limit.
+const maxCacheEntries = 10000
+
+// WithCache returns a Decorator caching users and posts retrieved by ID for ttl.
+// Deleting through the decorated repository evicts the entry, but changes made elsewhere,
+// e.g. by another instance, are only seen once the entry expires, so ttl bounds staleness.
+// Entries are scoped by the tenant of the context like the queries they cache.
+func WithCache(ttl time.Duration) Decorator {
+	c := clock.New()
+
+	return Decorator{
+		user: func(next entity.UserRepository) entity.UserRepository {
+			return &cachedUserRepository{next: next, cache: newTTLCache[entity.User](ttl, c)}
+		},
+		post: func(next entity.PostRepository) entity.PostRepository {
+			return &cachedPostRepository{next: next, cache: newTTLCache[entity.Post](ttl, c)}
+		},
+	}
+}
+
+// cachedUserRepository caches the users retrieved by ID from a UserRepository.
+// Every method is implemented explicitly, so that a method added to the interface
+// cannot bypass eviction unnoticed.
+type cachedUserRepository struct {
+	next  entity.UserRepository
+	cache *ttlCache[entity.User]
+}
+
+// Create creates a new user.
+func (r *cachedUserRepository) Create(ctx context.Context, params *entity.NewUser) (*entity.User, error) {
+	return r.next.Create(ctx, params)
+}
+
+// Get retrieves a user by ID from the cache, or from the repository on a miss.
+func (r *cachedUserRepository) Get(ctx context.Context, id string) (*entity.User, error) {
+	key, ok := cacheKey(ctx, id)
+	if !ok {
+		return r.next.Get(ctx, id)
+	}
+
+	if user, ok := r.cache.get(key); ok {
+		return user, nil
+	}
+
+	user, err := r.next.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.set(key, user)
+
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email. Lookups by email are not cached.
+func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.next.GetByEmail(ctx, email)
+}
+
+// Upsert creates or retrieves a user by email.
+func (r *cachedUserRepository) Upsert(ctx context.Context, params *entity.NewUser) (*entity.User, bool, error) {
+	return r.next.Upsert(ctx, params)
+}
+
+// Exists reports whether a user exists.
+func (r *cachedUserRepository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.next.Exists(ctx, id)
+}
+
+// List retrieves a page of users.
+func (r *cachedUserRepository) List(ctx context.Context, params *entity.ListParams) ([]*entity.User, string, error) {
+	return r.next.List(ctx, params)
+}
+
+// Delete removes a user and evicts it from the cache.
+func (r *cachedUserRepository) Delete(ctx context.Context, id string) error {
+	if key, ok := cacheKey(ctx, id); ok {
+		defer r.cache.delete(key)
+	}
+
+	return r.next.Delete(ctx, id)
+}
+
+// cachedPostRepository caches the posts retrieved by ID from a PostRepository.
+// Every method is implemented explicitly, so that a method added to the interface
+// cannot bypass eviction unnoticed.
+type cachedPostRepository struct {
+	next  entity.PostRepository
+	cache *ttlCache[entity.Post]
+}
+
+// Create creates a new post.
+func (r *cachedPostRepository) Create(ctx context.Context, params *entity.NewPost) (*entity.Post, error) {
+	return r.next.Create(ctx, params)
+}
+
+// Get retrieves a post by ID from the cache, or from the repository on a miss.
+func (r *cachedPostRepository) Get(ctx context.Context, id string) (*entity.Post, error) {
+	key, ok := cacheKey(ctx, id)
+	if !ok {
+		return r.next.Get(ctx, id)
+	}
+
+	if post, ok := r.cache.get(key); ok {
+		return post, nil
+	}
+
+	post, err := r.next.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.set(key, post)
+
+	return post, nil
+}
+
+// GetWithAuthor retrieves a post and its author. Posts retrieved with their author are not cached.
+func (r *cachedPostRepository) GetWithAuthor(ctx context.Context, id string) (*entity.Post, *entity.User, error) {
+	return r.next.GetWithAuthor(ctx, id)
+}
+
+// List retrieves a page of posts.
+func (r *cachedPostRepository) List(ctx context.Context, params *entity.ListParams) ([]*entity.Post, string, error) {
+	return r.next.List(ctx, params)
+}
+
+// Search retrieves posts matching query.
+func (r *cachedPostRepository) Search(ctx context.Context, query string, limit int) ([]*entity.Post, error) {
+	return r.next.Search(ctx, query, limit)
+}
+
+// Delete removes a post and evicts it from the cache.
+func (r *cachedPostRepository) Delete(ctx context.Context, id string) error {
+	if key, ok := cacheKey(ctx, id); ok {
+		defer r.cache.delete(key)
+	}
+
+	return r.next.Delete(ctx, id)
+}
+
+// DeleteByOwner removes a post of the given owner and evicts it from the cache.
+func (r *cachedPostRepository) DeleteByOwner(ctx context.Context, postID, userID string) error {
+	if key, ok := cacheKey(ctx, postID); ok {
+		defer r.cache.delete(key)
+	}
+
+	return r.next.DeleteByOwner(ctx, postID, userID)
+}
+
+// cacheKey returns the key of the entity with id in the tenant of ctx.
+// It reports false without a tenant, leaving the repository to reject the call.
+func cacheKey(ctx context.Context, id string) (string, bool) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	return tenantID + "/" + id, true
+}
+
+// cacheEntry is a cached value with its expiry.
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache is an in-memory cache whose entries expire after a fixed ttl.
+// It stores copies of values, so callers cannot modify cached entities. It is safe for concurrent use.
+type ttlCache[V any] struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry[V]
+}
+
+// newTTLCache creates an empty cache whose entries expire after ttl.
+func newTTLCache[V any](ttl time.Duration, c clock.Clock) *ttlCache[V] {
+	return &ttlCache[V]{
+		ttl:     ttl,
+		clock:   c,
+		entries: make(map[string]cacheEntry[V]),
+	}
+}
+
+// get returns a copy of the unexpired value of key.
+func (c *ttlCache[V]) get(key string) (*V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+
+		return nil, false
+	}
+
+	value := entry.value
+
+	return &value, true
+}
+
+// set stores a copy of value for key. When the cache is full, expired entries are dropped first,
+// and value is not stored if none have expired.
+func (c *ttlCache[V]) set(key string, value *V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+
+		if len(c.entries) >= maxCacheEntries {
+			return
+		}
+	}
+
+	c.entries[key] = cacheEntry[V]{value: *value, expiresAt: now.Add(c.ttl)}
+}
+
+// delete evicts key.
+func (c *ttlCache[V]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
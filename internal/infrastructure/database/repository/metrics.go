@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+)
+
+// durationMetric is the name of the histogram of repository call durations.
+const durationMetric = "app.repository.duration"
+
+// WithMetrics returns a Decorator recording the duration of every repository call in the
+// app.repository.duration histogram, labeled with the method and the resulting code,
+// e.g. "ok" or "not_found".
+func WithMetrics(meter otelmetric.Meter) (Decorator, error) {
+	duration, err := meter.Float64Histogram(durationMetric,
+		otelmetric.WithDescription("Duration of repository calls."),
+		otelmetric.WithUnit("s"),
+	)
+	if err != nil {
+		return Decorator{}, fmt.Errorf("failed to create repository duration metric: %w", err)
+	}
+
+	return intercept(func(ctx context.Context, c call, fn func(ctx context.Context) error) error {
+		start := time.Now()
+		err := fn(ctx)
+
+		duration.Record(ctx, time.Since(start).Seconds(), otelmetric.WithAttributes(
+			attribute.String("method", c.method),
+			attribute.String("code", resultCode(err)),
+		))
+
+		return err
+	}), nil
+}
+
+// resultCode returns the code of the outcome of a call for labeling metrics.
+func resultCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	var appErr *apperr.AppErr
+	if errors.As(err, &appErr) {
+		return appErr.Code.String()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,219 @@
+// Package repository composes cross-cutting concerns, such as metrics, retries, and caching,
+// around any implementation of entity.UserRepository and entity.PostRepository.
+//
+// Each concern is a Decorator, and decorators are chained around a repository in order,
+// the first being the outermost:
+//
+//	users := repository.DecorateUserRepository(rdb.NewUserRepository(db),
+//		metrics,
+//		repository.WithCache(time.Minute),
+//		repository.WithRetry(3, 50*time.Millisecond),
+//	)
+//
+// Here every call is measured, including cache hits, and only cache misses are retried.
+package repository
+
+import (
+	"context"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+)
+
+// Decorator wraps repositories with a cross-cutting concern.
+type Decorator struct {
+	user func(entity.UserRepository) entity.UserRepository
+	post func(entity.PostRepository) entity.PostRepository
+}
+
+// DecorateUserRepository wraps repo with decorators, the first being the outermost.
+func DecorateUserRepository(repo entity.UserRepository, decorators ...Decorator) entity.UserRepository {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		repo = decorators[i].user(repo)
+	}
+
+	return repo
+}
+
+// DecoratePostRepository wraps repo with decorators, the first being the outermost.
+func DecoratePostRepository(repo entity.PostRepository, decorators ...Decorator) entity.PostRepository {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		repo = decorators[i].post(repo)
+	}
+
+	return repo
+}
+
+// call describes a repository method call passed to an interceptor.
+type call struct {
+	// method is the qualified method name, e.g. "UserRepository.Get"
+	method string
+	// readOnly reports whether the method leaves data unchanged, so that it is safe to repeat
+	readOnly bool
+}
+
+// interceptor runs fn, the repository method described by c, adding behavior around it.
+type interceptor func(ctx context.Context, c call, fn func(ctx context.Context) error) error
+
+// intercept returns a Decorator that runs every repository call through i.
+func intercept(i interceptor) Decorator {
+	return Decorator{
+		user: func(next entity.UserRepository) entity.UserRepository {
+			return &interceptedUserRepository{next: next, intercept: i}
+		},
+		post: func(next entity.PostRepository) entity.PostRepository {
+			return &interceptedPostRepository{next: next, intercept: i}
+		},
+	}
+}
+
+// interceptedUserRepository runs the calls of a UserRepository through an interceptor.
+type interceptedUserRepository struct {
+	next      entity.UserRepository
+	intercept interceptor
+}
+
+// Create creates a new user.
+func (r *interceptedUserRepository) Create(ctx context.Context, params *entity.NewUser) (user *entity.User, err error) {
+	err = r.intercept(ctx, call{method: "UserRepository.Create"}, func(ctx context.Context) error {
+		user, err = r.next.Create(ctx, params)
+		return err
+	})
+
+	return user, err
+}
+
+// Get retrieves a user by ID.
+func (r *interceptedUserRepository) Get(ctx context.Context, id string) (user *entity.User, err error) {
+	err = r.intercept(ctx, call{method: "UserRepository.Get", readOnly: true}, func(ctx context.Context) error {
+		user, err = r.next.Get(ctx, id)
+		return err
+	})
+
+	return user, err
+}
+
+// GetByEmail retrieves a user by email.
+func (r *interceptedUserRepository) GetByEmail(ctx context.Context, email string) (user *entity.User, err error) {
+	err = r.intercept(ctx, call{method: "UserRepository.GetByEmail", readOnly: true}, func(ctx context.Context) error {
+		user, err = r.next.GetByEmail(ctx, email)
+		return err
+	})
+
+	return user, err
+}
+
+// Upsert creates or retrieves a user by email.
+func (r *interceptedUserRepository) Upsert(ctx context.Context, params *entity.NewUser) (user *entity.User, created bool, err error) {
+	err = r.intercept(ctx, call{method: "UserRepository.Upsert"}, func(ctx context.Context) error {
+		user, created, err = r.next.Upsert(ctx, params)
+		return err
+	})
+
+	return user, created, err
+}
+
+// Exists reports whether a user exists.
+func (r *interceptedUserRepository) Exists(ctx context.Context, id string) (exists bool, err error) {
+	err = r.intercept(ctx, call{method: "UserRepository.Exists", readOnly: true}, func(ctx context.Context) error {
+		exists, err = r.next.Exists(ctx, id)
+		return err
+	})
+
+	return exists, err
+}
+
+// List retrieves a page of users.
+func (r *interceptedUserRepository) List(
+	ctx context.Context,
+	params *entity.ListParams,
+) (users []*entity.User, nextPageToken string, err error) {
+	err = r.intercept(ctx, call{method: "UserRepository.List", readOnly: true}, func(ctx context.Context) error {
+		users, nextPageToken, err = r.next.List(ctx, params)
+		return err
+	})
+
+	return users, nextPageToken, err
+}
+
+// Delete removes a user.
+func (r *interceptedUserRepository) Delete(ctx context.Context, id string) error {
+	return r.intercept(ctx, call{method: "UserRepository.Delete"}, func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+// interceptedPostRepository runs the calls of a PostRepository through an interceptor.
+type interceptedPostRepository struct {
+	next      entity.PostRepository
+	intercept interceptor
+}
+
+// Create creates a new post.
+func (r *interceptedPostRepository) Create(ctx context.Context, params *entity.NewPost) (post *entity.Post, err error) {
+	err = r.intercept(ctx, call{method: "PostRepository.Create"}, func(ctx context.Context) error {
+		post, err = r.next.Create(ctx, params)
+		return err
+	})
+
+	return post, err
+}
+
+// Get retrieves a post by ID.
+func (r *interceptedPostRepository) Get(ctx context.Context, id string) (post *entity.Post, err error) {
+	err = r.intercept(ctx, call{method: "PostRepository.Get", readOnly: true}, func(ctx context.Context) error {
+		post, err = r.next.Get(ctx, id)
+		return err
+	})
+
+	return post, err
+}
+
+// GetWithAuthor retrieves a post and its author.
+func (r *interceptedPostRepository) GetWithAuthor(
+	ctx context.Context,
+	id string,
+) (post *entity.Post, author *entity.User, err error) {
+	err = r.intercept(ctx, call{method: "PostRepository.GetWithAuthor", readOnly: true}, func(ctx context.Context) error {
+		post, author, err = r.next.GetWithAuthor(ctx, id)
+		return err
+	})
+
+	return post, author, err
+}
+
+// List retrieves a page of posts.
+func (r *interceptedPostRepository) List(
+	ctx context.Context,
+	params *entity.ListParams,
+) (posts []*entity.Post, nextPageToken string, err error) {
+	err = r.intercept(ctx, call{method: "PostRepository.List", readOnly: true}, func(ctx context.Context) error {
+		posts, nextPageToken, err = r.next.List(ctx, params)
+		return err
+	})
+
+	return posts, nextPageToken, err
+}
+
+// Search retrieves posts matching query.
+func (r *interceptedPostRepository) Search(ctx context.Context, query string, limit int) (posts []*entity.Post, err error) {
+	err = r.intercept(ctx, call{method: "PostRepository.Search", readOnly: true}, func(ctx context.Context) error {
+		posts, err = r.next.Search(ctx, query, limit)
+		return err
+	})
+
+	return posts, err
+}
+
+// Delete removes a post.
+func (r *interceptedPostRepository) Delete(ctx context.Context, id string) error {
+	return r.intercept(ctx, call{method: "PostRepository.Delete"}, func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+// DeleteByOwner removes a post of the given owner.
+func (r *interceptedPostRepository) DeleteByOwner(ctx context.Context, postID, userID string) error {
+	return r.intercept(ctx, call{method: "PostRepository.DeleteByOwner"}, func(ctx context.Context) error {
+		return r.next.DeleteByOwner(ctx, postID, userID)
+	})
+}
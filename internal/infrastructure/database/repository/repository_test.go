@@ -0,0 +1,228 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/internal/infrastructure/database/repository"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+)
+
+// newMetrics returns a metrics decorator recording into the returned reader.
+func newMetrics(t *testing.T) (repository.Decorator, *metric.ManualReader) {
+	t.Helper()
+
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	decorator, err := repository.WithMetrics(provider.Meter("test"))
+	require.NoError(t, err)
+
+	return decorator, reader
+}
+
+// recordedCalls returns the number of calls recorded per method and code.
+func recordedCalls(t *testing.T, reader *metric.ManualReader) map[string]uint64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	calls := make(map[string]uint64)
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "app.repository.duration" {
+				continue
+			}
+
+			histogram, ok := m.Data.(metricdata.Histogram[float64])
+			require.True(t, ok)
+
+			for _, dp := range histogram.DataPoints {
+				method, _ := dp.Attributes.Value(attribute.Key("method"))
+				code, _ := dp.Attributes.Value(attribute.Key("code"))
+				calls[method.AsString()+" "+code.AsString()] += dp.Count
+			}
+		}
+	}
+
+	return calls
+}
+
+func TestDecorateUserRepository_MetricsAndRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), "tenant-1")
+	user := &entity.User{ID: "user-1", Name: "Alice"}
+	connReset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+	tests := []struct {
+		name      string
+		setupMock func(repo *entity.MockUserRepository)
+		call      func(repo entity.UserRepository) error
+		wantErr   bool
+		wantCalls map[string]uint64
+	}{
+		{
+			name: "retry transient read failure and record a single call",
+			setupMock: func(repo *entity.MockUserRepository) {
+				repo.EXPECT().Get(ctx, "user-1").Return(nil, fmt.Errorf("failed to get user: %w", connReset)).Once()
+				repo.EXPECT().Get(ctx, "user-1").Return(user, nil).Once()
+			},
+			call: func(repo entity.UserRepository) error {
+				_, err := repo.Get(ctx, "user-1")
+				return err
+			},
+			wantCalls: map[string]uint64{"UserRepository.Get ok": 1},
+		},
+		{
+			name: "record failure after exhausting retries",
+			setupMock: func(repo *entity.MockUserRepository) {
+				repo.EXPECT().Get(ctx, "user-1").Return(nil, driver.ErrBadConn).Times(3)
+			},
+			call: func(repo entity.UserRepository) error {
+				_, err := repo.Get(ctx, "user-1")
+				return err
+			},
+			wantErr:   true,
+			wantCalls: map[string]uint64{"UserRepository.Get unknown": 1},
+		},
+		{
+			name: "not retry open circuit",
+			setupMock: func(repo *entity.MockUserRepository) {
+				repo.EXPECT().Get(ctx, "user-1").
+					Return(nil, apperr.New(codes.Unavailable, "database circuit open")).Once()
+			},
+			call: func(repo entity.UserRepository) error {
+				_, err := repo.Get(ctx, "user-1")
+				return err
+			},
+			wantErr:   true,
+			wantCalls: map[string]uint64{"UserRepository.Get unavailable": 1},
+		},
+		{
+			name: "not retry query error",
+			setupMock: func(repo *entity.MockUserRepository) {
+				repo.EXPECT().Get(ctx, "user-1").Return(nil, errors.New("syntax error")).Once()
+			},
+			call: func(repo entity.UserRepository) error {
+				_, err := repo.Get(ctx, "user-1")
+				return err
+			},
+			wantErr:   true,
+			wantCalls: map[string]uint64{"UserRepository.Get unknown": 1},
+		},
+		{
+			name: "not retry client error",
+			setupMock: func(repo *entity.MockUserRepository) {
+				repo.EXPECT().Get(ctx, "user-1").
+					Return(nil, apperr.New(codes.NotFound, "user not found")).Once()
+			},
+			call: func(repo entity.UserRepository) error {
+				_, err := repo.Get(ctx, "user-1")
+				return err
+			},
+			wantErr:   true,
+			wantCalls: map[string]uint64{"UserRepository.Get not_found": 1},
+		},
+		{
+			name: "not retry write",
+			setupMock: func(repo *entity.MockUserRepository) {
+				repo.EXPECT().Delete(ctx, "user-1").Return(connReset).Once()
+			},
+			call: func(repo entity.UserRepository) error {
+				return repo.Delete(ctx, "user-1")
+			},
+			wantErr:   true,
+			wantCalls: map[string]uint64{"UserRepository.Delete unknown": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := entity.NewMockUserRepository(t)
+			tt.setupMock(mockRepo)
+
+			metrics, reader := newMetrics(t)
+			repo := repository.DecorateUserRepository(mockRepo, metrics, repository.WithRetry(3, time.Millisecond))
+
+			err := tt.call(repo)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, recordedCalls(t, reader))
+		})
+	}
+}
+
+func TestDecoratePostRepository_MetricsAndCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := tenant.NewContext(context.Background(), "tenant-1")
+	post := &entity.Post{ID: "post-1", UserID: "user-1", Title: "Hello"}
+
+	mockRepo := entity.NewMockPostRepository(t)
+	mockRepo.EXPECT().Get(ctx, "post-1").Return(post, nil).Once()
+
+	metrics, reader := newMetrics(t)
+	repo := repository.DecoratePostRepository(mockRepo, metrics, repository.WithCache(time.Minute))
+
+	// The second call is served from the cache, but both are measured
+	for range 2 {
+		got, err := repo.Get(ctx, "post-1")
+		require.NoError(t, err)
+		assert.Equal(t, post, got)
+	}
+
+	// Modifying a returned post leaves the cached one intact
+	got, err := repo.Get(ctx, "post-1")
+	require.NoError(t, err)
+	got.Title = "Modified"
+
+	got, err = repo.Get(ctx, "post-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", got.Title)
+
+	// Other tenants do not share the cached post
+	otherCtx := tenant.NewContext(context.Background(), "tenant-2")
+	mockRepo.EXPECT().Get(otherCtx, "post-1").Return(nil, apperr.New(codes.NotFound, "post not found")).Once()
+
+	_, err = repo.Get(otherCtx, "post-1")
+	require.Error(t, err)
+
+	// Deleting evicts the cached post
+	mockRepo.EXPECT().Delete(ctx, "post-1").Return(nil).Once()
+	mockRepo.EXPECT().Get(ctx, "post-1").Return(nil, apperr.New(codes.NotFound, "post not found")).Once()
+
+	require.NoError(t, repo.Delete(ctx, "post-1"))
+
+	_, err = repo.Get(ctx, "post-1")
+	require.Error(t, err)
+
+	assert.Equal(t, map[string]uint64{
+		"PostRepository.Get ok":        4,
+		"PostRepository.Get not_found": 2,
+		"PostRepository.Delete ok":     1,
+	}, recordedCalls(t, reader))
+}
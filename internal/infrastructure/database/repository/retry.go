@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+)
+
+// WithRetry returns a Decorator retrying read-only repository calls that fail on a broken database
+// connection, up to maxAttempts calls in total. The wait before each retry starts at backoff and doubles.
+// Writes are never retried, since a write that failed after reaching the database may still
+// have been applied. A maxAttempts of 1 or less disables retrying.
+func WithRetry(maxAttempts int, backoff time.Duration) Decorator {
+	return intercept(func(ctx context.Context, c call, fn func(ctx context.Context) error) error {
+		err := fn(ctx)
+
+		for attempt := 1; c.readOnly && attempt < maxAttempts && isTransient(err); attempt++ {
+			if waitErr := wait(ctx, backoff<<(attempt-1)); waitErr != nil {
+				return err
+			}
+
+			err = fn(ctx)
+		}
+
+		return err
+	})
+}
+
+// isTransient reports whether err is a broken database connection, which may not recur when the call
+// is repeated on another connection. Any other error, including codes.Unavailable of an open circuit
+// breaker, is returned as is, since retrying would only add load to a database that is already failing.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var appErr *apperr.AppErr
+	if errors.As(err, &appErr) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// wait blocks for d or until ctx is done, in which case it returns the error of ctx.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
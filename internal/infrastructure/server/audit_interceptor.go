@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// AuditEntry describes a call to a mutating procedure.
+type AuditEntry struct {
+	Procedure string
+	Peer      string
+	Time      time.Time
+	Err       error // nil if the call succeeded
+}
+
+// AuditSink records audit entries.
+type AuditSink interface {
+	Record(ctx context.Context, entry AuditEntry)
+}
+
+// AuditSinkFunc is an adapter to allow the use of ordinary functions as audit sinks.
+type AuditSinkFunc func(ctx context.Context, entry AuditEntry)
+
+// Record calls f(ctx, entry).
+func (f AuditSinkFunc) Record(ctx context.Context, entry AuditEntry) {
+	f(ctx, entry)
+}
+
+// NewLogAuditSink creates an audit sink that writes entries to the logger.
+func NewLogAuditSink(logger *logging.Logger) AuditSink {
+	return AuditSinkFunc(func(ctx context.Context, entry AuditEntry) {
+		attrs := []slog.Attr{
+			attr.Procedure(entry.Procedure),
+			attr.RemoteAddr(entry.Peer),
+		}
+
+		if entry.Err != nil {
+			attrs = append(attrs, slog.String(attr.Error, entry.Err.Error()))
+		}
+
+		logger.Info(ctx, "Audit log", attrs...)
+	})
+}
+
+// NewAuditLogInterceptor creates a Connect interceptor that records calls to mutating procedures in sink.
+// It is meant to be applied to specific handlers with WithHandlerInterceptors rather than globally.
+func NewAuditLogInterceptor(sink AuditSink) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			resp, err := next(ctx, req)
+
+			if isMutating(req.Spec()) {
+				sink.Record(ctx, AuditEntry{
+					Procedure: req.Spec().Procedure,
+					Peer:      req.Peer().Addr,
+					Time:      time.Now(),
+					Err:       err,
+				})
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// isMutating reports whether the procedure may modify state.
+// Procedures declared free of side effects, and Get and List methods by naming convention, are read-only.
+func isMutating(spec connect.Spec) bool {
+	if spec.IdempotencyLevel == connect.IdempotencyNoSideEffects {
+		return false
+	}
+
+	method := spec.Procedure[strings.LastIndex(spec.Procedure, "/")+1:]
+
+	return !strings.HasPrefix(method, "Get") && !strings.HasPrefix(method, "List")
+}
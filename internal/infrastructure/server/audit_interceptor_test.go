@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+const (
+	adminServicePath      = "/test.v1.AdminService/"
+	adminDeleteProcedure  = "/test.v1.AdminService/DeleteThing"
+	adminGetProcedure     = "/test.v1.AdminService/GetThing"
+	publicDeleteProcedure = "/test.v1.PublicService/DeleteThing"
+)
+
+// recordingSink collects audit entries for assertions.
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (s *recordingSink) Record(_ context.Context, entry AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+}
+
+func (s *recordingSink) procedures() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	procedures := make([]string, 0, len(s.entries))
+	for _, entry := range s.entries {
+		procedures = append(procedures, entry.Procedure)
+	}
+
+	return procedures
+}
+
+func TestAuditLogInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		procedure      string
+		wantProcedures []string
+	}{
+		{
+			name:           "record mutating procedures of the designated handler",
+			procedure:      adminDeleteProcedure,
+			wantProcedures: []string{adminDeleteProcedure},
+		},
+		{
+			name:           "skip read-only procedures of the designated handler",
+			procedure:      adminGetProcedure,
+			wantProcedures: []string{},
+		},
+		{
+			name:           "skip procedures of other handlers",
+			procedure:      publicDeleteProcedure,
+			wantProcedures: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sink := &recordingSink{}
+			cfg := &config.Config{}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			handler := func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				return connect.NewResponse(&emptypb.Empty{}), nil
+			}
+
+			adminHandler := func(opts ...connect.HandlerOption) (string, http.Handler) {
+				mux := http.NewServeMux()
+				mux.Handle(adminDeleteProcedure, connect.NewUnaryHandler(adminDeleteProcedure, handler, opts...))
+				mux.Handle(adminGetProcedure, connect.NewUnaryHandler(adminGetProcedure, handler, opts...))
+
+				return adminServicePath, mux
+			}
+
+			publicHandler := func(opts ...connect.HandlerOption) (string, http.Handler) {
+				return publicDeleteProcedure, connect.NewUnaryHandler(publicDeleteProcedure, handler, opts...)
+			}
+
+			s := NewConnectServer(cfg, logger, nil,
+				WithHandlerInterceptors(adminHandler, NewAuditLogInterceptor(sink)),
+				publicHandler,
+			)
+
+			srv := httptest.NewServer(s.server.Handler)
+			t.Cleanup(srv.Close)
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+tt.procedure)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantProcedures, sink.procedures())
+		})
+	}
+}
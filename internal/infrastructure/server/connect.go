@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"log/slog"
 
@@ -15,6 +16,8 @@ import (
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/config"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"go.opentelemetry.io/otel"
 )
 
 // ConnectServer represents the Connect server.
@@ -28,6 +31,15 @@ type ConnectServer struct {
 // RPCHandlerFunc is a function that returns a path and a handler for a Connect RPC service.
 type RPCHandlerFunc func(opts ...connect.HandlerOption) (string, http.Handler)
 
+// WithHandlerInterceptors returns an RPCHandlerFunc that applies the given interceptors to the handler
+// in addition to the global ones. Connect composes them after the global interceptors, so they run
+// closer to the handler and only for procedures of this handler.
+func WithHandlerInterceptors(handlerFunc RPCHandlerFunc, interceptors ...connect.Interceptor) RPCHandlerFunc {
+	return func(opts ...connect.HandlerOption) (string, http.Handler) {
+		return handlerFunc(append(opts, connect.WithInterceptors(interceptors...))...)
+	}
+}
+
 // NewConnectServer creates a new Connect server instance.
 func NewConnectServer(
 	cfg *config.Config,
@@ -37,31 +49,52 @@ func NewConnectServer(
 ) *ConnectServer {
 	mux := http.NewServeMux()
 
-	// Create interceptors
-	tracingInterceptor, _ := otelconnect.NewInterceptor()
-	accessLogInterceptor := logging.NewAccessLogInterceptor(logger)
-	errorInterceptor := apperr.NewInterceptor(logger)
+	interceptors := newInterceptors(cfg, logger)
 
 	for _, handlerFunc := range handlerFuncs {
 		path, handler := handlerFunc(
 			newRecoverHandler(logger),
-			connect.WithInterceptors(
-				tracingInterceptor,
-				accessLogInterceptor,
-				errorInterceptor,
-			),
+			connect.WithInterceptors(interceptors...),
 		)
 		mux.Handle(path, handler)
 	}
 
+	// Routes other than Connect ones are not covered by the timeout interceptor, so they are bounded here
+	timeout := cfg.Server.HandlerTimeout
+
+	// Debug endpoints expose internals, so they are only served in development.
+	// Profiles and traces must be requested with ?seconds= below the handler timeout.
+	if cfg.IsDevelopment() {
+		mux.Handle(dbStatsPath, withHandlerTimeout(newDBStatsHandler(db), timeout))
+
+		pprofMux := http.NewServeMux()
+		RegisterPprof(pprofMux)
+		mux.Handle(pprofPath, withHandlerTimeout(pprofMux, timeout))
+	}
+
+	mux.Handle(versionPath, withHandlerTimeout(newVersionHandler(cfg), timeout))
+
+	// Unknown routes get a structured not_found error instead of the plain text default
+	mux.Handle(notFoundPath, withHandlerTimeout(newNotFoundHandler(logger), timeout))
+
 	address := net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port))
 
 	server := &http.Server{
 		Addr:              address,
-		Handler:           http.TimeoutHandler(mux, cfg.Server.HandlerTimeout, ""),
+		Handler:           mux,
 		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
 		ReadTimeout:       cfg.Server.ReadTimeout,
 		IdleTimeout:       cfg.Server.IdleTimeout,
+		// Zero keeps the net/http default; larger headers are rejected with 431 before reaching handlers
+		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
+		Protocols:      newProtocols(),
+	}
+
+	// Bound the streams a single HTTP/2 client can open, so one connection cannot exhaust the server under load
+	if cfg.Server.MaxConcurrentStreams > 0 {
+		server.HTTP2 = &http.HTTP2Config{
+			MaxConcurrentStreams: int(cfg.Server.MaxConcurrentStreams),
+		}
 	}
 
 	return &ConnectServer{
@@ -72,6 +105,17 @@ func NewConnectServer(
 	}
 }
 
+// newProtocols returns the protocols the server accepts: HTTP/1.1 and HTTP/2 without TLS (h2c).
+// The server listens without TLS, e.g. behind a TLS-terminating load balancer, so without h2c
+// HTTP/2 is never negotiated, gRPC clients cannot connect, and MaxConcurrentStreams has no effect.
+func newProtocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return protocols
+}
+
 // Start starts the Connect server.
 func (s *ConnectServer) Start() error {
 	s.logger.Info(context.Background(), fmt.Sprintf("Connect Server starting on %s", s.address))
@@ -79,15 +123,20 @@ func (s *ConnectServer) Start() error {
 	return s.server.ListenAndServe()
 }
 
-// Stop gracefully stops the Connect server.
-func (s *ConnectServer) Stop() error {
+// Stop gracefully stops the Connect server, waiting for in-flight requests until ctx is done.
+// If ctx has no deadline, the shutdown is bounded by cfg.ShutdownTimeout.
+func (s *ConnectServer) Stop(ctx context.Context) error {
 	if s.server != nil {
-		timeout := s.Cfg.ShutdownTimeout
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, s.Cfg.ShutdownTimeout)
+			defer cancel()
+		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
+		deadline, _ := ctx.Deadline()
 
-		s.logger.Info(ctx, "Shutting down Connect server gracefully...", slog.Duration("timeout", timeout))
+		s.logger.Info(ctx, "Shutting down Connect server gracefully...", slog.Duration("timeout", time.Until(deadline)))
 
 		return s.server.Shutdown(ctx)
 	}
@@ -95,10 +144,97 @@ func (s *ConnectServer) Stop() error {
 	return nil
 }
 
+// newInterceptors returns the interceptors applied to every handler.
+//
+// Connect wraps interceptors so that the first one is the outermost, which makes the order significant:
+//  1. Tracing runs outermost so the span covers the whole request, including logging.
+//  2. Trusted proxy filtering runs before access logging so that remote_addr is only taken from
+//     forwarding headers of trusted proxies.
+//  3. Access logging runs outside the error interceptor so it reports the final Connect code.
+//  4. Server timing, if enabled, runs inside access logging so it reports the same duration,
+//     and outside the error interceptor so it can set the header on converted Connect errors.
+//  5. Error handling runs inside access logging so it sees the AppErr returned by the handler before conversion.
+//  6. Load shedding runs inside error handling so its Unavailable AppErr is converted, and outside the
+//     deadline budget check and handler timeout so it counts the errors they return.
+//  7. The deadline budget check runs inside error handling so its DeadlineExceeded AppErr is converted,
+//     and before the handler timeout so it sees the remaining time of the client deadline.
+//  8. The handler timeout runs innermost so its DeadlineExceeded AppErr is converted by the error interceptor.
+//
+// Do not reorder without updating TestInterceptorOrdering.
+//
+// Tracing is not essential to serve requests, so if the tracing interceptor cannot be created,
+// a warning is logged and the server runs without it rather than failing to start.
+func newInterceptors(cfg *config.Config, logger *logging.Logger) []connect.Interceptor {
+	interceptors := make([]connect.Interceptor, 0, 8)
+
+	tracingInterceptor, err := newTracingInterceptor()
+	if err != nil {
+		logger.Warn(context.Background(), "Failed to create tracing interceptor, requests will not be traced",
+			slog.String(attr.Error, err.Error()),
+		)
+	} else {
+		interceptors = append(interceptors, tracingInterceptor)
+	}
+
+	// Without trusted proxies, forwarding headers are honored from no peer. Load rejects invalid
+	// trusted proxies, so an error here comes from a configuration built by hand, and trusts no proxy.
+	trusted, err := cfg.Server.TrustedProxyPrefixes()
+	if err != nil {
+		logger.Error(context.Background(), "Invalid trusted proxies, forwarding headers will not be honored from any peer", err)
+	}
+
+	interceptors = append(interceptors, newTrustedProxyInterceptor(trusted, logger))
+
+	interceptors = append(interceptors,
+		logging.NewAccessLogInterceptor(logger, logging.WithSlowThreshold(cfg.Server.SlowRequestThreshold)),
+	)
+
+	if cfg.Server.TimingHeader {
+		interceptors = append(interceptors, newServerTimingInterceptor())
+	}
+
+	return append(interceptors,
+		apperr.NewInterceptor(logger, errorInterceptorOptions(cfg)...),
+		newLoadSheddingInterceptor(
+			cfg.Server.LoadSheddingErrorRate, cfg.Server.LoadSheddingRatio, cfg.Server.LoadSheddingWindow, logger,
+		),
+		newDeadlineBudgetInterceptor(cfg.Server.MinDeadlineBudget),
+		newTimeoutInterceptor(cfg.Server.HandlerTimeout),
+	)
+}
+
+// errorMeterName is the name of the meter counting the errors returned by RPCs.
+const errorMeterName = "github.com/pannpers/go-backend-scaffold/pkg/apperr"
+
+// errorInterceptorOptions returns the error interceptor options for the server configuration.
+// Errors are counted with the global meter provider, which records nothing until telemetry is set up.
+func errorInterceptorOptions(cfg *config.Config) []apperr.InterceptorOption {
+	opts := []apperr.InterceptorOption{
+		apperr.WithErrorMetrics(otel.GetMeterProvider().Meter(errorMeterName)),
+	}
+
+	if cfg.Server.ErrorReferences {
+		opts = append(opts, apperr.WithErrorReferences())
+	}
+
+	return opts
+}
+
+// newTracingInterceptor creates the tracing interceptor.
+// It is a variable so tests can simulate a failure.
+var newTracingInterceptor = func() (connect.Interceptor, error) {
+	interceptor, err := otelconnect.NewInterceptor()
+	if err != nil {
+		return nil, err
+	}
+
+	return interceptor, nil
+}
+
 func newRecoverHandler(logger *logging.Logger) connect.HandlerOption {
 	return connect.WithRecover(func(ctx context.Context, spec connect.Spec, header http.Header, p any) error {
 		logger.Error(ctx, "Panic recovered in Connect handler", fmt.Errorf("panic: %v", p),
-			slog.String("procedure", spec.Procedure),
+			attr.Procedure(spec.Procedure),
 		)
 
 		return connect.NewError(connect.CodeInternal, fmt.Errorf("internal server error"))
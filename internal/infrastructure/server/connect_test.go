@@ -0,0 +1,453 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+const testProcedure = "/test.v1.TestService/Call"
+
+// newTestServer serves a single unary procedure backed by handler with the production interceptor chain.
+func newTestServer(
+	t *testing.T,
+	cfg *config.Config,
+	logger *logging.Logger,
+	handler func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error),
+	opts ...connect.HandlerOption,
+) *connect.Client[emptypb.Empty, emptypb.Empty] {
+	t.Helper()
+
+	opts = append([]connect.HandlerOption{
+		newRecoverHandler(logger),
+		connect.WithInterceptors(newInterceptors(cfg, logger)...),
+	}, opts...)
+
+	mux := http.NewServeMux()
+	mux.Handle(testProcedure, connect.NewUnaryHandler(testProcedure, handler, opts...))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+testProcedure)
+}
+
+func TestInterceptorOrdering(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		handlerErr error
+		wantCode   connect.Code
+		wantStatus string
+	}{
+		{
+			name:       "access log reports the code converted from AppErr",
+			handlerErr: apperr.New(codes.Internal, "database error"),
+			wantCode:   connect.CodeInternal,
+			wantStatus: `"status":"internal"`,
+		},
+		{
+			name:       "access log reports client error codes",
+			handlerErr: apperr.New(codes.NotFound, "user not found"),
+			wantCode:   connect.CodeNotFound,
+			wantStatus: `"status":"not_found"`,
+		},
+		{
+			name:       "access log reports canceled context error",
+			handlerErr: context.Canceled,
+			wantCode:   connect.CodeCanceled,
+			wantStatus: `"status":"canceled"`,
+		},
+		{
+			name:       "access log reports wrapped deadline exceeded context error",
+			handlerErr: fmt.Errorf("failed to get user: %w", context.DeadlineExceeded),
+			wantCode:   connect.CodeDeadlineExceeded,
+			wantStatus: `"status":"deadline_exceeded"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logBuffer := &bytes.Buffer{}
+			logger := logging.New(
+				logging.WithWriter(logBuffer),
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithLevel(slog.LevelDebug),
+			)
+
+			client := newTestServer(t, &config.Config{}, logger,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					return nil, tt.handlerErr
+				},
+			)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.Error(t, err)
+
+			var connectErr *connect.Error
+			require.True(t, errors.As(err, &connectErr))
+			assert.Equal(t, tt.wantCode, connectErr.Code())
+
+			assert.Contains(t, logBuffer.String(), `"msg":"Access log"`)
+			assert.Contains(t, logBuffer.String(), tt.wantStatus)
+		})
+	}
+}
+
+func TestConnectServer_Stop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "return promptly when the context is already canceled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "use the context deadline instead of the shutdown timeout",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{ShutdownTimeout: time.Minute}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			// Keep a request in flight so that the shutdown has to wait for it
+			slowHandler := func(_ ...connect.HandlerOption) (string, http.Handler) {
+				return "/slow", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					close(started)
+					<-release
+				})
+			}
+
+			s := NewConnectServer(cfg, logger, nil, slowHandler)
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			go func() { _ = s.server.Serve(ln) }()
+
+			go func() {
+				resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+			}()
+
+			<-started
+			t.Cleanup(func() { close(release) })
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			start := time.Now()
+			err = s.Stop(ctx)
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+// TestNewConnectServer_TracingInterceptorError is not parallel because it replaces newTracingInterceptor.
+func TestNewConnectServer_TracingInterceptorError(t *testing.T) {
+	original := newTracingInterceptor
+	newTracingInterceptor = func() (connect.Interceptor, error) {
+		return nil, errors.New("tracing unavailable")
+	}
+
+	t.Cleanup(func() {
+		newTracingInterceptor = original
+	})
+
+	logBuffer := &bytes.Buffer{}
+	logger := logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON))
+
+	assert.Len(t, newInterceptors(&config.Config{}, logger), 6)
+	assert.Contains(t, logBuffer.String(), `"level":"WARN"`)
+	assert.Contains(t, logBuffer.String(), "tracing unavailable")
+
+	// The server still serves requests without tracing
+	client := newTestServer(t, &config.Config{}, logging.New(logging.WithWriter(io.Discard)),
+		func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			return connect.NewResponse(&emptypb.Empty{}), nil
+		},
+	)
+
+	_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+
+	assert.NotNil(t, NewConnectServer(&config.Config{}, logger, nil))
+}
+
+func TestNewConnectServer_MaxConcurrentStreams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		maxConcurrentStreams uint32
+		want                 *http.HTTP2Config
+	}{
+		{
+			name:                 "apply configured limit to HTTP/2",
+			maxConcurrentStreams: 100,
+			want:                 &http.HTTP2Config{MaxConcurrentStreams: 100},
+		},
+		{
+			name:                 "keep net/http default when unset",
+			maxConcurrentStreams: 0,
+			want:                 nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{
+				Server: config.ServerConfig{MaxConcurrentStreams: tt.maxConcurrentStreams},
+			}
+
+			s := NewConnectServer(cfg, logging.New(logging.WithWriter(io.Discard)), nil)
+
+			assert.Equal(t, tt.want, s.server.HTTP2)
+		})
+	}
+}
+
+func TestConnectServer_MaxConcurrentStreams_H2C(t *testing.T) {
+	t.Parallel()
+
+	const (
+		maxStreams = 2
+		requests   = 6
+	)
+
+	var (
+		mu       sync.Mutex
+		inFlight = make(map[string]int) // concurrent requests by client connection
+		peak     int                    // most concurrent requests on a single connection
+		protos   []string
+	)
+
+	slow := func(...connect.HandlerOption) (string, http.Handler) {
+		return "/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight[r.RemoteAddr]++
+			peak = max(peak, inFlight[r.RemoteAddr])
+			protos = append(protos, r.Proto)
+			mu.Unlock()
+
+			time.Sleep(100 * time.Millisecond)
+
+			mu.Lock()
+			inFlight[r.RemoteAddr]--
+			mu.Unlock()
+		})
+	}
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{MaxConcurrentStreams: maxStreams},
+	}
+
+	s := NewConnectServer(cfg, logging.New(logging.WithWriter(io.Discard)), nil, slow)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = s.server.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = s.server.Close()
+	})
+
+	// An HTTP/2 client without TLS, which multiplexes requests on a connection up to the stream limit
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	var wg sync.WaitGroup
+
+	for range requests {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Get("http://" + ln.Addr().String() + "/slow")
+			if assert.NoError(t, err) {
+				_ = resp.Body.Close()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, maxStreams, peak, "expected the stream limit to bound concurrent requests per connection")
+	assert.Len(t, protos, requests)
+	for _, proto := range protos {
+		assert.Equal(t, "HTTP/2.0", proto)
+	}
+}
+
+func TestConnectServer_MaxHeaderBytes(t *testing.T) {
+	t.Parallel()
+
+	const maxHeaderBytes = 1 << 10
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{MaxHeaderBytes: maxHeaderBytes},
+	}
+
+	s := NewConnectServer(cfg, logging.New(logging.WithWriter(io.Discard)), nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = s.server.Serve(listener) }()
+	t.Cleanup(func() { _ = s.server.Close() })
+
+	tests := []struct {
+		name       string
+		headerSize int
+		wantStatus int
+	}{
+		{
+			name:       "serve request with headers within the limit",
+			headerSize: maxHeaderBytes / 2,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			// net/http tolerates 4 KB beyond the limit, so exceed it well beyond that
+			name:       "reject request with oversized headers",
+			headerSize: maxHeaderBytes + 16<<10,
+			wantStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+listener.Addr().String()+"/unknown", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+strings.Repeat("x", tt.headerSize))
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+// TestConnectServer_RequestTooLarge guards that bodies truncated by http.MaxBytesHandler are reported
+// as resource_exhausted. Connect reads the request before running interceptors and maps
+// *http.MaxBytesError itself, so no interceptor is involved.
+func TestConnectServer_RequestTooLarge(t *testing.T) {
+	t.Parallel()
+
+	const maxBytes = 16
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{
+			name:        "JSON body",
+			contentType: "application/json",
+		},
+		{
+			name:        "binary body",
+			contentType: "application/proto",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			handler := connect.NewUnaryHandler(testProcedure,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				newRecoverHandler(logger),
+				connect.WithInterceptors(newInterceptors(&config.Config{}, logger)...),
+			)
+
+			srv := httptest.NewServer(http.MaxBytesHandler(handler, maxBytes))
+			t.Cleanup(srv.Close)
+
+			body := bytes.Repeat([]byte("x"), maxBytes*64)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+testProcedure, bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			resp, err := srv.Client().Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			var got struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+			assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+			assert.Equal(t, connect.CodeResourceExhausted.String(), got.Code)
+			assert.Contains(t, got.Message, "exceeded 16 byte http.MaxBytesReader limit")
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// newDeadlineBudgetInterceptor creates a Connect interceptor that rejects requests whose deadline
+// leaves less than minRemaining, returning a DeadlineExceeded AppErr without calling the handler.
+// In a chain of service calls, the client would give up before the handler could respond anyway,
+// so starting the work would only waste resources.
+// Requests without a deadline are always handled. A non-positive minRemaining disables the interceptor.
+func newDeadlineBudgetInterceptor(minRemaining time.Duration) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		if minRemaining <= 0 {
+			return next
+		}
+
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return next(ctx, req)
+			}
+
+			if remaining := time.Until(deadline); remaining < minRemaining {
+				return nil, apperr.New(codes.DeadlineExceeded, "insufficient time remaining before deadline",
+					attr.Procedure(req.Spec().Procedure),
+					slog.Duration("remaining", remaining),
+					slog.Duration("min_remaining", minRemaining),
+				)
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
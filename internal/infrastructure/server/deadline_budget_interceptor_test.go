@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestDeadlineBudgetInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		minRemaining  time.Duration
+		clientTimeout time.Duration // zero for no deadline
+		wantCalled    bool
+		wantCode      connect.Code // zero if no error is expected
+	}{
+		{
+			name:          "reject request with nearly expired deadline",
+			minRemaining:  time.Second,
+			clientTimeout: 100 * time.Millisecond,
+			wantCalled:    false,
+			wantCode:      connect.CodeDeadlineExceeded,
+		},
+		{
+			name:          "handle request with enough time remaining",
+			minRemaining:  50 * time.Millisecond,
+			clientTimeout: 5 * time.Second,
+			wantCalled:    true,
+		},
+		{
+			name:         "handle request without deadline",
+			minRemaining: time.Second,
+			wantCalled:   true,
+		},
+		{
+			name:          "disable check when minimum is not positive",
+			minRemaining:  0,
+			clientTimeout: 100 * time.Millisecond,
+			wantCalled:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{
+				Server: config.ServerConfig{MinDeadlineBudget: tt.minRemaining},
+			}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			var called atomic.Bool
+
+			client := newTestServer(t, cfg, logger,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					called.Store(true)
+
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+			)
+
+			// The client sends its deadline in the Connect-Timeout-Ms header
+			ctx := context.Background()
+			if tt.clientTimeout > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tt.clientTimeout)
+				defer cancel()
+			}
+
+			_, err := client.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+
+			assert.Equal(t, tt.wantCalled, called.Load())
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var connectErr *connect.Error
+			require.True(t, errors.As(err, &connectErr))
+			assert.Equal(t, tt.wantCode, connectErr.Code())
+			assert.Contains(t, connectErr.Message(), "insufficient time remaining")
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+)
+
+// dbStatsPath is the path of the database pool statistics endpoint.
+const dbStatsPath = "/debug/dbstats"
+
+// pprofPath is the path prefix of the runtime profiling endpoints.
+const pprofPath = "/debug/pprof/"
+
+// RegisterPprof mounts the net/http/pprof handlers under /debug/pprof/ on mux.
+// Profiles expose internals and can be expensive to collect, so only register them on
+// servers that are not publicly reachable, such as in development.
+func RegisterPprof(mux *http.ServeMux) {
+	mux.HandleFunc(pprofPath, pprof.Index)
+	mux.HandleFunc(pprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(pprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(pprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(pprofPath+"trace", pprof.Trace)
+}
+
+// dbStatsProvider provides connection pool statistics.
+type dbStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// newDBStatsHandler creates an HTTP handler that returns the connection pool statistics as JSON.
+func newDBStatsHandler(db dbStatsProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(db.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
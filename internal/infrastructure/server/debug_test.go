@@ -0,0 +1,122 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+type fakeStatsProvider struct {
+	stats sql.DBStats
+}
+
+func (f *fakeStatsProvider) Stats() sql.DBStats {
+	return f.stats
+}
+
+func TestDBStatsHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		stats      sql.DBStats
+		wantStatus int
+		wantBody   map[string]any
+	}{
+		{
+			name:   "return pool statistics as JSON",
+			method: http.MethodGet,
+			stats: sql.DBStats{
+				MaxOpenConnections: 10,
+				OpenConnections:    3,
+				InUse:              2,
+				Idle:               1,
+			},
+			wantStatus: http.StatusOK,
+			wantBody: map[string]any{
+				"MaxOpenConnections": float64(10),
+				"OpenConnections":    float64(3),
+				"InUse":              float64(2),
+				"Idle":               float64(1),
+			},
+		},
+		{
+			name:       "reject non-GET requests",
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newDBStatsHandler(&fakeStatsProvider{stats: tt.stats})
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, dbStatsPath, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantBody == nil {
+				return
+			}
+
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+
+			for key, want := range tt.wantBody {
+				assert.Equal(t, want, got[key], "unexpected value for %s", key)
+			}
+		})
+	}
+}
+
+func TestRegisterPprof(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		environment string
+		wantStatus  int
+	}{
+		{
+			name:        "serve pprof in development",
+			environment: "development",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "hide pprof in production",
+			environment: "production",
+			wantStatus:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{Environment: tt.environment}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			s := NewConnectServer(cfg, logger, nil)
+
+			rec := httptest.NewRecorder()
+			s.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, pprofPath, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
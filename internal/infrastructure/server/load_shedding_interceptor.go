@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+const (
+	// loadSheddingBuckets is the number of buckets the rolling window is divided into.
+	// The oldest bucket expires at once, so more buckets make the rate smoother.
+	loadSheddingBuckets = 10
+
+	// loadSheddingMinRequests is the number of requests in the window below which load is never shed,
+	// so that a few failures at low traffic do not trigger shedding.
+	loadSheddingMinRequests = 20
+)
+
+// loadShedder tracks the rate of server errors over a rolling window and decides which requests
+// to reject while it is above the threshold.
+type loadShedder struct {
+	threshold float64
+	ratio     float64
+	bucketLen time.Duration
+	clock     clock.Clock
+	random    func() float64 // returns a number in [0, 1)
+	logger    *logging.Logger
+
+	mu       sync.Mutex
+	buckets  [loadSheddingBuckets]errorBucket
+	shedding bool // whether the rate was above the threshold at the last check, to log transitions
+}
+
+// errorBucket counts the requests handled in the slice of the window starting at start.
+type errorBucket struct {
+	start    time.Time
+	requests int
+	errors   int
+}
+
+// newLoadShedder creates a load shedder rejecting a fraction ratio of requests while the server
+// error rate over window exceeds threshold.
+func newLoadShedder(threshold, ratio float64, window time.Duration, c clock.Clock, logger *logging.Logger) *loadShedder {
+	return &loadShedder{
+		threshold: threshold,
+		ratio:     ratio,
+		bucketLen: max(window/loadSheddingBuckets, time.Millisecond),
+		clock:     c,
+		random:    rand.Float64,
+		logger:    logger,
+	}
+}
+
+var _ connect.Interceptor = (*loadShedder)(nil)
+
+// newLoadSheddingInterceptor creates a Connect interceptor that protects the database and other
+// dependencies of a failing server by rejecting a fraction ratio of new requests and streams with an
+// Unavailable error, without calling the handler, while the fraction of requests and streams failing
+// with server errors over the rolling window exceeds threshold. Rejected requests are not counted,
+// so the rate recovers as the handled requests succeed again.
+// A non-positive threshold disables the interceptor.
+func newLoadSheddingInterceptor(threshold, ratio float64, window time.Duration, logger *logging.Logger) *loadShedder {
+	if threshold <= 0 {
+		return &loadShedder{}
+	}
+
+	return newLoadShedder(threshold, ratio, window, clock.New(), logger)
+}
+
+// WrapUnary implements connect.Interceptor.
+func (s *loadShedder) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	if s.threshold <= 0 {
+		return next
+	}
+
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if s.shed(ctx) {
+			return nil, newLoadSheddingError(req.Spec())
+		}
+
+		resp, err := next(ctx, req)
+
+		s.record(isServerError(err))
+
+		return resp, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor. Client streams are not shed.
+func (s *loadShedder) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+// A stream is counted once, with the error its handler returns. Streams already open are not cut off.
+// The error interceptor does not wrap streams, so the error is returned as a connect.Error here.
+func (s *loadShedder) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	if s.threshold <= 0 {
+		return next
+	}
+
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if s.shed(ctx) {
+			return connect.NewError(connect.CodeUnavailable, newLoadSheddingError(conn.Spec()))
+		}
+
+		err := next(ctx, conn)
+
+		s.record(isServerError(err))
+
+		return err
+	}
+}
+
+func newLoadSheddingError(spec connect.Spec) error {
+	return apperr.New(codes.Unavailable, "server is shedding load", attr.Procedure(spec.Procedure))
+}
+
+// shed reports whether to reject a request, logging when shedding starts and stops.
+func (s *loadShedder) shed(ctx context.Context) bool {
+	s.mu.Lock()
+	requests, errs := s.counts(s.clock.Now())
+	rate := 0.0
+	if requests > 0 {
+		rate = float64(errs) / float64(requests)
+	}
+
+	shedding := requests >= loadSheddingMinRequests && rate > s.threshold
+	changed := shedding != s.shedding
+	s.shedding = shedding
+	s.mu.Unlock()
+
+	if changed && shedding {
+		s.logger.Warn(ctx, "Server error rate above threshold, shedding load",
+			slog.Float64("error_rate", rate),
+			slog.Float64("threshold", s.threshold),
+			slog.Float64("ratio", s.ratio),
+		)
+	} else if changed {
+		s.logger.Info(ctx, "Server error rate recovered, stopped shedding load", slog.Float64("error_rate", rate))
+	}
+
+	return shedding && s.random() < s.ratio
+}
+
+// record counts a handled request in the bucket of the current time.
+func (s *loadShedder) record(serverError bool) {
+	now := s.clock.Now()
+	start := now.Truncate(s.bucketLen)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.buckets[int(start.UnixNano()/int64(s.bucketLen))%loadSheddingBuckets]
+	if !b.start.Equal(start) {
+		*b = errorBucket{start: start}
+	}
+
+	b.requests++
+	if serverError {
+		b.errors++
+	}
+}
+
+// counts returns the requests and server errors counted in the window ending at now.
+// It must be called with s.mu held.
+func (s *loadShedder) counts(now time.Time) (requests, errs int) {
+	oldest := now.Truncate(s.bucketLen).Add(-s.bucketLen * (loadSheddingBuckets - 1))
+
+	for _, b := range s.buckets {
+		if !b.start.Before(oldest) {
+			requests += b.requests
+			errs += b.errors
+		}
+	}
+
+	return requests, errs
+}
+
+// isServerError reports whether err is returned to clients as a server error.
+// Connect errors returned by handlers, e.g. for invalid requests, keep their code, and context errors
+// returned as is are client errors, Canceled or DeadlineExceeded, so client disconnects and expired
+// client deadlines do not raise the error rate. Other errors than AppErr are returned as Unknown,
+// which is a server error.
+func isServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var appErr *apperr.AppErr
+	if errors.As(err, &appErr) {
+		return apperr.IsServerError(appErr.Code)
+	}
+
+	if code, ok := apperr.ContextErrorCode(err); ok {
+		return apperr.IsServerError(code)
+	}
+
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return apperr.IsServerError(connectErr.Code())
+	}
+
+	return true
+}
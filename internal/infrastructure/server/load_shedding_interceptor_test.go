@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestLoadSheddingInterceptor(t *testing.T) {
+	t.Parallel()
+
+	const window = 10 * time.Second
+
+	tests := []struct {
+		name       string
+		handlerErr error // returned by the requests preceding the checked one
+		requests   int
+		advance    time.Duration // clock advance before the checked request
+		random     float64
+		wantShed   bool
+	}{
+		{
+			name:       "shed request when server error rate exceeds threshold",
+			handlerErr: apperr.New(codes.Internal, "database error"),
+			requests:   loadSheddingMinRequests,
+			wantShed:   true,
+		},
+		{
+			name:       "count errors other than AppErr as server errors",
+			handlerErr: errors.New("unexpected error"),
+			requests:   loadSheddingMinRequests,
+			wantShed:   true,
+		},
+		{
+			name:       "handle the fraction of requests beyond the shedding ratio",
+			handlerErr: apperr.New(codes.Internal, "database error"),
+			requests:   loadSheddingMinRequests,
+			random:     0.7,
+			wantShed:   false,
+		},
+		{
+			name:       "do not shed below the minimum number of requests",
+			handlerErr: apperr.New(codes.Internal, "database error"),
+			requests:   loadSheddingMinRequests - 1,
+			wantShed:   false,
+		},
+		{
+			name:       "do not count client errors",
+			handlerErr: apperr.New(codes.NotFound, "user not found"),
+			requests:   loadSheddingMinRequests,
+			wantShed:   false,
+		},
+		{
+			name:       "do not count Connect client errors",
+			handlerErr: connect.NewError(connect.CodeInvalidArgument, errors.New("user_id is required")),
+			requests:   loadSheddingMinRequests,
+			wantShed:   false,
+		},
+		{
+			name:       "count Connect server errors",
+			handlerErr: connect.NewError(connect.CodeUnavailable, errors.New("upstream unavailable")),
+			requests:   loadSheddingMinRequests,
+			wantShed:   true,
+		},
+		{
+			name:       "do not count canceled requests",
+			handlerErr: context.Canceled,
+			requests:   loadSheddingMinRequests,
+			wantShed:   false,
+		},
+		{
+			name:       "do not count requests past the client deadline",
+			handlerErr: fmt.Errorf("failed to get user: %w", context.DeadlineExceeded),
+			requests:   loadSheddingMinRequests,
+			wantShed:   false,
+		},
+		{
+			name:     "do not shed while requests succeed",
+			requests: loadSheddingMinRequests,
+			wantShed: false,
+		},
+		{
+			name:       "stop shedding once errors leave the window",
+			handlerErr: apperr.New(codes.Internal, "database error"),
+			requests:   loadSheddingMinRequests,
+			advance:    window,
+			wantShed:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+			shedder := newLoadShedder(0.5, 0.5, window, fakeClock, logging.New(logging.WithWriter(io.Discard)))
+			shedder.random = func() float64 { return tt.random }
+
+			var handled int
+
+			next := shedder.WrapUnary(func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+				handled++
+
+				return nil, tt.handlerErr
+			})
+
+			req := connect.NewRequest(&emptypb.Empty{})
+
+			for range tt.requests {
+				_, _ = next(context.Background(), req)
+			}
+
+			fakeClock.Advance(tt.advance)
+			handled = 0
+
+			_, err := next(context.Background(), req)
+
+			if !tt.wantShed {
+				assert.Equal(t, 1, handled, "expected the request to be handled")
+
+				return
+			}
+
+			assert.Zero(t, handled, "expected the request to be shed without calling the handler")
+			require.Error(t, err)
+			assert.True(t, apperr.IsCode(err, codes.Unavailable), "got error %v, want code Unavailable", err)
+		})
+	}
+}
+
+func TestLoadSheddingInterceptor_Disabled(t *testing.T) {
+	t.Parallel()
+
+	interceptor := newLoadSheddingInterceptor(0, 1, time.Second, logging.New(logging.WithWriter(io.Discard)))
+
+	var handled int
+
+	next := interceptor.WrapUnary(func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+		handled++
+
+		return nil, apperr.New(codes.Internal, "database error")
+	})
+
+	for range 2 * loadSheddingMinRequests {
+		_, err := next(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+		require.True(t, apperr.IsCode(err, codes.Internal))
+	}
+
+	assert.Equal(t, 2*loadSheddingMinRequests, handled)
+}
+
+func TestLoadSheddingInterceptor_WrapStreamingHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		handlerErr error // returned by the streams preceding the checked one
+		wantShed   bool
+	}{
+		{
+			name:       "shed new stream when stream server error rate exceeds threshold",
+			handlerErr: connect.NewError(connect.CodeInternal, errors.New("database error")),
+			wantShed:   true,
+		},
+		{
+			name:       "do not count stream client errors",
+			handlerErr: connect.NewError(connect.CodePermissionDenied, errors.New("user agent not allowed")),
+			wantShed:   false,
+		},
+		{
+			name:     "do not shed while streams succeed",
+			wantShed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+			shedder := newLoadShedder(0.5, 0.5, 10*time.Second, fakeClock, logging.New(logging.WithWriter(io.Discard)))
+			shedder.random = func() float64 { return 0 }
+
+			var handled int
+
+			handler := shedder.WrapStreamingHandler(func(context.Context, connect.StreamingHandlerConn) error {
+				handled++
+
+				return tt.handlerErr
+			})
+
+			conn := &stubStreamingHandlerConn{spec: connect.Spec{Procedure: testProcedure}}
+
+			for range loadSheddingMinRequests {
+				_ = handler(context.Background(), conn)
+			}
+
+			handled = 0
+
+			err := handler(context.Background(), conn)
+
+			if !tt.wantShed {
+				assert.Equal(t, 1, handled, "expected the stream to be handled")
+
+				return
+			}
+
+			assert.Zero(t, handled, "expected the stream to be shed without calling the handler")
+			assert.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// notFoundPath is the catch-all pattern matching every path no other handler is registered for.
+const notFoundPath = "/"
+
+// notFoundBody is the JSON error body returned for unknown routes.
+// It follows the Connect error shape so clients can handle it like any other RPC error.
+type notFoundBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newNotFoundHandler creates an HTTP handler that logs unmatched paths and returns a Connect-style not_found error.
+func newNotFoundHandler(logger *logging.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Warn(r.Context(), "No handler registered for path",
+			slog.String(attr.Method, r.Method),
+			slog.String("path", r.URL.Path),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+
+		_ = json.NewEncoder(w).Encode(notFoundBody{
+			Code:    connect.CodeNotFound.String(),
+			Message: "no handler registered for path " + r.URL.Path,
+		})
+	})
+}
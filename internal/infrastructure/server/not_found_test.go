@@ -0,0 +1,41 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestNotFoundHandler(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := &bytes.Buffer{}
+	logger := logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON))
+
+	s := NewConnectServer(&config.Config{Environment: "production"}, logger, nil)
+
+	rec := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api.v1.UnknownService/Get", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "not_found", body["code"])
+	assert.Contains(t, body["message"], "/api.v1.UnknownService/Get")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "/api.v1.UnknownService/Get", entry["path"])
+	assert.Equal(t, http.MethodPost, entry["method"])
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/metadata"
+)
+
+// RequiredHeaderInterceptor is a Connect interceptor that rejects requests missing any of the
+// configured headers. The header values are stored in the context, where use cases can read them
+// with metadata.Value.
+type RequiredHeaderInterceptor struct {
+	headers []string
+	exempt  map[string]struct{}
+}
+
+var _ connect.Interceptor = (*RequiredHeaderInterceptor)(nil)
+
+// NewRequiredHeaderInterceptor creates an interceptor that requires the given headers on every request.
+// Missing headers are reported as InvalidArgument AppErrs, so the interceptor must run inside the
+// apperr interceptor.
+func NewRequiredHeaderInterceptor(headers ...string) *RequiredHeaderInterceptor {
+	canonical := make([]string, 0, len(headers))
+	for _, h := range headers {
+		canonical = append(canonical, http.CanonicalHeaderKey(h))
+	}
+
+	return &RequiredHeaderInterceptor{
+		headers: canonical,
+		exempt:  make(map[string]struct{}),
+	}
+}
+
+// Exempt skips the header check for the given procedures, e.g. "/grpc.health.v1.Health/Check".
+func (i *RequiredHeaderInterceptor) Exempt(procedures ...string) *RequiredHeaderInterceptor {
+	for _, procedure := range procedures {
+		i.exempt[procedure] = struct{}{}
+	}
+
+	return i
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *RequiredHeaderInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		ctx, err := i.check(ctx, req.Spec().Procedure, req.Header())
+		if err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor. Client streams are not checked.
+func (i *RequiredHeaderInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+// The error interceptor does not wrap streams, so the error is returned as a connect.Error here.
+func (i *RequiredHeaderInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.check(ctx, conn.Spec().Procedure, conn.RequestHeader())
+		if err != nil {
+			return connect.NewError(connect.CodeInvalidArgument, err)
+		}
+
+		return next(ctx, conn)
+	}
+}
+
+// check verifies the required headers are present and stores their values in ctx.
+func (i *RequiredHeaderInterceptor) check(ctx context.Context, procedure string, header http.Header) (context.Context, error) {
+	if _, ok := i.exempt[procedure]; ok {
+		return ctx, nil
+	}
+
+	md := make(metadata.Metadata, len(i.headers))
+
+	for _, h := range i.headers {
+		value := header.Get(h)
+		if value == "" {
+			return ctx, apperr.New(codes.InvalidArgument, "missing required header", slog.String("header", h))
+		}
+
+		md[h] = value
+	}
+
+	return metadata.NewContext(ctx, md), nil
+}
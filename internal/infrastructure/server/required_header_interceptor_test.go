@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/metadata"
+)
+
+func TestRequiredHeaderInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		interceptor  *RequiredHeaderInterceptor
+		headers      map[string]string
+		wantCode     connect.Code
+		wantTenantID string
+	}{
+		{
+			name:         "store header value in context when present",
+			interceptor:  NewRequiredHeaderInterceptor("X-Tenant-Id"),
+			headers:      map[string]string{"X-Tenant-Id": "tenant-123"},
+			wantTenantID: "tenant-123",
+		},
+		{
+			name:        "return InvalidArgument when header is missing",
+			interceptor: NewRequiredHeaderInterceptor("X-Tenant-Id"),
+			headers:     map[string]string{},
+			wantCode:    connect.CodeInvalidArgument,
+		},
+		{
+			name:        "return InvalidArgument when any of several headers is missing",
+			interceptor: NewRequiredHeaderInterceptor("X-Tenant-Id", "X-Request-Id"),
+			headers:     map[string]string{"X-Tenant-Id": "tenant-123"},
+			wantCode:    connect.CodeInvalidArgument,
+		},
+		{
+			name:         "skip check for exempt procedures",
+			interceptor:  NewRequiredHeaderInterceptor("X-Tenant-Id").Exempt(testProcedure),
+			headers:      map[string]string{},
+			wantTenantID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			var gotTenantID string
+
+			client := newTestServer(t, cfg, logger,
+				func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					gotTenantID = metadata.Value(ctx, "X-Tenant-Id")
+
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				connect.WithInterceptors(tt.interceptor),
+			)
+
+			req := connect.NewRequest(&emptypb.Empty{})
+			for key, value := range tt.headers {
+				req.Header().Set(key, value)
+			}
+
+			_, err := client.CallUnary(context.Background(), req)
+
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, connect.CodeOf(err))
+
+				var connectErr *connect.Error
+				require.ErrorAs(t, err, &connectErr)
+				assert.NotEmpty(t, connectErr.Meta().Get("header"), "expected the missing header in error metadata")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTenantID, gotTenantID)
+		})
+	}
+}
+
+func TestRequiredHeaderInterceptor_WrapStreamingHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		header       http.Header
+		wantCode     connect.Code
+		wantTenantID string
+	}{
+		{
+			name:         "store header value in context when present",
+			header:       http.Header{"X-Tenant-Id": {"tenant-123"}},
+			wantTenantID: "tenant-123",
+		},
+		{
+			name:     "return InvalidArgument when header is missing",
+			header:   http.Header{},
+			wantCode: connect.CodeInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotTenantID string
+
+			handler := NewRequiredHeaderInterceptor("X-Tenant-Id").WrapStreamingHandler(
+				func(ctx context.Context, _ connect.StreamingHandlerConn) error {
+					gotTenantID = metadata.Value(ctx, "X-Tenant-Id")
+
+					return nil
+				},
+			)
+
+			err := handler(context.Background(), &stubStreamingHandlerConn{
+				spec:   connect.Spec{Procedure: testProcedure},
+				header: tt.header,
+			})
+
+			if tt.wantCode != 0 {
+				var connectErr *connect.Error
+				require.ErrorAs(t, err, &connectErr, "expected a connect.Error, not a plain error mapped to Unknown")
+				assert.Equal(t, tt.wantCode, connectErr.Code())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTenantID, gotTenantID)
+		})
+	}
+}
+
+// stubStreamingHandlerConn is a connect.StreamingHandlerConn with a fixed spec and request header.
+// Other methods panic, since interceptors under test do not call them.
+type stubStreamingHandlerConn struct {
+	connect.StreamingHandlerConn
+
+	spec   connect.Spec
+	header http.Header
+}
+
+func (c *stubStreamingHandlerConn) Spec() connect.Spec { return c.spec }
+
+func (c *stubStreamingHandlerConn) RequestHeader() http.Header { return c.header }
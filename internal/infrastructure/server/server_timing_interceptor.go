@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+// serverTimingHeader is the response header read by browser developer tools and front-end performance tooling.
+const serverTimingHeader = "Server-Timing"
+
+// newServerTimingInterceptor creates a Connect interceptor that reports the handler duration to clients
+// in a "Server-Timing: app;dur=<ms>" response header, also on error responses.
+// Inside the access log interceptor, the duration is measured from the same start as its duration_ms,
+// so the header and the access log of a request agree.
+func newServerTimingInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			start := time.Now()
+
+			resp, err := next(ctx, req)
+
+			duration, ok := logging.RequestDuration(ctx)
+			if !ok {
+				duration = time.Since(start)
+			}
+
+			value := fmt.Sprintf("app;dur=%d", duration.Milliseconds())
+
+			var connectErr *connect.Error
+			switch {
+			case err == nil && resp != nil:
+				resp.Header().Set(serverTimingHeader, value)
+			case errors.As(err, &connectErr):
+				connectErr.Meta().Set(serverTimingHeader, value)
+			}
+
+			return resp, err
+		}
+	}
+}
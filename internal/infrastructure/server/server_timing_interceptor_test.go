@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestServerTimingInterceptor(t *testing.T) {
+	t.Parallel()
+
+	serverTiming := regexp.MustCompile(`^app;dur=\d+$`)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		handlerErr error
+		wantHeader bool
+	}{
+		{
+			name:       "set header on successful response",
+			enabled:    true,
+			wantHeader: true,
+		},
+		{
+			name:       "set header on error response",
+			enabled:    true,
+			handlerErr: apperr.New(codes.NotFound, "user not found"),
+			wantHeader: true,
+		},
+		{
+			name:       "set no header when disabled",
+			enabled:    false,
+			wantHeader: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{
+				Server: config.ServerConfig{TimingHeader: tt.enabled},
+			}
+
+			client := newTestServer(t, cfg, logging.New(logging.WithWriter(io.Discard)),
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					if tt.handlerErr != nil {
+						return nil, tt.handlerErr
+					}
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+			)
+
+			resp, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+
+			var header string
+			if tt.handlerErr != nil {
+				var connectErr *connect.Error
+				require.True(t, errors.As(err, &connectErr))
+				header = connectErr.Meta().Get(serverTimingHeader)
+			} else {
+				require.NoError(t, err)
+				header = resp.Header().Get(serverTimingHeader)
+			}
+
+			if tt.wantHeader {
+				assert.Regexp(t, serverTiming, header)
+			} else {
+				assert.Empty(t, header)
+			}
+		})
+	}
+}
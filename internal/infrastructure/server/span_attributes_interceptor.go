@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanAttributesFunc returns the attributes to set on the span of req, e.g. the ID of the entity
+// it targets, or none if it has no attributes of interest.
+type SpanAttributesFunc func(req connect.AnyRequest) []attribute.KeyValue
+
+// NewSpanAttributesInterceptor creates a Connect interceptor that sets the attributes returned by fn
+// on the span of every request, so that traces can be searched by them. The span is started by the
+// tracing interceptor, so it is applied per handler with WithHandlerInterceptors, where fn knows the
+// request messages. Without a recording span, it does nothing.
+func NewSpanAttributesInterceptor(fn SpanAttributesFunc) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if span := trace.SpanFromContext(ctx); span.IsRecording() {
+				if attrs := fn(req); len(attrs) > 0 {
+					span.SetAttributes(attrs...)
+				}
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSpanAttributesInterceptor(t *testing.T) {
+	t.Parallel()
+
+	// Maps a request carrying an ID to the attribute, as a per-procedure function would
+	idAttributes := func(req connect.AnyRequest) []attribute.KeyValue {
+		if msg, ok := req.Any().(*wrapperspb.StringValue); ok {
+			return []attribute.KeyValue{attribute.String("post_id", msg.GetValue())}
+		}
+
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		req       connect.AnyRequest
+		wantAttrs []attribute.KeyValue
+	}{
+		{
+			name:      "set extracted attributes on span",
+			req:       connect.NewRequest(wrapperspb.String("post-123")),
+			wantAttrs: []attribute.KeyValue{attribute.String("post_id", "post-123")},
+		},
+		{
+			name:      "leave span unchanged without attributes",
+			req:       connect.NewRequest(wrapperspb.Bool(true)),
+			wantAttrs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			ctx, span := provider.Tracer("test").Start(context.Background(), "handler")
+
+			interceptor := NewSpanAttributesInterceptor(idAttributes)
+			next := func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+				return nil, nil
+			}
+
+			_, err := interceptor(next)(ctx, tt.req)
+			require.NoError(t, err)
+
+			span.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.wantAttrs, spans[0].Attributes())
+		})
+	}
+}
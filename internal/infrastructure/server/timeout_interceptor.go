@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// handlerResult holds the outcome of a unary handler call, or the value it panicked with.
+type handlerResult struct {
+	resp  connect.AnyResponse
+	err   error
+	panic any
+}
+
+// timeoutInterceptor is a Connect interceptor that bounds handlers by a timeout.
+// Unlike http.TimeoutHandler, which replies with an empty 503 body, it returns a DeadlineExceeded error
+// so that clients receive a proper Connect error code.
+type timeoutInterceptor struct {
+	timeout time.Duration
+}
+
+// newTimeoutInterceptor creates a timeoutInterceptor bounding unary and streaming handlers by the given timeout.
+// A non-positive timeout disables the interceptor.
+func newTimeoutInterceptor(timeout time.Duration) *timeoutInterceptor {
+	return &timeoutInterceptor{timeout: timeout}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *timeoutInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	timeout := i.timeout
+	if timeout <= 0 {
+		return next
+	}
+
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// Run the handler in a goroutine so that handlers ignoring the context cannot block the response.
+		done := make(chan handlerResult, 1)
+
+		go func() {
+			// A panic cannot reach the recover handler of connect.WithRecover from this goroutine and would
+			// crash the process, so it is handed back to be re-raised in the goroutine serving the request.
+			defer func() {
+				if p := recover(); p != nil {
+					done <- handlerResult{panic: p}
+				}
+			}()
+
+			resp, err := next(ctx, req)
+			done <- handlerResult{resp: resp, err: err}
+		}()
+
+		select {
+		case result := <-done:
+			if result.panic != nil {
+				panic(result.panic)
+			}
+
+			if result.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, newDeadlineExceededError(req, timeout)
+			}
+
+			return result.resp, result.err
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, newDeadlineExceededError(req, timeout)
+			}
+
+			return nil, apperr.Wrap(ctx.Err(), codes.Canceled, "request canceled")
+		}
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor. Client streams are not bounded.
+func (i *timeoutInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+// A stream cannot be abandoned like a unary call, since its connection belongs to the handler,
+// so stream handlers must return once their context is done. The error interceptor does not wrap
+// streams, so the error is returned as a connect.Error here.
+func (i *timeoutInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	timeout := i.timeout
+	if timeout <= 0 {
+		return next
+	}
+
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := next(ctx, conn)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return connect.NewError(connect.CodeDeadlineExceeded, errors.New("handler timed out"))
+		}
+
+		return err
+	}
+}
+
+// withHandlerTimeout bounds a plain HTTP handler, e.g. /version or pprof, by timeout, since the
+// timeout interceptor only covers Connect routes. A non-positive timeout disables it.
+func withHandlerTimeout(h http.Handler, timeout time.Duration) http.Handler {
+	if timeout <= 0 {
+		return h
+	}
+
+	return http.TimeoutHandler(h, timeout, "")
+}
+
+func newDeadlineExceededError(req connect.AnyRequest, timeout time.Duration) error {
+	return apperr.New(codes.DeadlineExceeded, "handler timed out",
+		attr.Procedure(req.Spec().Procedure),
+		slog.Duration("timeout", timeout),
+	)
+}
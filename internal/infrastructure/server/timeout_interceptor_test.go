@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestTimeoutInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		handler  func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error)
+		wantCode connect.Code
+		wantErr  bool
+	}{
+		{
+			name:    "return DeadlineExceeded when handler ignores the context",
+			timeout: 50 * time.Millisecond,
+			handler: func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				time.Sleep(500 * time.Millisecond)
+
+				return connect.NewResponse(&emptypb.Empty{}), nil
+			},
+			wantCode: connect.CodeDeadlineExceeded,
+			wantErr:  true,
+		},
+		{
+			name:    "return DeadlineExceeded when handler aborts on context deadline",
+			timeout: 50 * time.Millisecond,
+			handler: func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			},
+			wantCode: connect.CodeDeadlineExceeded,
+			wantErr:  true,
+		},
+		{
+			name:    "return Internal when handler panics",
+			timeout: time.Second,
+			handler: func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				panic("boom")
+			},
+			wantCode: connect.CodeInternal,
+			wantErr:  true,
+		},
+		{
+			name:    "return response when handler completes in time",
+			timeout: time.Second,
+			handler: func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				return connect.NewResponse(&emptypb.Empty{}), nil
+			},
+		},
+		{
+			name:    "disable timeout when it is not positive",
+			timeout: 0,
+			handler: func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				if _, ok := ctx.Deadline(); ok {
+					return nil, errors.New("unexpected deadline")
+				}
+
+				return connect.NewResponse(&emptypb.Empty{}), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{
+				Server: config.ServerConfig{HandlerTimeout: tt.timeout},
+			}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			client := newTestServer(t, cfg, logger, tt.handler)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var connectErr *connect.Error
+			require.True(t, errors.As(err, &connectErr))
+			assert.Equal(t, tt.wantCode, connectErr.Code())
+		})
+	}
+}
+
+func TestTimeoutInterceptor_WrapStreamingHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		handler  connect.StreamingHandlerFunc
+		wantCode connect.Code // zero if the stream is expected to succeed
+	}{
+		{
+			name:    "return DeadlineExceeded when handler aborts on context deadline",
+			timeout: 50 * time.Millisecond,
+			handler: func(ctx context.Context, _ connect.StreamingHandlerConn) error {
+				<-ctx.Done()
+
+				return ctx.Err()
+			},
+			wantCode: connect.CodeDeadlineExceeded,
+		},
+		{
+			name:    "return nil when handler completes in time",
+			timeout: time.Second,
+			handler: func(context.Context, connect.StreamingHandlerConn) error {
+				return nil
+			},
+		},
+		{
+			name:    "disable timeout when it is not positive",
+			timeout: 0,
+			handler: func(ctx context.Context, _ connect.StreamingHandlerConn) error {
+				if _, ok := ctx.Deadline(); ok {
+					return errors.New("unexpected deadline")
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTimeoutInterceptor(tt.timeout).WrapStreamingHandler(tt.handler)
+
+			err := handler(context.Background(), &stubStreamingHandlerConn{spec: connect.Spec{Procedure: testProcedure}})
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var connectErr *connect.Error
+			require.ErrorAs(t, err, &connectErr)
+			assert.Equal(t, tt.wantCode, connectErr.Code())
+		})
+	}
+}
+
+func TestWithHandlerTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		delay      time.Duration
+		wantStatus int
+	}{
+		{
+			name:       "return 503 when handler exceeds the timeout",
+			timeout:    50 * time.Millisecond,
+			delay:      500 * time.Millisecond,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "return response when handler completes in time",
+			timeout:    time.Second,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "disable timeout when it is not positive",
+			timeout:    0,
+			delay:      100 * time.Millisecond,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := withHandlerTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}), tt.timeout)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, versionPath, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// forwardingHeaders report the client address behind a proxy. Clients connecting directly can set
+// them to any value, so they are only honored from trusted proxies.
+var forwardingHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// newTrustedProxyInterceptor creates a Connect interceptor that strips the forwarding headers of requests
+// whose immediate peer is not within trusted, so that the access log and anything else reading the client
+// address from them falls back to the address of the connection itself. Without trusted prefixes,
+// they are stripped from every request.
+// Stripped headers are logged at Debug, since spoofed headers are expected from untrusted clients.
+//
+// From a trusted proxy, X-Forwarded-For is reduced to the client address: its rightmost address that is
+// not a trusted proxy. Addresses to the left of it were sent by the client and may be spoofed.
+func newTrustedProxyInterceptor(trusted []netip.Prefix, logger *logging.Logger) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			peer := req.Peer().Addr
+
+			if isTrustedPeer(peer, trusted) {
+				if forwardedFor := req.Header().Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+					req.Header().Set("X-Forwarded-For", forwardedClient(forwardedFor, trusted))
+				}
+			} else {
+				stripForwardingHeaders(ctx, logger, req.Header(), req.Spec().Procedure, peer)
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// forwardedClient returns the client address of the X-Forwarded-For header values: the rightmost
+// address that is not within trusted, since each proxy appends the address it received the request from.
+// An unparsable entry is returned as is, since it cannot be a trusted proxy. If every address is
+// a trusted proxy, the leftmost one is returned.
+func forwardedClient(values []string, trusted []netip.Prefix) string {
+	var addrs []string
+
+	for _, value := range values {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	if len(addrs) == 0 {
+		return ""
+	}
+
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if !isTrustedPeer(addrs[i], trusted) {
+			return addrs[i]
+		}
+	}
+
+	return addrs[0]
+}
+
+// isTrustedPeer reports whether the peer address, such as "10.0.0.1:54321", is within one of the trusted prefixes.
+func isTrustedPeer(peer string, trusted []netip.Prefix) bool {
+	var addr netip.Addr
+
+	if addrPort, err := netip.ParseAddrPort(peer); err == nil {
+		addr = addrPort.Addr()
+	} else if addr, err = netip.ParseAddr(peer); err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stripForwardingHeaders removes the forwarding headers from header, logging those that were set.
+func stripForwardingHeaders(ctx context.Context, logger *logging.Logger, header http.Header, procedure, peer string) {
+	for _, h := range forwardingHeaders {
+		value := header.Get(h)
+		if value == "" {
+			continue
+		}
+
+		header.Del(h)
+
+		logger.Debug(ctx, "Stripped forwarding header from untrusted peer",
+			attr.Procedure(procedure),
+			attr.RemoteAddr(peer),
+			slog.String("header", h),
+		)
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestTrustedProxyInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		header         map[string]string
+		wantRemoteAddr string
+		wantForwarded  string
+	}{
+		{
+			name:           "honor X-Forwarded-For from trusted proxy",
+			trustedProxies: []string{"127.0.0.0/8"},
+			header:         map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			wantRemoteAddr: "203.0.113.7",
+			wantForwarded:  "203.0.113.7",
+		},
+		{
+			name:           "honor X-Real-IP from trusted proxy",
+			trustedProxies: []string{"127.0.0.1"},
+			header:         map[string]string{"X-Real-IP": "203.0.113.8"},
+			wantRemoteAddr: "203.0.113.8",
+		},
+		{
+			name:           "strip X-Forwarded-For from untrusted peer",
+			trustedProxies: []string{"10.0.0.0/8"},
+			header:         map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			wantRemoteAddr: "127.0.0.1",
+		},
+		{
+			name:           "strip X-Real-IP from untrusted peer",
+			trustedProxies: []string{"10.0.0.0/8"},
+			header:         map[string]string{"X-Real-IP": "203.0.113.8"},
+			wantRemoteAddr: "127.0.0.1",
+		},
+		{
+			name:           "strip forwarding headers from every peer without trusted proxies",
+			header:         map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.8"},
+			wantRemoteAddr: "127.0.0.1",
+		},
+		{
+			name:           "use rightmost untrusted address of X-Forwarded-For chain",
+			trustedProxies: []string{"127.0.0.0/8", "10.0.0.0/8"},
+			header:         map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.2"},
+			wantRemoteAddr: "203.0.113.7",
+			wantForwarded:  "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logBuffer := logging.NewTestLogger()
+
+			cfg := &config.Config{
+				Server: config.ServerConfig{TrustedProxies: tt.trustedProxies},
+			}
+
+			var gotForwarded string
+
+			client := newTestServer(t, cfg, logger,
+				func(_ context.Context, req *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					gotForwarded = req.Header().Get("X-Forwarded-For")
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+			)
+
+			req := connect.NewRequest(&emptypb.Empty{})
+			for k, v := range tt.header {
+				req.Header().Set(k, v)
+			}
+
+			_, err := client.CallUnary(context.Background(), req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantForwarded, gotForwarded)
+
+			var accessLog map[string]any
+			for _, line := range strings.Split(strings.TrimSpace(logBuffer.String()), "\n") {
+				var entry map[string]any
+				require.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+				if entry["msg"] == "Access log" {
+					accessLog = entry
+				}
+			}
+
+			require.NotNil(t, accessLog, "expected an access log in %s", logBuffer.String())
+			assert.Equal(t, tt.wantRemoteAddr, accessLog["remote_addr"])
+		})
+	}
+}
+
+func TestIsTrustedPeer(t *testing.T) {
+	t.Parallel()
+
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name string
+		peer string
+		want bool
+	}{
+		{name: "IPv4 peer within prefix", peer: "10.1.2.3:54321", want: true},
+		{name: "IPv4 peer outside prefixes", peer: "192.168.1.1:54321", want: false},
+		{name: "IPv6 peer within prefix", peer: "[fd00::1]:54321", want: true},
+		{name: "IPv4-mapped IPv6 peer within prefix", peer: "[::ffff:10.1.2.3]:54321", want: true},
+		{name: "peer without port", peer: "10.1.2.3", want: true},
+		{name: "unparsable peer", peer: "pipe", want: false},
+		{name: "empty peer", peer: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, isTrustedPeer(tt.peer, trusted))
+		})
+	}
+}
+
+func TestForwardedClient(t *testing.T) {
+	t.Parallel()
+
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{name: "single address", values: []string{"203.0.113.7"}, want: "203.0.113.7"},
+		{name: "skip trusted proxies from the right", values: []string{"203.0.113.7, 10.0.0.1, 10.0.0.2"}, want: "203.0.113.7"},
+		{name: "ignore spoofed addresses left of the client", values: []string{"198.51.100.1, 203.0.113.7, 10.0.0.1"}, want: "203.0.113.7"},
+		{name: "join repeated headers", values: []string{"198.51.100.1", "203.0.113.7, 10.0.0.1"}, want: "203.0.113.7"},
+		{name: "return unparsable entry", values: []string{"203.0.113.7, unknown, 10.0.0.1"}, want: "unknown"},
+		{name: "return leftmost when all are trusted", values: []string{"10.0.0.3, 10.0.0.1"}, want: "10.0.0.3"},
+		{name: "return empty for empty header", values: []string{" , "}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, forwardedClient(tt.values, trusted))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// UserAgentFilterMode defines whether the patterns of a UserAgentFilterInterceptor list the
+// user agents to allow or to deny.
+type UserAgentFilterMode int
+
+const (
+	// UserAgentAllow accepts only requests whose User-Agent matches a pattern, e.g. to require
+	// known clients. Requests without a User-Agent are rejected.
+	UserAgentAllow UserAgentFilterMode = iota
+	// UserAgentDeny rejects requests whose User-Agent matches a pattern, e.g. to block scrapers.
+	// Requests without a User-Agent are accepted.
+	UserAgentDeny
+)
+
+// UserAgentFilterInterceptor is a Connect interceptor that accepts or rejects requests by their
+// User-Agent header.
+type UserAgentFilterInterceptor struct {
+	mode     UserAgentFilterMode
+	patterns []string
+}
+
+var _ connect.Interceptor = (*UserAgentFilterInterceptor)(nil)
+
+// NewUserAgentFilterInterceptor creates an interceptor that filters requests by their User-Agent
+// header with patterns in mode. A pattern matches a User-Agent that contains it, ignoring case,
+// e.g. "python-requests" matches "python-requests/2.32.3". Rejected requests are reported as
+// PermissionDenied AppErrs, so the interceptor must run inside the apperr interceptor.
+func NewUserAgentFilterInterceptor(mode UserAgentFilterMode, patterns []string) *UserAgentFilterInterceptor {
+	lower := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p != "" {
+			lower = append(lower, strings.ToLower(p))
+		}
+	}
+
+	return &UserAgentFilterInterceptor{
+		mode:     mode,
+		patterns: lower,
+	}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *UserAgentFilterInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.check(req.Header()); err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor. Client streams are not checked.
+func (i *UserAgentFilterInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+// The error interceptor does not wrap streams, so the error is returned as a connect.Error here.
+func (i *UserAgentFilterInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.check(conn.RequestHeader()); err != nil {
+			return connect.NewError(connect.CodePermissionDenied, err)
+		}
+
+		return next(ctx, conn)
+	}
+}
+
+// check returns a PermissionDenied error if the User-Agent of header is not accepted.
+func (i *UserAgentFilterInterceptor) check(header http.Header) error {
+	userAgent := header.Get("User-Agent")
+
+	if i.matches(userAgent) == (i.mode == UserAgentAllow) {
+		return nil
+	}
+
+	return apperr.New(codes.PermissionDenied, "user agent not allowed", attr.UserAgent(userAgent))
+}
+
+// matches reports whether userAgent matches any of the patterns.
+func (i *UserAgentFilterInterceptor) matches(userAgent string) bool {
+	if userAgent == "" {
+		return false
+	}
+
+	userAgent = strings.ToLower(userAgent)
+
+	for _, p := range i.patterns {
+		if strings.Contains(userAgent, p) {
+			return true
+		}
+	}
+
+	return false
+}
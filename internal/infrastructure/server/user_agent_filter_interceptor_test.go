@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+func TestUserAgentFilterInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		mode      UserAgentFilterMode
+		patterns  []string
+		userAgent string
+		wantCode  connect.Code // zero if the request is expected to be accepted
+	}{
+		{
+			name:      "allow mode accepts matching user agent",
+			mode:      UserAgentAllow,
+			patterns:  []string{"scaffold-ios", "scaffold-web"},
+			userAgent: "scaffold-web/1.4.0",
+		},
+		{
+			name:      "allow mode matches ignoring case",
+			mode:      UserAgentAllow,
+			patterns:  []string{"Scaffold-iOS"},
+			userAgent: "scaffold-ios/2.0 (iPhone)",
+		},
+		{
+			name:      "allow mode rejects non-matching user agent",
+			mode:      UserAgentAllow,
+			patterns:  []string{"scaffold-ios", "scaffold-web"},
+			userAgent: "curl/8.7.1",
+			wantCode:  connect.CodePermissionDenied,
+		},
+		{
+			name:     "allow mode rejects missing user agent",
+			mode:     UserAgentAllow,
+			patterns: []string{"scaffold-web"},
+			wantCode: connect.CodePermissionDenied,
+		},
+		{
+			name:      "deny mode rejects matching user agent",
+			mode:      UserAgentDeny,
+			patterns:  []string{"python-requests", "bot"},
+			userAgent: "Mozilla/5.0 (compatible; ExampleBot/1.0)",
+			wantCode:  connect.CodePermissionDenied,
+		},
+		{
+			name:      "deny mode accepts non-matching user agent",
+			mode:      UserAgentDeny,
+			patterns:  []string{"python-requests", "bot"},
+			userAgent: "scaffold-web/1.4.0",
+		},
+		{
+			name:     "deny mode accepts missing user agent",
+			mode:     UserAgentDeny,
+			patterns: []string{"python-requests"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{}
+			logger := logging.New(logging.WithWriter(io.Discard))
+
+			client := newTestServer(t, cfg, logger,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				connect.WithInterceptors(NewUserAgentFilterInterceptor(tt.mode, tt.patterns)),
+			)
+
+			req := connect.NewRequest(&emptypb.Empty{})
+			// Set explicitly, since the client sends its own User-Agent otherwise
+			req.Header().Set("User-Agent", tt.userAgent)
+
+			_, err := client.CallUnary(context.Background(), req)
+
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, connect.CodeOf(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUserAgentFilterInterceptor_WrapStreamingHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		userAgent string
+		wantCode  connect.Code // zero if the stream is expected to be accepted
+	}{
+		{
+			name:      "accept matching user agent",
+			userAgent: "scaffold-web/1.4.0",
+		},
+		{
+			name:      "reject non-matching user agent",
+			userAgent: "curl/8.7.1",
+			wantCode:  connect.CodePermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			called := false
+
+			handler := NewUserAgentFilterInterceptor(UserAgentAllow, []string{"scaffold-web"}).WrapStreamingHandler(
+				func(context.Context, connect.StreamingHandlerConn) error {
+					called = true
+
+					return nil
+				},
+			)
+
+			err := handler(context.Background(), &stubStreamingHandlerConn{
+				spec:   connect.Spec{Procedure: testProcedure},
+				header: http.Header{"User-Agent": {tt.userAgent}},
+			})
+
+			if tt.wantCode != 0 {
+				var connectErr *connect.Error
+				require.ErrorAs(t, err, &connectErr, "expected a connect.Error, not a plain error mapped to Unknown")
+				assert.Equal(t, tt.wantCode, connectErr.Code())
+				assert.False(t, called)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
+)
+
+// versionPath is the path of the build version endpoint.
+const versionPath = "/version"
+
+// versionInfo describes the running build, to tell which version each instance runs when debugging version skew.
+type versionInfo struct {
+	Service   string `json:"service"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// newVersionHandler creates an HTTP handler that returns the service name and version from cfg,
+// and the VCS commit and Go version the binary was built with, as JSON.
+// They are the same as the labels of the app.build.info metric.
+func newVersionHandler(cfg *config.Config) http.Handler {
+	info := versionInfo{
+		Service:   cfg.Telemetry.ServiceName,
+		Version:   cfg.Telemetry.ServiceVersion,
+		Commit:    telemetry.BuildCommit(),
+		GoVersion: telemetry.GoVersion(),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(info); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+)
+
+func TestVersionHandler(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Telemetry: config.TelemetryConfig{
+			ServiceName:    "go-backend-scaffold",
+			ServiceVersion: "1.2.3",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		wantStatus int
+		wantBody   map[string]string
+	}{
+		{
+			name:       "return service, version, commit and Go version as JSON",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantBody: map[string]string{
+				"service":    "go-backend-scaffold",
+				"version":    "1.2.3",
+				"commit":     "unknown", // Test binaries are built without VCS information
+				"go_version": runtime.Version(),
+			},
+		},
+		{
+			name:       "reject non-GET requests",
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			newVersionHandler(cfg).ServeHTTP(rec, httptest.NewRequest(tt.method, versionPath, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantBody == nil {
+				return
+			}
+
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var got map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantBody, got)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+)
+
+// listResult normalizes the result of a repository List call so that all list use cases behave
+// identically: an empty result is an empty slice with an empty cursor and no error, even if the
+// repository returns nil or codes.NotFound. Invalid arguments are passed through, an expired ctx
+// returns codes.DeadlineExceeded, and any other error is wrapped as codes.Internal with msg.
+func listResult[T any](ctx context.Context, items []T, nextCursor string, err error, msg string) ([]T, string, error) {
+	if err != nil {
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return nil, "", apperr.Wrap(err, codes.DeadlineExceeded, msg)
+		case apperr.IsCode(err, codes.NotFound):
+			return []T{}, "", nil
+		case apperr.IsCode(err, codes.InvalidArgument):
+			return nil, "", err
+		default:
+			return nil, "", apperr.Wrap(err, errorCode(ctx, err, codes.Internal), msg)
+		}
+	}
+
+	if items == nil {
+		items = []T{}
+	}
+
+	return items, nextCursor, nil
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
+)
+
+// Option defines a function that configures a use case.
+type Option func(*options)
+
+// options holds the optional dependencies of use cases.
+type options struct {
+	clock     clock.Clock
+	publisher EventPublisher
+	notifier  Notifier
+	timeout   time.Duration
+
+	maxPendingNotifications int
+
+	userValidators []Validator[entity.NewUser]
+	postValidators []Validator[entity.NewPost]
+}
+
+// EventPublisher publishes domain events, such as entity.UserCreated, to in-process subscribers.
+// event.Bus implements it.
+type EventPublisher interface {
+	Publish(ctx context.Context, e event.Event)
+}
+
+// nopPublisher is an EventPublisher that drops all events.
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, event.Event) {}
+
+// Notifier notifies users of activity, such as the followers of the author of a created post.
+type Notifier interface {
+	NotifyPostCreated(ctx context.Context, post *entity.Post) error
+}
+
+// nopNotifier is a Notifier that notifies no one.
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyPostCreated(context.Context, *entity.Post) error { return nil }
+
+// defaultOptions returns the default use case options.
+func defaultOptions() *options {
+	return &options{
+		clock:     clock.New(),
+		publisher: nopPublisher{},
+		notifier:  nopNotifier{},
+
+		maxPendingNotifications: defaultMaxPendingNotifications,
+	}
+}
+
+// newOptions applies opts on top of the defaults.
+func newOptions(opts []Option) *options {
+	o := defaultOptions()
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithClock sets the clock used for timestamps, which makes them deterministic in tests.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		if c != nil {
+			o.clock = c
+		}
+	}
+}
+
+// WithEventPublisher sets the publisher of domain events. Events are dropped by default.
+func WithEventPublisher(p EventPublisher) Option {
+	return func(o *options) {
+		if p != nil {
+			o.publisher = p
+		}
+	}
+}
+
+// WithNotifier sets the notifier called after a post is created. It is called in the background,
+// so a slow, failing, or panicking notifier never delays or fails the create; its errors are only logged.
+// No one is notified by default, and at most WithMaxPendingNotifications notifications are in flight.
+func WithNotifier(n Notifier) Option {
+	return func(o *options) {
+		if n != nil {
+			o.notifier = n
+		}
+	}
+}
+
+// defaultMaxPendingNotifications is the number of post creation notifications that may be in flight at once
+// unless set by WithMaxPendingNotifications.
+const defaultMaxPendingNotifications = 100
+
+// WithMaxPendingNotifications bounds the number of notifications of WithNotifier in flight at once to n,
+// so a slow notifier cannot pile up goroutines. Notifications beyond it are dropped and logged at Warn.
+// A non-positive n keeps the default of 100.
+func WithMaxPendingNotifications(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxPendingNotifications = n
+		}
+	}
+}
+
+// WithCallTimeout bounds every use case call by timeout, e.g. for background jobs without a request deadline.
+// A call that runs out of time returns codes.DeadlineExceeded. A non-positive timeout, the default,
+// leaves calls bounded only by the context passed in.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserValidators adds validators run on the params of UserUseCase.CreateUser after the built-in
+// validation and before the user is persisted. Their violations are returned together as a single
+// codes.InvalidArgument error.
+func WithUserValidators(validators ...Validator[entity.NewUser]) Option {
+	return func(o *options) {
+		o.userValidators = append(o.userValidators, validators...)
+	}
+}
+
+// WithPostValidators adds validators run on the params of PostUseCase.CreatePost after the built-in
+// validation and before the post is persisted. Their violations are returned together as a single
+// codes.InvalidArgument error.
+func WithPostValidators(validators ...Validator[entity.NewPost]) Option {
+	return func(o *options) {
+		o.postValidators = append(o.postValidators, validators...)
+	}
+}
+
+// withCallTimeout returns ctx bounded by timeout, or ctx itself if timeout is not positive.
+func withCallTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// errorCode returns the code to wrap an error err of a repository call with: codes.DeadlineExceeded if
+// the deadline of ctx has passed, since that is why the call failed regardless of the error returned,
+// the code of err if it is an AppErr, e.g. Unavailable while the database circuit is open, and code
+// for any other error.
+func errorCode(ctx context.Context, err error, code codes.Code) codes.Code {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return codes.DeadlineExceeded
+	}
+
+	var appErr *apperr.AppErr
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	return code
+}
+
+// stampTimestamps sets zero timestamps to the current time of c.
+// Repositories normally populate them, but this guarantees created entities always carry them.
+func stampTimestamps(createdAt, updatedAt *time.Time, c clock.Clock) {
+	if !createdAt.IsZero() && !updatedAt.IsZero() {
+		return
+	}
+
+	now := c.Now()
+
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
@@ -4,72 +4,254 @@ package usecase
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"github.com/pannpers/go-backend-scaffold/pkg/safego"
+	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // PostUseCase handles post business logic.
 type PostUseCase struct {
 	postRepo entity.PostRepository
 	logger   *logging.Logger
+	clock    clock.Clock
+	notifier Notifier
+	timeout  time.Duration
+
+	validators []Validator[entity.NewPost]
+
+	// notifications is a semaphore bounding the notifications in flight, which wg tracks for Close
+	notifications chan struct{}
+	wg            sync.WaitGroup
 }
 
 // NewPostUseCase creates a new post use case.
-func NewPostUseCase(postRepo entity.PostRepository, logger *logging.Logger) *PostUseCase {
+func NewPostUseCase(postRepo entity.PostRepository, logger *logging.Logger, opts ...Option) *PostUseCase {
+	o := newOptions(opts)
+
 	return &PostUseCase{
 		postRepo: postRepo,
 		logger:   logger,
+		clock:    o.clock,
+		notifier: o.notifier,
+		timeout:  o.timeout,
+
+		validators: o.postValidators,
+
+		notifications: make(chan struct{}, o.maxPendingNotifications),
 	}
 }
 
-// CreatePost creates a new post.
+// CreatePost validates params and creates a new post, published immediately unless params.PublishedAt
+// schedules it.
+// Invalid params return codes.InvalidArgument with an entity.Reason in the "reason" attribute,
+// and params rejected by validators of WithPostValidators return it with all their field violations.
 func (uc *PostUseCase) CreatePost(ctx context.Context, params *entity.NewPost) (*entity.Post, error) {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	ctx, span := telemetry.StartSpan(ctx, "PostUseCase.CreatePost")
+	defer span.End()
+
+	if err := validateNewPost(params); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	if err := runValidators(ctx, "post", uc.validators, params); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String(attr.UserIDKey, params.UserID))
+
+	params = publishOnCreate(params, uc.clock)
+
 	post, err := uc.postRepo.Create(ctx, params)
 	if err != nil {
-		return nil, apperr.Wrap(err, codes.Internal, "failed to create post", 
+		telemetry.RecordError(span, err)
+
+		return nil, apperr.Wrap(err, errorCode(ctx, err, codes.Internal), "failed to create post", 
 			slog.String("title", params.Title),
-			slog.String("user_id", params.UserID),
+			attr.UserID(params.UserID),
 		)
 	}
 
-	uc.logger.Info(ctx, "Post created successfully", slog.String("post_id", post.ID))
+	stampTimestamps(&post.CreatedAt, &post.UpdatedAt, uc.clock)
+
+	span.SetAttributes(attribute.String(attr.PostIDKey, post.ID))
+
+	uc.logger.Info(ctx, "Post created successfully", attr.PostID(post.ID))
+
+	// Notified with a copy in the background, outliving the request, so the caller can use post freely
+	uc.notifyInBackground(context.WithoutCancel(ctx), *post)
 
 	return post, nil
 }
 
+// publishOnCreate returns params with PublishedAt set to the current time of c if it is nil,
+// so that created posts are published immediately unless scheduled. params itself is not modified.
+func publishOnCreate(params *entity.NewPost, c clock.Clock) *entity.NewPost {
+	if params.PublishedAt != nil {
+		return params
+	}
+
+	now := c.Now()
+	published := *params
+	published.PublishedAt = &now
+
+	return &published
+}
+
+// notifyInBackground notifies of post in a new goroutine, or drops the notification with a warning
+// if the maximum number of notifications is already in flight, so that CreatePost never waits for the notifier.
+func (uc *PostUseCase) notifyInBackground(ctx context.Context, post entity.Post) {
+	select {
+	case uc.notifications <- struct{}{}:
+	default:
+		uc.logger.Warn(ctx, "Dropping post creation notification since too many are in flight", attr.PostID(post.ID))
+
+		return
+	}
+
+	uc.wg.Add(1)
+
+	go func() {
+		defer uc.wg.Done()
+		defer func() { <-uc.notifications }()
+		defer safego.Recover(ctx, uc.logger, attr.PostID(post.ID))
+
+		uc.notifyPostCreated(ctx, &post)
+	}()
+}
+
+// Close waits for in-flight notifications to finish.
+// Call it after CreatePost is no longer called, e.g. once the server has shut down.
+func (uc *PostUseCase) Close() error {
+	uc.wg.Wait()
+
+	return nil
+}
+
+// notifyPostCreated calls the notifier, logging rather than returning its error since the post is already created.
+func (uc *PostUseCase) notifyPostCreated(ctx context.Context, post *entity.Post) {
+	if err := uc.notifier.NotifyPostCreated(ctx, post); err != nil {
+		uc.logger.Error(ctx, "Failed to notify post creation", err, attr.PostID(post.ID))
+	}
+}
+
 // GetPost retrieves a post by ID.
 func (uc *PostUseCase) GetPost(ctx context.Context, id string) (*entity.Post, error) {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	ctx, span := telemetry.StartSpan(ctx, "PostUseCase.GetPost")
+	defer span.End()
+
+	span.SetAttributes(attribute.String(attr.PostIDKey, id))
+
 	if id == "" {
-		return nil, apperr.New(codes.InvalidArgument, "post ID cannot be empty")
+		err := apperr.New(codes.InvalidArgument, "post ID cannot be empty")
+		telemetry.RecordError(span, err)
+
+		return nil, err
 	}
 
 	post, err := uc.postRepo.Get(ctx, id)
 	if err != nil {
-		return nil, apperr.Wrap(err, codes.NotFound, "failed to get post", 
-			slog.String("post_id", id),
+		telemetry.RecordError(span, err)
+
+		return nil, apperr.Wrap(err, errorCode(ctx, err, codes.NotFound), "failed to get post", 
+			attr.PostID(id),
 		)
 	}
 
 	return post, nil
 }
 
+// ListPosts retrieves a page of posts and the cursor of the next page.
+// An empty result is not an error and never returns codes.NotFound.
+func (uc *PostUseCase) ListPosts(ctx context.Context, params *entity.ListParams) ([]*entity.Post, string, error) {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	posts, nextCursor, err := uc.postRepo.List(ctx, params)
+
+	return listResult(ctx, posts, nextCursor, err, "failed to list posts")
+}
+
+// StreamPosts pages through all posts with pages of pageSize, or entity.DefaultListLimit if zero,
+// and calls send with each post in order, e.g. to send it as a message of a server stream.
+// WithCallTimeout bounds each page, as for ListPosts, rather than the whole stream, which may run for long.
+// It stops at the first error of send, returned as is, and when ctx is done, returning codes.Canceled
+// or codes.DeadlineExceeded without sending the rest of the page.
+func (uc *PostUseCase) StreamPosts(ctx context.Context, pageSize int, send func(*entity.Post) error) error {
+	params := &entity.ListParams{Limit: pageSize}
+
+	for {
+		if err := streamStopped(ctx); err != nil {
+			return err
+		}
+
+		posts, nextCursor, err := uc.ListPosts(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			if err := streamStopped(ctx); err != nil {
+				return err
+			}
+
+			if err := send(post); err != nil {
+				return err
+			}
+		}
+
+		if nextCursor == "" {
+			return nil
+		}
+
+		params = &entity.ListParams{Limit: pageSize, Cursor: nextCursor}
+	}
+}
+
+// streamStopped returns the error StreamPosts stops with once ctx is done, or nil otherwise.
+func streamStopped(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(err, errorCode(ctx, err, codes.Canceled), "post stream stopped")
+	}
+
+	return nil
+}
+
 // DeletePost deletes a post by ID.
 func (uc *PostUseCase) DeletePost(ctx context.Context, id string) error {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
 	if id == "" {
 		return apperr.New(codes.InvalidArgument, "post ID cannot be empty")
 	}
 
 	err := uc.postRepo.Delete(ctx, id)
 	if err != nil {
-		return apperr.Wrap(err, codes.Internal, "failed to delete post", 
-			slog.String("post_id", id),
+		return apperr.Wrap(err, errorCode(ctx, err, codes.Internal), "failed to delete post", 
+			attr.PostID(id),
 		)
 	}
 
-	uc.logger.Info(ctx, "Post deleted successfully", slog.String("post_id", id))
+	uc.logger.Info(ctx, "Post deleted successfully", attr.PostID(id))
 
 	return nil
 }
@@ -2,18 +2,27 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/internal/usecase"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
 )
 
 func TestPostUseCase_CreatePost(t *testing.T) {
+	scheduledTime := fakeTime.Add(24 * time.Hour)
+
 	type args struct {
 		ctx    context.Context
 		params *entity.NewPost
@@ -25,11 +34,11 @@ func TestPostUseCase_CreatePost(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		dep     func() dep
-		want    *entity.Post
-		wantErr error
+		name     string
+		args     args
+		dep      func() dep
+		want     *entity.Post
+		wantCode codes.Code // zero if no error is expected
 	}{
 		{
 			name: "return created post when valid input provided",
@@ -52,10 +61,47 @@ func TestPostUseCase_CreatePost(t *testing.T) {
 					UpdatedAt: fakeTime,
 				}
 
-				mockRepo.EXPECT().Create(context.Background(), &entity.NewPost{
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewPost{
+					Title:       "Test Post",
+					UserID:      "user-123",
+					PublishedAt: &fakeTime,
+				}).Return(expectedPost, nil).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want: &entity.Post{
+				ID:        "post-456",
+				Title:     "Test Post",
+				UserID:    "user-123",
+				CreatedAt: fakeTime,
+				UpdatedAt: fakeTime,
+			},
+		},
+		{
+			name: "stamp timestamps from clock when repository leaves them zero",
+			args: args{
+				ctx: context.Background(),
+				params: &entity.NewPost{
+					Title:  "Test Post",
+					UserID: "user-123",
+				},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewPost{
+					Title:       "Test Post",
+					UserID:      "user-123",
+					PublishedAt: &fakeTime,
+				}).Return(&entity.Post{
+					ID:     "post-456",
 					Title:  "Test Post",
 					UserID: "user-123",
-				}).Return(expectedPost, nil).Once()
+				}, nil).Once()
 
 				return dep{
 					postRepo: mockRepo,
@@ -69,7 +115,47 @@ func TestPostUseCase_CreatePost(t *testing.T) {
 				CreatedAt: fakeTime,
 				UpdatedAt: fakeTime,
 			},
-			wantErr: nil,
+		},
+		{
+			name: "keep publish time of scheduled post",
+			args: args{
+				ctx: context.Background(),
+				params: &entity.NewPost{
+					Title:       "Scheduled Post",
+					UserID:      "user-123",
+					PublishedAt: &scheduledTime,
+				},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewPost{
+					Title:       "Scheduled Post",
+					UserID:      "user-123",
+					PublishedAt: &scheduledTime,
+				}).Return(&entity.Post{
+					ID:          "post-456",
+					Title:       "Scheduled Post",
+					UserID:      "user-123",
+					CreatedAt:   fakeTime,
+					UpdatedAt:   fakeTime,
+					PublishedAt: &scheduledTime,
+				}, nil).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want: &entity.Post{
+				ID:          "post-456",
+				Title:       "Scheduled Post",
+				UserID:      "user-123",
+				CreatedAt:   fakeTime,
+				UpdatedAt:   fakeTime,
+				PublishedAt: &scheduledTime,
+			},
 		},
 		{
 			name: "return error when repository fails",
@@ -84,9 +170,10 @@ func TestPostUseCase_CreatePost(t *testing.T) {
 				mockRepo := entity.NewMockPostRepository(t)
 				logger := logging.New()
 
-				mockRepo.EXPECT().Create(context.Background(), &entity.NewPost{
-					Title:  "Failed Post",
-					UserID: "user-456",
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewPost{
+					Title:       "Failed Post",
+					UserID:      "user-456",
+					PublishedAt: &fakeTime,
 				}).Return(nil, apperr.New(codes.Internal, "failed to create post")).Once()
 
 				return dep{
@@ -94,23 +181,23 @@ func TestPostUseCase_CreatePost(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			want:    nil,
-			wantErr: apperr.ErrInternal,
+			want:     nil,
+			wantCode: codes.Internal,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			d := tt.dep()
-			uc := usecase.NewPostUseCase(d.postRepo, d.logger)
+			uc := usecase.NewPostUseCase(d.postRepo, d.logger, usecase.WithClock(clock.NewFake(fakeTime)))
 
 			got, err := uc.CreatePost(tt.args.ctx, tt.args.params)
 
-			if tt.wantErr != nil {
+			if tt.wantCode != 0 {
 				assert.Error(t, err)
 				assert.Nil(t, got)
 
-				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -119,6 +206,117 @@ func TestPostUseCase_CreatePost(t *testing.T) {
 	}
 }
 
+// notifierFunc is a usecase.Notifier backed by a function.
+type notifierFunc func(ctx context.Context, post *entity.Post) error
+
+func (f notifierFunc) NotifyPostCreated(ctx context.Context, post *entity.Post) error {
+	return f(ctx, post)
+}
+
+// errorLogHandler is a slog.Handler that sends the messages of error records to a channel,
+// so tests can wait for errors logged in the background.
+type errorLogHandler struct {
+	messages chan string
+}
+
+func (h *errorLogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelError
+}
+
+func (h *errorLogHandler) Handle(_ context.Context, r slog.Record) error {
+	h.messages <- r.Message
+	return nil
+}
+
+func (h *errorLogHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *errorLogHandler) WithGroup(string) slog.Handler { return h }
+
+func TestPostUseCase_CreatePost_Notifies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		notifyErr  error
+		wantNotify bool
+		wantLog    string // empty if no error log is expected
+	}{
+		{
+			name:       "notify after post is created",
+			wantNotify: true,
+		},
+		{
+			name:       "log notifier error without failing the create",
+			notifyErr:  errors.New("notification service unavailable"),
+			wantNotify: true,
+			wantLog:    "Failed to notify post creation",
+		},
+		{
+			name:       "notify nothing when create fails",
+			repoErr:    errors.New("database error"),
+			wantNotify: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := entity.NewMockPostRepository(t)
+			if tt.repoErr != nil {
+				mockRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil, tt.repoErr).Once()
+			} else {
+				mockRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(&entity.Post{ID: "post-456", UserID: "user-123"}, nil).Once()
+			}
+
+			notified := make(chan *entity.Post, 1)
+			handler := &errorLogHandler{messages: make(chan string, 1)}
+
+			uc := usecase.NewPostUseCase(mockRepo, logging.New(logging.WithHandler(handler)),
+				usecase.WithClock(clock.NewFake(fakeTime)),
+				usecase.WithNotifier(notifierFunc(func(_ context.Context, post *entity.Post) error {
+					notified <- post
+					return tt.notifyErr
+				})),
+			)
+
+			post, err := uc.CreatePost(context.Background(), &entity.NewPost{Title: "Test Post", UserID: "user-123"})
+
+			if !tt.wantNotify {
+				assert.Error(t, err)
+
+				select {
+				case <-notified:
+					t.Error("expected no notification")
+				case <-time.After(50 * time.Millisecond):
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "post-456", post.ID)
+
+			select {
+			case got := <-notified:
+				assert.Equal(t, post, got)
+			case <-time.After(time.Second):
+				t.Fatal("expected notifier to be called")
+			}
+
+			if tt.wantLog != "" {
+				select {
+				case got := <-handler.messages:
+					assert.Equal(t, tt.wantLog, got)
+				case <-time.After(time.Second):
+					t.Fatal("expected notifier error to be logged")
+				}
+			}
+		})
+	}
+}
+
 func TestPostUseCase_GetPost(t *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -131,11 +329,11 @@ func TestPostUseCase_GetPost(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		dep     func() dep
-		want    *entity.Post
-		wantErr error
+		name     string
+		args     args
+		dep      func() dep
+		want     *entity.Post
+		wantCode codes.Code // zero if no error is expected
 	}{
 		{
 			name: "return post when valid ID provided",
@@ -155,7 +353,7 @@ func TestPostUseCase_GetPost(t *testing.T) {
 					UpdatedAt: fakeTime,
 				}
 
-				mockRepo.EXPECT().Get(context.Background(), "post-123").Return(expectedPost, nil).Once()
+				mockRepo.EXPECT().Get(mock.Anything, "post-123").Return(expectedPost, nil).Once()
 
 				return dep{
 					postRepo: mockRepo,
@@ -169,7 +367,6 @@ func TestPostUseCase_GetPost(t *testing.T) {
 				CreatedAt: fakeTime,
 				UpdatedAt: fakeTime,
 			},
-			wantErr: nil,
 		},
 		{
 			name: "return error when empty ID provided",
@@ -188,8 +385,8 @@ func TestPostUseCase_GetPost(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			want:    nil,
-			wantErr: apperr.ErrInvalidArgument,
+			want:     nil,
+			wantCode: codes.InvalidArgument,
 		},
 		{
 			name: "return error when repository fails",
@@ -201,15 +398,15 @@ func TestPostUseCase_GetPost(t *testing.T) {
 				mockRepo := entity.NewMockPostRepository(t)
 				logger := logging.New()
 
-				mockRepo.EXPECT().Get(context.Background(), "post-123").Return(nil, apperr.New(codes.NotFound, "post not found")).Once()
+				mockRepo.EXPECT().Get(mock.Anything, "post-123").Return(nil, apperr.New(codes.NotFound, "post not found")).Once()
 
 				return dep{
 					postRepo: mockRepo,
 					logger:   logger,
 				}
 			},
-			want:    nil,
-			wantErr: apperr.ErrNotFound,
+			want:     nil,
+			wantCode: codes.NotFound,
 		},
 	}
 
@@ -220,11 +417,11 @@ func TestPostUseCase_GetPost(t *testing.T) {
 
 			got, err := uc.GetPost(tt.args.ctx, tt.args.id)
 
-			if tt.wantErr != nil {
+			if tt.wantCode != 0 {
 				assert.Error(t, err)
 				assert.Nil(t, got)
 
-				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -245,10 +442,10 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		dep     func() dep
-		wantErr error
+		name     string
+		args     args
+		dep      func() dep
+		wantCode codes.Code // zero if no error is expected
 	}{
 		{
 			name: "return nil when valid ID provided",
@@ -267,7 +464,6 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			wantErr: nil,
 		},
 		{
 			name: "return error when empty ID provided",
@@ -286,7 +482,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			wantErr: apperr.ErrInvalidArgument,
+			wantCode: codes.InvalidArgument,
 		},
 		{
 			name: "return error when repository fails",
@@ -305,7 +501,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			wantErr: apperr.ErrInternal,
+			wantCode: codes.Internal,
 		},
 	}
 
@@ -316,10 +512,10 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 
 			err := uc.DeletePost(tt.args.ctx, tt.args.id)
 
-			if tt.wantErr != nil {
+			if tt.wantCode != 0 {
 				assert.Error(t, err)
 
-				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
 			} else {
 				assert.NoError(t, err)
 			}
@@ -327,6 +523,265 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 	}
 }
 
+func TestPostUseCase_ListPosts(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		params *entity.ListParams
+	}
+
+	type dep struct {
+		postRepo *entity.MockPostRepository
+		logger   *logging.Logger
+	}
+
+	tests := []struct {
+		name           string
+		args           args
+		dep            func() dep
+		want           []*entity.Post
+		wantNextCursor string
+		wantCode       codes.Code // zero if no error is expected
+	}{
+		{
+			name: "return page and next cursor when more posts exist",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{Limit: 1},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{Limit: 1}).
+					Return([]*entity.Post{{ID: "post-123"}}, "post-123", nil).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.Post{{ID: "post-123"}},
+			wantNextCursor: "post-123",
+		},
+		{
+			name: "return empty slice when no posts match",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return([]*entity.Post{}, "", nil).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.Post{},
+			wantNextCursor: "",
+		},
+		{
+			name: "return empty slice when repository returns nil",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", nil).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.Post{},
+			wantNextCursor: "",
+		},
+		{
+			name: "return empty slice instead of NotFound",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", apperr.New(codes.NotFound, "no posts found")).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.Post{},
+			wantNextCursor: "",
+		},
+		{
+			name: "return error when cursor is invalid",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{Cursor: "invalid"},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{Cursor: "invalid"}).
+					Return(nil, "", apperr.New(codes.InvalidArgument, "invalid cursor")).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:     nil,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "return error when repository fails",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockPostRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", errors.New("connection refused")).Once()
+
+				return dep{
+					postRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:     nil,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.dep()
+			uc := usecase.NewPostUseCase(d.postRepo, d.logger)
+
+			got, gotNextCursor, err := uc.ListPosts(tt.args.ctx, tt.args.params)
+
+			if tt.wantCode != 0 {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantNextCursor, gotNextCursor)
+			}
+		})
+	}
+}
+
+func TestPostUseCase_StreamPosts(t *testing.T) {
+	t.Parallel()
+
+	pages := func(mockRepo *entity.MockPostRepository) {
+		mockRepo.EXPECT().List(mock.Anything, &entity.ListParams{Limit: 2}).
+			Return([]*entity.Post{{ID: "post-1"}, {ID: "post-2"}}, "post-2", nil).Maybe()
+		mockRepo.EXPECT().List(mock.Anything, &entity.ListParams{Limit: 2, Cursor: "post-2"}).
+			Return([]*entity.Post{{ID: "post-3"}}, "", nil).Maybe()
+	}
+
+	tests := []struct {
+		name     string
+		repo     func(mockRepo *entity.MockPostRepository)
+		cancelAt int // number of posts sent before the stream is canceled, zero to never cancel
+		sendErr  error
+		wantIDs  []string
+		wantCode codes.Code // zero if no error is expected
+	}{
+		{
+			name:    "send all posts across pages",
+			repo:    pages,
+			wantIDs: []string{"post-1", "post-2", "post-3"},
+		},
+		{
+			name:     "stop sending when canceled mid-page",
+			repo:     pages,
+			cancelAt: 1,
+			wantIDs:  []string{"post-1"},
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "stop before the next page when canceled at a page boundary",
+			repo:     pages,
+			cancelAt: 2,
+			wantIDs:  []string{"post-1", "post-2"},
+			wantCode: codes.Canceled,
+		},
+		{
+			name:    "return send error as is",
+			repo:    pages,
+			sendErr: errors.New("stream closed"),
+			wantIDs: []string{"post-1"},
+		},
+		{
+			name: "return internal error when listing fails",
+			repo: func(mockRepo *entity.MockPostRepository) {
+				mockRepo.EXPECT().List(mock.Anything, &entity.ListParams{Limit: 2}).
+					Return(nil, "", errors.New("database error")).Once()
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := entity.NewMockPostRepository(t)
+			tt.repo(mockRepo)
+
+			uc := usecase.NewPostUseCase(mockRepo, logging.New())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var gotIDs []string
+
+			err := uc.StreamPosts(ctx, 2, func(post *entity.Post) error {
+				gotIDs = append(gotIDs, post.ID)
+
+				if len(gotIDs) == tt.cancelAt {
+					cancel()
+				}
+
+				return tt.sendErr
+			})
+
+			assert.Equal(t, tt.wantIDs, gotIDs)
+
+			switch {
+			case tt.sendErr != nil:
+				assert.ErrorIs(t, err, tt.sendErr)
+			case tt.wantCode != 0:
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "got error %v, want code %v", err, tt.wantCode)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestNewPostUseCase(t *testing.T) {
 	type args struct {
 		postRepo entity.PostRepository
@@ -356,3 +811,62 @@ func TestNewPostUseCase(t *testing.T) {
 		})
 	}
 }
+
+func TestPostUseCase_CallTimeout(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := entity.NewMockPostRepository(t)
+	mockRepo.EXPECT().Delete(mock.Anything, "post-123").RunAndReturn(func(ctx context.Context, _ string) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}).Once()
+
+	uc := usecase.NewPostUseCase(mockRepo, logging.New(), usecase.WithCallTimeout(20*time.Millisecond))
+
+	err := uc.DeletePost(context.Background(), "post-123")
+
+	assert.True(t, apperr.IsCode(err, codes.DeadlineExceeded), "want DeadlineExceeded, got %v", err)
+}
+
+func TestPostUseCase_CreatePost_BoundsNotifications(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := entity.NewMockPostRepository(t)
+	mockRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(&entity.Post{ID: "post-456", UserID: "user-123"}, nil).Times(2)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	var notified atomic.Int32
+
+	uc := usecase.NewPostUseCase(mockRepo, logging.New(),
+		usecase.WithClock(clock.NewFake(fakeTime)),
+		usecase.WithMaxPendingNotifications(1),
+		usecase.WithNotifier(notifierFunc(func(context.Context, *entity.Post) error {
+			started <- struct{}{}
+			<-release
+			notified.Add(1)
+			return nil
+		})),
+	)
+
+	_, err := uc.CreatePost(context.Background(), &entity.NewPost{Title: "First Post", UserID: "user-123"})
+	require.NoError(t, err)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("expected notifier to be called")
+	}
+
+	// The first notification is still in flight, so the second is dropped without blocking the create
+	_, err = uc.CreatePost(context.Background(), &entity.NewPost{Title: "Second Post", UserID: "user-123"})
+	require.NoError(t, err)
+
+	close(release)
+
+	// Close must wait for the in-flight notification
+	require.NoError(t, uc.Close())
+	assert.Equal(t, int32(1), notified.Load())
+}
@@ -0,0 +1,152 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/internal/usecase"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// TestUseCase_Spans is not parallel because it replaces the global tracer provider.
+func TestUseCase_Spans(t *testing.T) {
+	errRepo := errors.New("repository error")
+
+	tests := []struct {
+		name       string
+		call       func(userRepo *entity.MockUserRepository, postRepo *entity.MockPostRepository) error
+		wantSpan   string
+		wantAttrs  []attribute.KeyValue
+		wantStatus otelcodes.Code
+	}{
+		{
+			name: "record span for CreateUser",
+			call: func(userRepo *entity.MockUserRepository, _ *entity.MockPostRepository) error {
+				userRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(&entity.User{ID: "user-123"}, nil).Once()
+
+				_, err := usecase.NewUserUseCase(userRepo, logging.New()).CreateUser(context.Background(), &entity.NewUser{
+					Name:  "John Doe",
+					Email: "john@example.com",
+				})
+
+				return err
+			},
+			wantSpan:   "UserUseCase.CreateUser",
+			wantAttrs:  []attribute.KeyValue{attribute.String(attr.UserIDKey, "user-123")},
+			wantStatus: otelcodes.Unset,
+		},
+		{
+			name: "record span for GetUser",
+			call: func(userRepo *entity.MockUserRepository, _ *entity.MockPostRepository) error {
+				userRepo.EXPECT().Get(mock.Anything, "user-123").Return(&entity.User{ID: "user-123"}, nil).Once()
+
+				_, err := usecase.NewUserUseCase(userRepo, logging.New()).GetUser(context.Background(), "user-123")
+
+				return err
+			},
+			wantSpan:   "UserUseCase.GetUser",
+			wantAttrs:  []attribute.KeyValue{attribute.String(attr.UserIDKey, "user-123")},
+			wantStatus: otelcodes.Unset,
+		},
+		{
+			name: "record error on span when GetUser fails",
+			call: func(userRepo *entity.MockUserRepository, _ *entity.MockPostRepository) error {
+				userRepo.EXPECT().Get(mock.Anything, "user-123").Return(nil, errRepo).Once()
+
+				_, err := usecase.NewUserUseCase(userRepo, logging.New()).GetUser(context.Background(), "user-123")
+
+				return err
+			},
+			wantSpan:   "UserUseCase.GetUser",
+			wantAttrs:  []attribute.KeyValue{attribute.String(attr.UserIDKey, "user-123")},
+			wantStatus: otelcodes.Error,
+		},
+		{
+			name: "record span for CreatePost",
+			call: func(_ *entity.MockUserRepository, postRepo *entity.MockPostRepository) error {
+				postRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(&entity.Post{ID: "post-123", UserID: "user-123"}, nil).Once()
+
+				_, err := usecase.NewPostUseCase(postRepo, logging.New()).CreatePost(context.Background(), &entity.NewPost{
+					Title:  "Test Post",
+					UserID: "user-123",
+				})
+
+				return err
+			},
+			wantSpan: "PostUseCase.CreatePost",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String(attr.UserIDKey, "user-123"),
+				attribute.String(attr.PostIDKey, "post-123"),
+			},
+			wantStatus: otelcodes.Unset,
+		},
+		{
+			name: "record span for GetPost",
+			call: func(_ *entity.MockUserRepository, postRepo *entity.MockPostRepository) error {
+				postRepo.EXPECT().Get(mock.Anything, "post-123").Return(&entity.Post{ID: "post-123"}, nil).Once()
+
+				_, err := usecase.NewPostUseCase(postRepo, logging.New()).GetPost(context.Background(), "post-123")
+
+				return err
+			},
+			wantSpan:   "PostUseCase.GetPost",
+			wantAttrs:  []attribute.KeyValue{attribute.String(attr.PostIDKey, "post-123")},
+			wantStatus: otelcodes.Unset,
+		},
+		{
+			name: "record error on span when GetPost is given an empty ID",
+			call: func(_ *entity.MockUserRepository, postRepo *entity.MockPostRepository) error {
+				_, err := usecase.NewPostUseCase(postRepo, logging.New()).GetPost(context.Background(), "")
+
+				return err
+			},
+			wantSpan:   "PostUseCase.GetPost",
+			wantAttrs:  []attribute.KeyValue{attribute.String(attr.PostIDKey, "")},
+			wantStatus: otelcodes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			prev := otel.GetTracerProvider()
+			otel.SetTracerProvider(provider)
+
+			t.Cleanup(func() {
+				otel.SetTracerProvider(prev)
+				_ = provider.Shutdown(context.Background())
+			})
+
+			err := tt.call(entity.NewMockUserRepository(t), entity.NewMockPostRepository(t))
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+
+			span := spans[0]
+			assert.Equal(t, tt.wantSpan, span.Name())
+			assert.Equal(t, tt.wantAttrs, span.Attributes())
+			assert.Equal(t, tt.wantStatus, span.Status().Code)
+
+			if tt.wantStatus == otelcodes.Error {
+				assert.Error(t, err)
+				assert.NotEmpty(t, span.Events(), "expected the error to be recorded as a span event")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -4,72 +4,144 @@ package usecase
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // UserUseCase handles user business logic.
 type UserUseCase struct {
-	userRepo entity.UserRepository
-	logger   *logging.Logger
+	userRepo  entity.UserRepository
+	logger    *logging.Logger
+	clock     clock.Clock
+	publisher EventPublisher
+	timeout   time.Duration
+
+	validators []Validator[entity.NewUser]
 }
 
 // NewUserUseCase creates a new user use case.
-func NewUserUseCase(userRepo entity.UserRepository, logger *logging.Logger) *UserUseCase {
+func NewUserUseCase(userRepo entity.UserRepository, logger *logging.Logger, opts ...Option) *UserUseCase {
+	o := newOptions(opts)
+
 	return &UserUseCase{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:  userRepo,
+		logger:    logger,
+		clock:     o.clock,
+		publisher: o.publisher,
+		timeout:   o.timeout,
+
+		validators: o.userValidators,
 	}
 }
 
-// CreateUser creates a new user.
+// CreateUser validates params, creates a new user, and publishes an entity.UserCreated event.
+// Invalid params return codes.InvalidArgument with an entity.Reason in the "reason" attribute,
+// and params rejected by validators of WithUserValidators return it with all their field violations.
 func (uc *UserUseCase) CreateUser(ctx context.Context, params *entity.NewUser) (*entity.User, error) {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	ctx, span := telemetry.StartSpan(ctx, "UserUseCase.CreateUser")
+	defer span.End()
+
+	if err := validateNewUser(params); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	if err := runValidators(ctx, "user", uc.validators, params); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
 	user, err := uc.userRepo.Create(ctx, params)
 	if err != nil {
-		return nil, apperr.Wrap(err, codes.Internal, "failed to create user", 
+		telemetry.RecordError(span, err)
+
+		return nil, apperr.Wrap(err, errorCode(ctx, err, codes.Internal), "failed to create user", 
 			slog.String("name", params.Name),
-			slog.String("email", params.Email),
 		)
 	}
 
-	uc.logger.Info(ctx, "User created successfully", slog.String("user_id", user.ID))
+	stampTimestamps(&user.CreatedAt, &user.UpdatedAt, uc.clock)
+
+	span.SetAttributes(attribute.String(attr.UserIDKey, user.ID))
+
+	uc.logger.Info(ctx, "User created successfully", attr.UserID(user.ID))
+
+	// Published only once the user is stored, so subscribers never see a user that does not exist
+	uc.publisher.Publish(ctx, entity.UserCreated{User: user})
 
 	return user, nil
 }
 
 // GetUser retrieves a user by ID.
 func (uc *UserUseCase) GetUser(ctx context.Context, id string) (*entity.User, error) {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	ctx, span := telemetry.StartSpan(ctx, "UserUseCase.GetUser")
+	defer span.End()
+
+	span.SetAttributes(attribute.String(attr.UserIDKey, id))
+
 	if id == "" {
-		return nil, apperr.New(codes.InvalidArgument, "user ID cannot be empty")
+		err := apperr.New(codes.InvalidArgument, "user ID cannot be empty")
+		telemetry.RecordError(span, err)
+
+		return nil, err
 	}
 
 	user, err := uc.userRepo.Get(ctx, id)
 	if err != nil {
-		return nil, apperr.Wrap(err, codes.NotFound, "failed to get user", 
-			slog.String("user_id", id),
+		telemetry.RecordError(span, err)
+
+		return nil, apperr.Wrap(err, errorCode(ctx, err, codes.NotFound), "failed to get user", 
+			attr.UserID(id),
 		)
 	}
 
 	return user, nil
 }
 
+// ListUsers retrieves a page of users and the cursor of the next page.
+// An empty result is not an error and never returns codes.NotFound.
+func (uc *UserUseCase) ListUsers(ctx context.Context, params *entity.ListParams) ([]*entity.User, string, error) {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	users, nextCursor, err := uc.userRepo.List(ctx, params)
+
+	return listResult(ctx, users, nextCursor, err, "failed to list users")
+}
+
 // DeleteUser deletes a user by ID.
 func (uc *UserUseCase) DeleteUser(ctx context.Context, id string) error {
+	ctx, cancel := withCallTimeout(ctx, uc.timeout)
+	defer cancel()
+
 	if id == "" {
 		return apperr.New(codes.InvalidArgument, "user ID cannot be empty")
 	}
 
 	err := uc.userRepo.Delete(ctx, id)
 	if err != nil {
-		return apperr.Wrap(err, codes.Internal, "failed to delete user", 
-			slog.String("user_id", id),
+		return apperr.Wrap(err, errorCode(ctx, err, codes.Internal), "failed to delete user", 
+			attr.UserID(id),
 		)
 	}
 
-	uc.logger.Info(ctx, "User deleted successfully", slog.String("user_id", id))
+	uc.logger.Info(ctx, "User deleted successfully", attr.UserID(id))
 
 	return nil
 }
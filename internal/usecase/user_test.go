@@ -2,15 +2,21 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/pannpers/go-backend-scaffold/internal/entity"
 	"github.com/pannpers/go-backend-scaffold/internal/usecase"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
 )
 
@@ -28,11 +34,11 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		dep     func() dep
-		want    *entity.User
-		wantErr error
+		name     string
+		args     args
+		dep      func() dep
+		want     *entity.User
+		wantCode codes.Code // zero if no error is expected
 	}{
 		{
 			name: "return created user when valid input provided",
@@ -55,7 +61,7 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 					UpdatedAt: fakeTime,
 				}
 
-				mockRepo.EXPECT().Create(context.Background(), &entity.NewUser{
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewUser{
 					Name:  "John Doe",
 					Email: "john@example.com",
 				}).Return(expectedUser, nil).Once()
@@ -72,7 +78,41 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 				CreatedAt: fakeTime,
 				UpdatedAt: fakeTime,
 			},
-			wantErr: nil,
+		},
+		{
+			name: "stamp timestamps from clock when repository leaves them zero",
+			args: args{
+				ctx: context.Background(),
+				params: &entity.NewUser{
+					Name:  "John Doe",
+					Email: "john@example.com",
+				},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewUser{
+					Name:  "John Doe",
+					Email: "john@example.com",
+				}).Return(&entity.User{
+					ID:    "user-123",
+					Name:  "John Doe",
+					Email: "john@example.com",
+				}, nil).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want: &entity.User{
+				ID:        "user-123",
+				Name:      "John Doe",
+				Email:     "john@example.com",
+				CreatedAt: fakeTime,
+				UpdatedAt: fakeTime,
+			},
 		},
 		{
 			name: "return error when repository fails",
@@ -87,7 +127,7 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 				mockRepo := entity.NewMockUserRepository(t)
 				logger := logging.New()
 
-				mockRepo.EXPECT().Create(context.Background(), &entity.NewUser{
+				mockRepo.EXPECT().Create(mock.Anything, &entity.NewUser{
 					Name:  "Jane Doe",
 					Email: "jane@example.com",
 				}).Return(nil, apperr.New(codes.Internal, "failed to create user")).Once()
@@ -97,23 +137,23 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			want:    nil,
-			wantErr: apperr.ErrInternal,
+			want:     nil,
+			wantCode: codes.Internal,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			d := tt.dep()
-			uc := usecase.NewUserUseCase(d.userRepo, d.logger)
+			uc := usecase.NewUserUseCase(d.userRepo, d.logger, usecase.WithClock(clock.NewFake(fakeTime)))
 
 			got, err := uc.CreateUser(tt.args.ctx, tt.args.params)
 
-			if tt.wantErr != nil {
+			if tt.wantCode != 0 {
 				assert.Error(t, err)
 				assert.Nil(t, got)
 
-				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -122,6 +162,86 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 	}
 }
 
+func TestUserUseCase_CreateUser_PublishesEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantEvents []event.Event
+	}{
+		{
+			name:       "publish UserCreated after user is created",
+			repoErr:    nil,
+			wantEvents: []event.Event{entity.UserCreated{User: &entity.User{ID: "user-123", CreatedAt: fakeTime, UpdatedAt: fakeTime}}},
+		},
+		{
+			name:       "publish nothing when create fails",
+			repoErr:    errors.New("database error"),
+			wantEvents: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := entity.NewMockUserRepository(t)
+			if tt.repoErr != nil {
+				mockRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil, tt.repoErr).Once()
+			} else {
+				mockRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(&entity.User{ID: "user-123"}, nil).Once()
+			}
+
+			bus := event.NewBus(logging.New())
+
+			var (
+				mu     sync.Mutex
+				events []event.Event
+			)
+
+			bus.Subscribe(entity.UserCreatedEvent, func(_ context.Context, e event.Event) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				events = append(events, e)
+			})
+
+			uc := usecase.NewUserUseCase(mockRepo, logging.New(),
+				usecase.WithClock(clock.NewFake(fakeTime)),
+				usecase.WithEventPublisher(bus),
+			)
+
+			_, _ = uc.CreateUser(context.Background(), &entity.NewUser{Name: "John Doe", Email: "john@example.com"})
+
+			require.NoError(t, bus.Close())
+
+			assert.Equal(t, tt.wantEvents, events)
+		})
+	}
+}
+
+func TestUserUseCase_CreateUser_DuplicateEmail(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := entity.NewMockUserRepository(t)
+	mockRepo.EXPECT().Create(mock.Anything, mock.Anything).
+		Return(nil, apperr.New(codes.AlreadyExists, "user with this email already exists")).Once()
+
+	uc := usecase.NewUserUseCase(mockRepo, logging.New())
+
+	_, err := uc.CreateUser(context.Background(), &entity.NewUser{Name: "John Doe", Email: "John@Example.com"})
+	require.Error(t, err)
+	assert.True(t, apperr.IsCode(err, codes.AlreadyExists), "want AlreadyExists, got %v", err)
+
+	// Attributes are sent to clients as metadata, so the email must not be among them
+	code, _, meta := apperr.ParseConnectError(clientError(t, err))
+
+	assert.Equal(t, codes.AlreadyExists, code)
+	assert.NotContains(t, meta, "email")
+	assert.False(t, apperr.HasAttr(err, "email", "John@Example.com"))
+}
+
 func TestUserUseCase_GetUser(t *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -134,11 +254,11 @@ func TestUserUseCase_GetUser(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		dep     func() dep
-		want    *entity.User
-		wantErr error
+		name     string
+		args     args
+		dep      func() dep
+		want     *entity.User
+		wantCode codes.Code // zero if no error is expected
 	}{
 		{
 			name: "return user when valid ID provided",
@@ -158,7 +278,7 @@ func TestUserUseCase_GetUser(t *testing.T) {
 					UpdatedAt: fakeTime,
 				}
 
-				mockRepo.EXPECT().Get(context.Background(), "user-123").Return(expectedUser, nil).Once()
+				mockRepo.EXPECT().Get(mock.Anything, "user-123").Return(expectedUser, nil).Once()
 
 				return dep{
 					userRepo: mockRepo,
@@ -172,7 +292,6 @@ func TestUserUseCase_GetUser(t *testing.T) {
 				CreatedAt: fakeTime,
 				UpdatedAt: fakeTime,
 			},
-			wantErr: nil,
 		},
 		{
 			name: "return error when empty ID provided",
@@ -191,8 +310,8 @@ func TestUserUseCase_GetUser(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			want:    nil,
-			wantErr: apperr.ErrInvalidArgument,
+			want:     nil,
+			wantCode: codes.InvalidArgument,
 		},
 		{
 			name: "return error when repository fails",
@@ -204,15 +323,36 @@ func TestUserUseCase_GetUser(t *testing.T) {
 				mockRepo := entity.NewMockUserRepository(t)
 				logger := logging.New()
 
-				mockRepo.EXPECT().Get(context.Background(), "user-123").Return(nil, apperr.New(codes.NotFound, "user not found")).Once()
+				mockRepo.EXPECT().Get(mock.Anything, "user-123").Return(nil, apperr.New(codes.NotFound, "user not found")).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:     nil,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "keep Unavailable when database circuit is open",
+			args: args{
+				ctx: context.Background(),
+				id:  "user-123",
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().Get(mock.Anything, "user-123").
+					Return(nil, apperr.New(codes.Unavailable, "database circuit open")).Once()
 
 				return dep{
 					userRepo: mockRepo,
 					logger:   logger,
 				}
 			},
-			want:    nil,
-			wantErr: apperr.ErrNotFound,
+			want:     nil,
+			wantCode: codes.Unavailable,
 		},
 	}
 
@@ -223,11 +363,11 @@ func TestUserUseCase_GetUser(t *testing.T) {
 
 			got, err := uc.GetUser(tt.args.ctx, tt.args.id)
 
-			if tt.wantErr != nil {
+			if tt.wantCode != 0 {
 				assert.Error(t, err)
 				assert.Nil(t, got)
 
-				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -248,10 +388,10 @@ func TestUserUseCase_DeleteUser(t *testing.T) {
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		dep     func() dep
-		wantErr error
+		name     string
+		args     args
+		dep      func() dep
+		wantCode codes.Code // zero if no error is expected
 	}{
 		{
 			name: "return nil when valid ID provided",
@@ -270,7 +410,6 @@ func TestUserUseCase_DeleteUser(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			wantErr: nil,
 		},
 		{
 			name: "return error when empty ID provided",
@@ -289,7 +428,7 @@ func TestUserUseCase_DeleteUser(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			wantErr: apperr.ErrInvalidArgument,
+			wantCode: codes.InvalidArgument,
 		},
 		{
 			name: "return error when repository fails",
@@ -308,7 +447,7 @@ func TestUserUseCase_DeleteUser(t *testing.T) {
 					logger:   logger,
 				}
 			},
-			wantErr: apperr.ErrInternal,
+			wantCode: codes.Internal,
 		},
 	}
 
@@ -319,10 +458,10 @@ func TestUserUseCase_DeleteUser(t *testing.T) {
 
 			err := uc.DeleteUser(tt.args.ctx, tt.args.id)
 
-			if tt.wantErr != nil {
+			if tt.wantCode != 0 {
 				assert.Error(t, err)
 
-				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
 			} else {
 				assert.NoError(t, err)
 			}
@@ -330,6 +469,195 @@ func TestUserUseCase_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestUserUseCase_ListUsers(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		params *entity.ListParams
+	}
+
+	type dep struct {
+		userRepo *entity.MockUserRepository
+		logger   *logging.Logger
+	}
+
+	tests := []struct {
+		name           string
+		args           args
+		dep            func() dep
+		want           []*entity.User
+		wantNextCursor string
+		wantCode       codes.Code // zero if no error is expected
+	}{
+		{
+			name: "return page and next cursor when more users exist",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{Limit: 1},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{Limit: 1}).
+					Return([]*entity.User{{ID: "user-123"}}, "user-123", nil).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.User{{ID: "user-123"}},
+			wantNextCursor: "user-123",
+		},
+		{
+			name: "return empty slice when no users match",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return([]*entity.User{}, "", nil).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.User{},
+			wantNextCursor: "",
+		},
+		{
+			name: "return empty slice when repository returns nil",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", nil).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.User{},
+			wantNextCursor: "",
+		},
+		{
+			name: "return empty slice instead of NotFound",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", apperr.New(codes.NotFound, "no users found")).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:           []*entity.User{},
+			wantNextCursor: "",
+		},
+		{
+			name: "return error when cursor is invalid",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{Cursor: "invalid"},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{Cursor: "invalid"}).
+					Return(nil, "", apperr.New(codes.InvalidArgument, "invalid cursor")).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:     nil,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "return error when repository fails",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", errors.New("connection refused")).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:     nil,
+			wantCode: codes.Internal,
+		},
+		{
+			name: "keep Unavailable when database circuit is open",
+			args: args{
+				ctx:    context.Background(),
+				params: &entity.ListParams{},
+			},
+			dep: func() dep {
+				mockRepo := entity.NewMockUserRepository(t)
+				logger := logging.New()
+
+				mockRepo.EXPECT().List(context.Background(), &entity.ListParams{}).
+					Return(nil, "", apperr.New(codes.Unavailable, "database circuit open")).Once()
+
+				return dep{
+					userRepo: mockRepo,
+					logger:   logger,
+				}
+			},
+			want:     nil,
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.dep()
+			uc := usecase.NewUserUseCase(d.userRepo, d.logger)
+
+			got, gotNextCursor, err := uc.ListUsers(tt.args.ctx, tt.args.params)
+
+			if tt.wantCode != 0 {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+
+				assert.True(t, apperr.IsCode(err, tt.wantCode), "want code %v, got %v", tt.wantCode, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantNextCursor, gotNextCursor)
+			}
+		})
+	}
+}
+
 func TestNewUserUseCase(t *testing.T) {
 	type args struct {
 		userRepo entity.UserRepository
@@ -359,3 +687,118 @@ func TestNewUserUseCase(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUseCase_CallTimeout(t *testing.T) {
+	t.Parallel()
+
+	// slowGet blocks until the call is canceled, like a repository waiting on a stuck database
+	slowGet := func(ctx context.Context, _ string) (*entity.User, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		call     func(ctx context.Context, uc *usecase.UserUseCase) error
+		setup    func(m *entity.MockUserRepository)
+		wantCode codes.Code
+	}{
+		{
+			name:    "return DeadlineExceeded when get exceeds the timeout",
+			timeout: 20 * time.Millisecond,
+			call: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				_, err := uc.GetUser(ctx, "user-123")
+				return err
+			},
+			setup: func(m *entity.MockUserRepository) {
+				m.EXPECT().Get(mock.Anything, "user-123").RunAndReturn(slowGet).Once()
+			},
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name:    "return DeadlineExceeded when list exceeds the timeout",
+			timeout: 20 * time.Millisecond,
+			call: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				_, _, err := uc.ListUsers(ctx, &entity.ListParams{})
+				return err
+			},
+			setup: func(m *entity.MockUserRepository) {
+				m.EXPECT().List(mock.Anything, &entity.ListParams{}).RunAndReturn(
+					func(ctx context.Context, _ *entity.ListParams) ([]*entity.User, string, error) {
+						<-ctx.Done()
+
+						return nil, "", ctx.Err()
+					},
+				).Once()
+			},
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name:    "return DeadlineExceeded when the database cancels the statement",
+			timeout: time.Second,
+			call: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				_, err := uc.GetUser(ctx, "user-123")
+				return err
+			},
+			setup: func(m *entity.MockUserRepository) {
+				// The repository reports a statement_timeout this way while the call's context is still live
+				m.EXPECT().Get(mock.Anything, "user-123").Return(nil, apperr.Wrap(
+					errors.New("ERROR: canceling statement due to statement timeout (SQLSTATE=57014)"),
+					codes.DeadlineExceeded, "failed to get user: statement timeout exceeded",
+				)).Once()
+			},
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name:    "return result when call completes within the timeout",
+			timeout: time.Second,
+			call: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				_, err := uc.GetUser(ctx, "user-123")
+				return err
+			},
+			setup: func(m *entity.MockUserRepository) {
+				m.EXPECT().Get(mock.Anything, "user-123").Return(&entity.User{ID: "user-123"}, nil).Once()
+			},
+		},
+		{
+			name:    "apply no deadline without timeout",
+			timeout: 0,
+			call: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				_, err := uc.GetUser(ctx, "user-123")
+				return err
+			},
+			setup: func(m *entity.MockUserRepository) {
+				m.EXPECT().Get(mock.Anything, "user-123").RunAndReturn(func(ctx context.Context, _ string) (*entity.User, error) {
+					if _, ok := ctx.Deadline(); ok {
+						return nil, errors.New("unexpected deadline")
+					}
+
+					return &entity.User{ID: "user-123"}, nil
+				}).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := entity.NewMockUserRepository(t)
+			tt.setup(mockRepo)
+
+			uc := usecase.NewUserUseCase(mockRepo, logging.New(), usecase.WithCallTimeout(tt.timeout))
+
+			err := tt.call(context.Background(), uc)
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.True(t, apperr.IsCode(err, tt.wantCode), "want %v, got %v", tt.wantCode, err)
+		})
+	}
+}
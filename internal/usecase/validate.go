@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// invalidField returns an InvalidArgument error for an invalid request field.
+// The reason is sent to clients in the "reason" metadata and the field as a field violation.
+func invalidField(field string, reason entity.Reason, description string) error {
+	return apperr.NewInvalidArgument(fmt.Sprintf("invalid %s: %s", field, description),
+		[]apperr.FieldViolation{{Field: field, Description: description}},
+		attr.Reason(string(reason)),
+	)
+}
+
+// Validator checks params of type T against business rules that the request schema cannot express,
+// e.g. that a post title must not contain URLs. It returns the violations it finds, or none if params are valid.
+// Register validators with WithUserValidators and WithPostValidators.
+type Validator[T any] interface {
+	Validate(ctx context.Context, params *T) []apperr.FieldViolation
+}
+
+// ValidatorFunc adapts a function to a Validator.
+type ValidatorFunc[T any] func(ctx context.Context, params *T) []apperr.FieldViolation
+
+// Validate calls f(ctx, params).
+func (f ValidatorFunc[T]) Validate(ctx context.Context, params *T) []apperr.FieldViolation {
+	return f(ctx, params)
+}
+
+// runValidators runs every validator on params and returns a single InvalidArgument error
+// with the violations of all of them, or nil if there are none.
+func runValidators[T any](ctx context.Context, entityName string, validators []Validator[T], params *T) error {
+	var violations []apperr.FieldViolation
+
+	for _, v := range validators {
+		violations = append(violations, v.Validate(ctx, params)...)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(violations))
+	for i, violation := range violations {
+		descriptions[i] = fmt.Sprintf("%s: %s", violation.Field, violation.Description)
+	}
+
+	return apperr.NewInvalidArgument(
+		fmt.Sprintf("invalid %s: %s", entityName, strings.Join(descriptions, "; ")),
+		violations,
+	)
+}
+
+// validateNewUser validates the parameters for creating a user.
+func validateNewUser(params *entity.NewUser) error {
+	if params == nil {
+		return apperr.New(codes.InvalidArgument, "params cannot be nil")
+	}
+
+	switch {
+	case strings.TrimSpace(params.Name) == "":
+		return invalidField("name", entity.ReasonNameRequired, "must not be empty")
+	case utf8.RuneCountInString(params.Name) > entity.MaxUserNameLength:
+		return invalidField("name", entity.ReasonNameTooLong,
+			fmt.Sprintf("must be at most %d characters", entity.MaxUserNameLength),
+		)
+	}
+
+	switch {
+	case params.Email == "":
+		return invalidField("email", entity.ReasonEmailRequired, "must not be empty")
+	case utf8.RuneCountInString(params.Email) > entity.MaxUserEmailLength:
+		return invalidField("email", entity.ReasonEmailTooLong,
+			fmt.Sprintf("must be at most %d characters", entity.MaxUserEmailLength),
+		)
+	case !isEmailAddress(params.Email):
+		return invalidField("email", entity.ReasonEmailInvalid, "must be a valid email address")
+	}
+
+	return nil
+}
+
+// validateNewPost validates the parameters for creating a post.
+func validateNewPost(params *entity.NewPost) error {
+	if params == nil {
+		return apperr.New(codes.InvalidArgument, "params cannot be nil")
+	}
+
+	switch {
+	case strings.TrimSpace(params.Title) == "":
+		return invalidField("title", entity.ReasonTitleRequired, "must not be empty")
+	case utf8.RuneCountInString(params.Title) > entity.MaxPostTitleLength:
+		return invalidField("title", entity.ReasonTitleTooLong,
+			fmt.Sprintf("must be at most %d characters", entity.MaxPostTitleLength),
+		)
+	}
+
+	if params.UserID == "" {
+		return invalidField("user_id", entity.ReasonAuthorRequired, "must not be empty")
+	}
+
+	return nil
+}
+
+// isEmailAddress reports whether s is a bare email address such as "john@example.com",
+// without a display name or angle brackets.
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+
+	return err == nil && addr.Address == s
+}
@@ -0,0 +1,309 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/internal/entity"
+	"github.com/pannpers/go-backend-scaffold/internal/usecase"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// clientError returns the error a Connect client receives when a handler returns err.
+func clientError(t *testing.T, err error) error {
+	t.Helper()
+
+	const procedure = "/test.v1.TestService/Call"
+
+	mux := http.NewServeMux()
+	mux.Handle(procedure, connect.NewUnaryHandler(procedure,
+		func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			return nil, err
+		},
+		connect.WithInterceptors(apperr.NewInterceptor(logging.New(logging.WithWriter(io.Discard)))),
+	))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+procedure)
+
+	_, clientErr := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.Error(t, clientErr)
+
+	return clientErr
+}
+
+func TestUserUseCase_CreateUser_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		params     *entity.NewUser
+		wantReason entity.Reason
+		wantField  string
+	}{
+		{
+			name:       "reject empty name",
+			params:     &entity.NewUser{Name: " ", Email: "john@example.com"},
+			wantReason: entity.ReasonNameRequired,
+			wantField:  "name",
+		},
+		{
+			name:       "reject too long name",
+			params:     &entity.NewUser{Name: strings.Repeat("a", entity.MaxUserNameLength+1), Email: "john@example.com"},
+			wantReason: entity.ReasonNameTooLong,
+			wantField:  "name",
+		},
+		{
+			name:       "reject empty email",
+			params:     &entity.NewUser{Name: "John Doe"},
+			wantReason: entity.ReasonEmailRequired,
+			wantField:  "email",
+		},
+		{
+			name:       "reject malformed email",
+			params:     &entity.NewUser{Name: "John Doe", Email: "john.example.com"},
+			wantReason: entity.ReasonEmailInvalid,
+			wantField:  "email",
+		},
+		{
+			name:       "reject email with display name",
+			params:     &entity.NewUser{Name: "John Doe", Email: "John <john@example.com>"},
+			wantReason: entity.ReasonEmailInvalid,
+			wantField:  "email",
+		},
+		{
+			name:       "reject too long email",
+			params:     &entity.NewUser{Name: "John Doe", Email: strings.Repeat("a", entity.MaxUserEmailLength) + "@example.com"},
+			wantReason: entity.ReasonEmailTooLong,
+			wantField:  "email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The mock fails the test if the repository is called
+			uc := usecase.NewUserUseCase(entity.NewMockUserRepository(t), logging.New(logging.WithWriter(io.Discard)))
+
+			_, err := uc.CreateUser(context.Background(), tt.params)
+			require.Error(t, err)
+			assert.True(t, apperr.IsCode(err, codes.InvalidArgument), "want InvalidArgument, got %v", err)
+
+			code, violations, meta := apperr.ParseConnectError(clientError(t, err))
+
+			assert.Equal(t, codes.InvalidArgument, code)
+			assert.Equal(t, string(tt.wantReason), meta["reason"])
+			require.Len(t, violations, 1)
+			assert.Equal(t, tt.wantField, violations[0].Field)
+		})
+	}
+}
+
+func TestPostUseCase_CreatePost_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		params     *entity.NewPost
+		wantReason entity.Reason
+		wantField  string
+	}{
+		{
+			name:       "reject empty title",
+			params:     &entity.NewPost{Title: "", UserID: "user-123"},
+			wantReason: entity.ReasonTitleRequired,
+			wantField:  "title",
+		},
+		{
+			name:       "reject too long title",
+			params:     &entity.NewPost{Title: strings.Repeat("a", entity.MaxPostTitleLength+1), UserID: "user-123"},
+			wantReason: entity.ReasonTitleTooLong,
+			wantField:  "title",
+		},
+		{
+			name:       "reject missing author",
+			params:     &entity.NewPost{Title: "Test Post"},
+			wantReason: entity.ReasonAuthorRequired,
+			wantField:  "user_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The mock fails the test if the repository is called
+			uc := usecase.NewPostUseCase(entity.NewMockPostRepository(t), logging.New(logging.WithWriter(io.Discard)))
+
+			_, err := uc.CreatePost(context.Background(), tt.params)
+			require.Error(t, err)
+			assert.True(t, apperr.IsCode(err, codes.InvalidArgument), "want InvalidArgument, got %v", err)
+
+			code, violations, meta := apperr.ParseConnectError(clientError(t, err))
+
+			assert.Equal(t, codes.InvalidArgument, code)
+			assert.Equal(t, string(tt.wantReason), meta["reason"])
+			require.Len(t, violations, 1)
+			assert.Equal(t, tt.wantField, violations[0].Field)
+		})
+	}
+}
+
+func TestPostUseCase_CreatePost_CheckViolation(t *testing.T) {
+	t.Parallel()
+
+	// The title check of the posts table catches what validation lets through, e.g. when the two disagree
+	mockRepo := entity.NewMockPostRepository(t)
+	mockRepo.EXPECT().Create(mock.Anything, mock.Anything).
+		Return(nil, apperr.New(codes.InvalidArgument, "post violates check constraint posts_title_not_empty",
+			attr.Constraint("posts_title_not_empty"),
+		)).Once()
+
+	uc := usecase.NewPostUseCase(mockRepo, logging.New(logging.WithWriter(io.Discard)))
+
+	_, err := uc.CreatePost(context.Background(), &entity.NewPost{Title: "Test Post", UserID: "user-123"})
+	require.Error(t, err)
+	assert.True(t, apperr.IsCode(err, codes.InvalidArgument), "want InvalidArgument, got %v", err)
+
+	code, _, meta := apperr.ParseConnectError(clientError(t, err))
+
+	assert.Equal(t, codes.InvalidArgument, code)
+	assert.Equal(t, "posts_title_not_empty", meta[attr.ConstraintKey])
+}
+
+// rejectURLsInTitle is a custom validator rejecting post titles that contain URLs.
+var rejectURLsInTitle = usecase.ValidatorFunc[entity.NewPost](
+	func(_ context.Context, params *entity.NewPost) []apperr.FieldViolation {
+		title := strings.ToLower(params.Title)
+		if strings.Contains(title, "http://") || strings.Contains(title, "https://") {
+			return []apperr.FieldViolation{{Field: "title", Description: "must not contain URLs"}}
+		}
+		return nil
+	},
+)
+
+// rejectShoutingTitle is a custom validator rejecting post titles in all caps.
+var rejectShoutingTitle = usecase.ValidatorFunc[entity.NewPost](
+	func(_ context.Context, params *entity.NewPost) []apperr.FieldViolation {
+		if params.Title == strings.ToUpper(params.Title) {
+			return []apperr.FieldViolation{{Field: "title", Description: "must not be all caps"}}
+		}
+		return nil
+	},
+)
+
+func TestPostUseCase_CreatePost_CustomValidators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		params         *entity.NewPost
+		wantViolations []apperr.FieldViolation
+	}{
+		{
+			name:   "reject title with URL",
+			params: &entity.NewPost{Title: "Read https://example.com", UserID: "user-123"},
+			wantViolations: []apperr.FieldViolation{
+				{Field: "title", Description: "must not contain URLs"},
+			},
+		},
+		{
+			name:   "reject all caps title",
+			params: &entity.NewPost{Title: "HELLO WORLD", UserID: "user-123"},
+			wantViolations: []apperr.FieldViolation{
+				{Field: "title", Description: "must not be all caps"},
+			},
+		},
+		{
+			name:   "aggregate violations of all validators",
+			params: &entity.NewPost{Title: "READ HTTPS://EXAMPLE.COM", UserID: "user-123"},
+			wantViolations: []apperr.FieldViolation{
+				{Field: "title", Description: "must not contain URLs"},
+				{Field: "title", Description: "must not be all caps"},
+			},
+		},
+		{
+			name:           "accept title passing all validators",
+			params:         &entity.NewPost{Title: "Hello world", UserID: "user-123"},
+			wantViolations: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := entity.NewMockPostRepository(t)
+			if tt.wantViolations == nil {
+				published := *tt.params
+				published.PublishedAt = &fakeTime
+
+				mockRepo.EXPECT().Create(mock.Anything, &published).
+					Return(&entity.Post{ID: "post-123", Title: tt.params.Title, UserID: tt.params.UserID}, nil).Once()
+			}
+
+			uc := usecase.NewPostUseCase(mockRepo, logging.New(logging.WithWriter(io.Discard)),
+				usecase.WithClock(clock.NewFake(fakeTime)),
+				usecase.WithPostValidators(rejectURLsInTitle, rejectShoutingTitle),
+			)
+
+			_, err := uc.CreatePost(context.Background(), tt.params)
+
+			if tt.wantViolations == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, apperr.IsCode(err, codes.InvalidArgument), "want InvalidArgument, got %v", err)
+
+			code, violations, _ := apperr.ParseConnectError(clientError(t, err))
+
+			assert.Equal(t, codes.InvalidArgument, code)
+			assert.Equal(t, tt.wantViolations, violations)
+		})
+	}
+}
+
+func TestUserUseCase_CreateUser_CustomValidators(t *testing.T) {
+	t.Parallel()
+
+	rejectExampleDomain := usecase.ValidatorFunc[entity.NewUser](
+		func(_ context.Context, params *entity.NewUser) []apperr.FieldViolation {
+			if strings.HasSuffix(params.Email, "@example.com") {
+				return []apperr.FieldViolation{{Field: "email", Description: "must not use a reserved domain"}}
+			}
+			return nil
+		},
+	)
+
+	// The mock fails the test if the repository is called
+	uc := usecase.NewUserUseCase(entity.NewMockUserRepository(t), logging.New(logging.WithWriter(io.Discard)),
+		usecase.WithUserValidators(rejectExampleDomain),
+	)
+
+	_, err := uc.CreateUser(context.Background(), &entity.NewUser{Name: "John Doe", Email: "john@example.com"})
+	require.Error(t, err)
+
+	code, violations, _ := apperr.ParseConnectError(clientError(t, err))
+
+	assert.Equal(t, codes.InvalidArgument, code)
+	assert.Equal(t, []apperr.FieldViolation{{Field: "email", Description: "must not use a reserved domain"}}, violations)
+}
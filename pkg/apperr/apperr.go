@@ -23,15 +23,31 @@
 //
 // # Error Comparison
 //
-// Use predefined error variables for semantic comparison:
+// Use IsCode to check the code an error is reported with:
 //
-//	if errors.Is(err, apperr.ErrNotFound) {
+//	if apperr.IsCode(err, codes.NotFound) {
 //		// Handle not found error
 //	}
 //
-//	if errors.Is(err, apperr.ErrInvalidArgument) {
-//		// Handle invalid argument error
-//	}
+// errors.Is with the predefined error variables matches any AppErr in the chain, including causes.
+// An Internal error wrapping a NotFound error therefore matches both ErrInternal and ErrNotFound,
+// while IsCode only reports codes.Internal:
+//
+//	err := apperr.Wrap(notFoundErr, codes.Internal, "failed to get user")
+//	errors.Is(err, apperr.ErrNotFound)     // true, a cause is NotFound
+//	apperr.IsCode(err, codes.NotFound)     // false, err is reported as Internal
+//	errors.Is(err, sql.ErrNoRows)          // true if the cause chain contains it
+//
+// Use errors.Is to look for a specific cause, and IsCode to branch on the code.
+//
+// # Joining Errors
+//
+// Join aggregates several errors, e.g. of a batch operation, into one reported with the most
+// severe code, while errors.Is still matches the code of each of them:
+//
+//	err := apperr.Join(invalidErr, internalErr)
+//	apperr.IsCode(err, codes.Internal)        // true, Internal is more severe
+//	errors.Is(err, apperr.ErrInvalidArgument) // true, a joined error is InvalidArgument
 //
 // # Structured Logging
 //
@@ -52,6 +68,45 @@
 //	// Unwrap to get the original cause
 //	originalErr := errors.Unwrap(err)
 //
+// # Field Violations
+//
+// Report invalid request fields so clients can display them per field:
+//
+//	err := apperr.NewInvalidArgument("invalid user", []apperr.FieldViolation{
+//		{Field: "email", Description: "must be a valid email address"},
+//	})
+//
+// Clients read them back from the received Connect error:
+//
+//	code, violations, meta := apperr.ParseConnectError(err)
+//
+// # Errors From Other Services
+//
+// Translate a Connect error received from another service back into an AppErr, keeping its code,
+// message, field violations, and metadata:
+//
+//	if err != nil {
+//		return nil, apperr.FromConnectError(err)
+//	}
+//
+// # Operation Attribute
+//
+// Tag wrapped errors with the function that wrapped them, so logs can be grouped by operation:
+//
+//	apperr.SetAutoOperation(true)
+//
+//	err := apperr.Wrap(dbErr, codes.Internal, "failed to get user")
+//	// Attrs include operation="usecase.(*UserUseCase).GetUser"
+//
+// # Cause Chain
+//
+// Log every level of the cause chain rather than only the immediate cause:
+//
+//	apperr.SetCauseChain(true)
+//
+//	logger.Error(ctx, "Failed to get user", err)
+//	// Logs causes=[{"msg":"query failed: ..."},{"code":"unavailable","msg":"database unavailable"},...]
+//
 // # Predefined Error Variables
 //
 // The package provides predefined error variables for all status codes:
@@ -61,7 +116,7 @@
 //   - ErrAborted, ErrOutOfRange, ErrUnimplemented
 //   - ErrInternal, ErrUnavailable, ErrDataLoss, ErrUnauthenticated
 //
-// These variables can be used directly or as targets for errors.Is comparisons.
+// These variables can be used directly or as targets for errors.Is comparisons of causes.
 package apperr
 
 import (
@@ -70,6 +125,7 @@ import (
 	"log/slog"
 	"runtime"
 	"strings"
+	"sync/atomic"
 
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
 )
@@ -80,64 +136,76 @@ import (
 // AppErr implements the error interface and can be used with the standard
 // errors package functions like errors.Is and errors.As.
 type AppErr struct {
-	Cause error       // Original error that caused this AppErr (if any)
-	Code  codes.Code  // Status code representing the error type
-	Msg   string      // Human-readable error message
-	Attrs []slog.Attr // Structured attributes for logging context
+	Cause      error            // Original error that caused this AppErr (if any)
+	Code       codes.Code       // Status code representing the error type
+	Msg        string           // Human-readable error message
+	Attrs      []slog.Attr      // Structured attributes for logging context
+	Violations []FieldViolation // Invalid request fields sent to clients as error details
 }
 
 // Global error variables provide predefined AppErr instances for common status codes.
 // These can be used directly or as targets for errors.Is comparisons.
+// Each carries a default message derived from its code, e.g. "not found (not_found)".
 var (
 	// ErrCanceled represents a canceled operation.
-	ErrCanceled = &AppErr{Code: codes.Canceled}
+	ErrCanceled = sentinel(codes.Canceled)
 
 	// ErrUnknown represents an unknown error.
-	ErrUnknown = &AppErr{Code: codes.Unknown}
+	ErrUnknown = sentinel(codes.Unknown)
 
 	// ErrInvalidArgument represents an invalid argument error.
-	ErrInvalidArgument = &AppErr{Code: codes.InvalidArgument}
+	ErrInvalidArgument = sentinel(codes.InvalidArgument)
 
 	// ErrDeadlineExceeded represents a deadline exceeded error.
-	ErrDeadlineExceeded = &AppErr{Code: codes.DeadlineExceeded}
+	ErrDeadlineExceeded = sentinel(codes.DeadlineExceeded)
 
 	// ErrNotFound represents a not found error.
-	ErrNotFound = &AppErr{Code: codes.NotFound}
+	ErrNotFound = sentinel(codes.NotFound)
 
 	// ErrAlreadyExists represents an already exists error.
-	ErrAlreadyExists = &AppErr{Code: codes.AlreadyExists}
+	ErrAlreadyExists = sentinel(codes.AlreadyExists)
 
 	// ErrPermissionDenied represents a permission denied error.
-	ErrPermissionDenied = &AppErr{Code: codes.PermissionDenied}
+	ErrPermissionDenied = sentinel(codes.PermissionDenied)
 
 	// ErrResourceExhausted represents a resource exhausted error.
-	ErrResourceExhausted = &AppErr{Code: codes.ResourceExhausted}
+	ErrResourceExhausted = sentinel(codes.ResourceExhausted)
 
 	// ErrFailedPrecondition represents a failed precondition error.
-	ErrFailedPrecondition = &AppErr{Code: codes.FailedPrecondition}
+	ErrFailedPrecondition = sentinel(codes.FailedPrecondition)
 
 	// ErrAborted represents an aborted operation error.
-	ErrAborted = &AppErr{Code: codes.Aborted}
+	ErrAborted = sentinel(codes.Aborted)
 
 	// ErrOutOfRange represents an out of range error.
-	ErrOutOfRange = &AppErr{Code: codes.OutOfRange}
+	ErrOutOfRange = sentinel(codes.OutOfRange)
 
 	// ErrUnimplemented represents an unimplemented operation error.
-	ErrUnimplemented = &AppErr{Code: codes.Unimplemented}
+	ErrUnimplemented = sentinel(codes.Unimplemented)
 
 	// ErrInternal represents an internal server error.
-	ErrInternal = &AppErr{Code: codes.Internal}
+	ErrInternal = sentinel(codes.Internal)
 
 	// ErrUnavailable represents a service unavailable error.
-	ErrUnavailable = &AppErr{Code: codes.Unavailable}
+	ErrUnavailable = sentinel(codes.Unavailable)
 
 	// ErrDataLoss represents a data loss error.
-	ErrDataLoss = &AppErr{Code: codes.DataLoss}
+	ErrDataLoss = sentinel(codes.DataLoss)
 
 	// ErrUnauthenticated represents an unauthenticated request error.
-	ErrUnauthenticated = &AppErr{Code: codes.Unauthenticated}
+	ErrUnauthenticated = sentinel(codes.Unauthenticated)
 )
 
+// sentinel returns a predefined AppErr for code with a default message, e.g. "not found (not_found)",
+// so that it is usable when returned directly. errors.Is compares only the code, so the message
+// does not affect matching.
+func sentinel(code codes.Code) *AppErr {
+	return &AppErr{
+		Code: code,
+		Msg:  fmt.Sprintf("%s (%s)", strings.ReplaceAll(code.String(), "_", " "), code),
+	}
+}
+
 // Error implements the error interface.
 // Returns the formatted error message including the status code.
 func (e *AppErr) Error() string {
@@ -152,7 +220,8 @@ func (e *AppErr) Unwrap() error {
 
 // Is enables error checking with errors.Is.
 // Returns true if the target is an AppErr with the same Code, or if the Cause field matches the target.
-// This allows semantic error comparison based on error codes rather than exact instance matching.
+// Since errors.Is also walks the causes, it matches the code of any AppErr in the chain, not only
+// the code the error is reported with; use IsCode for that.
 func (e *AppErr) Is(target error) bool {
 	if target == nil {
 		return false
@@ -166,9 +235,44 @@ func (e *AppErr) Is(target error) bool {
 	return errors.Is(e.Cause, target)
 }
 
+// IsCode reports whether err is reported with code, i.e. the outermost AppErr in its chain has code.
+// Unlike errors.Is with a predefined error variable, the codes of wrapped causes are not considered.
+// It returns false if err contains no AppErr.
+func IsCode(err error, code codes.Code) bool {
+	var appErr *AppErr
+	if !errors.As(err, &appErr) {
+		return false
+	}
+
+	return appErr.Code == code
+}
+
+// HasAttr reports whether an AppErr in the chain of err carries the attribute key with value,
+// e.g. field=email for a validation error. Values of other kinds than string are compared
+// in their slog.Value.String form, e.g. "42" for an int.
+func HasAttr(err error, key, value string) bool {
+	for err != nil {
+		var appErr *AppErr
+		if !errors.As(err, &appErr) {
+			return false
+		}
+
+		for _, attr := range appErr.Attrs {
+			if attr.Key == key && attr.Value.String() == value {
+				return true
+			}
+		}
+
+		err = appErr.Cause
+	}
+
+	return false
+}
+
 // LogValue implements slog.LogValuer, allowing AppErr to be logged as structured attributes.
 // When used with slog, this will output all error context as structured fields including
-// message, code, cause, and any additional attributes.
+// message, code, cause, and any additional attributes. If enabled with SetCauseChain,
+// the whole cause chain is added as causes.
 func (e *AppErr) LogValue() slog.Value {
 	attrs := []slog.Attr{
 		slog.String("msg", e.Msg),
@@ -176,6 +280,10 @@ func (e *AppErr) LogValue() slog.Value {
 	}
 	if e.Cause != nil {
 		attrs = append(attrs, slog.String("cause", e.Cause.Error()))
+
+		if causeChain.Load() {
+			attrs = append(attrs, slog.Any("causes", causes(e.Cause)))
+		}
 	}
 
 	anyAttrs := make([]any, len(e.Attrs))
@@ -203,7 +311,7 @@ func (e *AppErr) LogValue() slog.Value {
 //		slog.String("user_id", "123"),
 //		slog.String("operation", "GetUser"))
 func New(code codes.Code, msg string, attrs ...slog.Attr) error {
-	attrs = append(attrs, withStack())
+	attrs = append(attrs, withStack(callers()))
 
 	return &AppErr{
 		Code:  code,
@@ -218,6 +326,7 @@ func New(code codes.Code, msg string, attrs ...slog.Attr) error {
 // Note: When wrapping an existing AppErr, its original Code field will be overridden by the given code.
 // A stack trace is automatically captured and included in the attributes.
 // Use this to wrap existing errors with additional context and status code.
+// If enabled with SetAutoOperation, the calling function is added as the "operation" attribute.
 //
 // Example:
 //
@@ -229,11 +338,19 @@ func New(code codes.Code, msg string, attrs ...slog.Attr) error {
 //	err = apperr.Wrap(appErr, codes.NotFound, "user lookup failed")
 //	// Result: "user lookup failed (NotFound): original message"
 func Wrap(err error, code codes.Code, msg string, attrs ...slog.Attr) error {
-	attrs = append(attrs, withStack())
+	pcs := callers()
 
 	// If err is already an AppErr, flatten the chain
 	var appErr *AppErr
-	if !errors.As(err, &appErr) {
+	isAppErr := errors.As(err, &appErr)
+
+	if autoOperation.Load() && !hasAttr(attrs, operationKey) && (!isAppErr || !hasAttr(appErr.Attrs, operationKey)) {
+		attrs = append(attrs, withOperation(pcs))
+	}
+
+	attrs = append(attrs, withStack(pcs))
+
+	if !isAppErr {
 		// Original behavior for non-AppErr errors
 		return &AppErr{
 			Cause: err,
@@ -263,29 +380,144 @@ func Wrap(err error, code codes.Code, msg string, attrs ...slog.Attr) error {
 	}
 
 	return &AppErr{
-		Cause: cause,       // Keep the original cause
-		Code:  code,        // Use new code
-		Msg:   combinedMsg, // Concatenated message
-		Attrs: mergedAttrs, // Merge attributes (keeping original stack trace)
+		Cause:      cause,             // Keep the original cause
+		Code:       code,              // Use new code
+		Msg:        combinedMsg,       // Concatenated message
+		Attrs:      mergedAttrs,       // Merge attributes (keeping original stack trace)
+		Violations: appErr.Violations, // Keep the original field violations
+	}
+}
+
+// Join combines errs, e.g. the failures of a batch operation, into a single AppErr reported with
+// the most severe of their codes, as ranked by severity, and carrying the attributes and field
+// violations of every AppErr among them. Errors that are not AppErrs count as codes.Unknown.
+// errors.Is matches the code of every joined error, not only the reported one.
+// Nil errors are discarded; Join returns nil if all are nil, and a single AppErr unchanged.
+//
+// Example:
+//
+//	err := apperr.Join(
+//		apperr.New(codes.InvalidArgument, "invalid title"),
+//		apperr.New(codes.Internal, "failed to store post"),
+//	)
+//	// apperr.IsCode(err, codes.Internal) and errors.Is(err, apperr.ErrInvalidArgument) are both true
+func Join(errs ...error) error {
+	joined := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		if err != nil {
+			joined = append(joined, err)
+		}
+	}
+
+	switch len(joined) {
+	case 0:
+		return nil
+	case 1:
+		var appErr *AppErr
+		if errors.As(joined[0], &appErr) && appErr == joined[0] {
+			return appErr
+		}
+	}
+
+	code := codes.Unknown
+	msgs := make([]string, 0, len(joined))
+
+	var (
+		attrs      []slog.Attr
+		violations []FieldViolation
+	)
+
+	for i, err := range joined {
+		errCode := codes.Unknown
+
+		var appErr *AppErr
+		if errors.As(err, &appErr) {
+			errCode = appErr.Code
+
+			// Each joined error keeps its own stack trace, so only the stack of Join is added below
+			for _, attr := range appErr.Attrs {
+				if attr.Key != "stacktrace" {
+					attrs = append(attrs, attr)
+				}
+			}
+
+			violations = append(violations, appErr.Violations...)
+		}
+
+		if i == 0 || severity(errCode) > severity(code) {
+			code = errCode
+		}
+
+		msgs = append(msgs, err.Error())
+	}
+
+	attrs = append(attrs, withStack(callers()))
+
+	return &AppErr{
+		Cause:      errors.Join(joined...),
+		Code:       code,
+		Msg:        fmt.Sprintf("%d errors (%s): %s", len(joined), code, strings.Join(msgs, "; ")),
+		Attrs:      attrs,
+		Violations: violations,
+	}
+}
+
+// severityOrder lists codes from least to most severe. Server errors rank above client errors,
+// since a batch that failed partly on the server side needs attention regardless of invalid input.
+var severityOrder = []codes.Code{
+	codes.Canceled,
+	codes.InvalidArgument,
+	codes.OutOfRange,
+	codes.NotFound,
+	codes.AlreadyExists,
+	codes.FailedPrecondition,
+	codes.Aborted,
+	codes.PermissionDenied,
+	codes.Unauthenticated,
+	codes.ResourceExhausted,
+	codes.DeadlineExceeded,
+	codes.Unimplemented,
+	codes.Unavailable,
+	codes.Unknown,
+	codes.Internal,
+	codes.DataLoss,
+}
+
+// severity returns the rank of code in severityOrder, higher being more severe.
+func severity(code codes.Code) int {
+	for i, c := range severityOrder {
+		if c == code {
+			return i
+		}
 	}
+
+	return -1
 }
 
 const callStackSkip = 3
 
-// withStack captures the current stack trace and returns it as a slog attribute.
-// This is used internally by New and Wrap to automatically include stack traces.
-// The stack trace excludes the withStack function itself and the immediate caller (New/Wrap).
-func withStack() slog.Attr {
+// callers returns the program counters of the call stack of the caller of the error constructor.
+// It must be called directly from the constructor, e.g. New or Wrap, so that runtime.Callers, callers itself,
+// and the constructor are skipped.
+func callers() []uintptr {
 	var pcs [32]uintptr
 
-	n := runtime.Callers(callStackSkip, pcs[:]) // Skip withStack and New/Wrap
-	if n == 0 {
+	n := runtime.Callers(callStackSkip, pcs[:])
+
+	return pcs[:n]
+}
+
+// withStack returns the call stack captured by callers as a slog attribute.
+// This is used internally by New and Wrap to automatically include stack traces.
+func withStack(pcs []uintptr) slog.Attr {
+	if len(pcs) == 0 {
 		return slog.String("stacktrace", "unknown")
 	}
 
 	var sb strings.Builder
 
-	frames := runtime.CallersFrames(pcs[:n])
+	frames := runtime.CallersFrames(pcs)
 
 	for {
 		frame, more := frames.Next()
@@ -298,3 +530,71 @@ func withStack() slog.Attr {
 
 	return slog.String("stacktrace", sb.String())
 }
+
+// operationKey is the attribute key of the operation that created an error.
+const operationKey = "operation"
+
+// autoOperation reports whether Wrap adds an operation attribute, see SetAutoOperation.
+var autoOperation atomic.Bool
+
+// SetAutoOperation enables or disables adding an "operation" attribute in Wrap, naming the function
+// that called Wrap, e.g. "usecase.(*UserUseCase).GetUser", so logs can be grouped by operation.
+// An operation attribute given to Wrap, or already present on a wrapped AppErr, takes precedence.
+// It is disabled by default and is typically enabled once at startup.
+func SetAutoOperation(enabled bool) {
+	autoOperation.Store(enabled)
+}
+
+// withOperation returns the operation attribute naming the function of the first frame in pcs.
+func withOperation(pcs []uintptr) slog.Attr {
+	if len(pcs) == 0 {
+		return slog.String(operationKey, "unknown")
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:1]).Next()
+
+	// Trim the package path, keeping the package name, e.g. "usecase.(*UserUseCase).GetUser"
+	function := frame.Function
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+
+	return slog.String(operationKey, function)
+}
+
+// hasAttr reports whether attrs contain an attribute with the given key.
+func hasAttr(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// causeChain reports whether LogValue adds the cause chain, see SetCauseChain.
+var causeChain atomic.Bool
+
+// SetCauseChain enables or disables adding a "causes" array to the log value of AppErr, with one entry
+// per level of the cause chain from the immediate cause down to the root. Each entry has the msg of the
+// error, and the code if it is an AppErr. Nested causes are otherwise only visible flattened into
+// the cause message. It is disabled by default and is typically enabled once at startup.
+func SetCauseChain(enabled bool) {
+	causeChain.Store(enabled)
+}
+
+// causes returns an entry per level of the chain of err, following errors.Unwrap.
+func causes(err error) []map[string]string {
+	var chain []map[string]string
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if appErr, ok := err.(*AppErr); ok {
+			chain = append(chain, map[string]string{"code": appErr.Code.String(), "msg": appErr.Msg})
+		} else {
+			chain = append(chain, map[string]string{"msg": err.Error()})
+		}
+	}
+
+	return chain
+}
@@ -1,9 +1,13 @@
 package apperr
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -193,6 +197,192 @@ func TestAppErr_Is(t *testing.T) {
 	}
 }
 
+func TestIsCode(t *testing.T) {
+	notFoundErr := New(codes.NotFound, "user not found")
+	wrappedErr := Wrap(notFoundErr, codes.Internal, "failed to get user")
+
+	tests := []struct {
+		name       string
+		err        error
+		code       codes.Code
+		want       bool
+		wantErrsIs bool // errors.Is with the predefined variable of code, for contrast
+	}{
+		{
+			name:       "returns true when error has the code",
+			err:        notFoundErr,
+			code:       codes.NotFound,
+			want:       true,
+			wantErrsIs: true,
+		},
+		{
+			name:       "returns true for the code the wrapping error is reported with",
+			err:        wrappedErr,
+			code:       codes.Internal,
+			want:       true,
+			wantErrsIs: true,
+		},
+		{
+			name:       "returns false for the code of a wrapped cause, unlike errors.Is",
+			err:        wrappedErr,
+			code:       codes.NotFound,
+			want:       false,
+			wantErrsIs: true,
+		},
+		{
+			name:       "returns true when AppErr is wrapped by a non-AppErr error",
+			err:        fmt.Errorf("handler failed: %w", notFoundErr),
+			code:       codes.NotFound,
+			want:       true,
+			wantErrsIs: true,
+		},
+		{
+			name:       "returns false when error is not an AppErr",
+			err:        sql.ErrNoRows,
+			code:       codes.NotFound,
+			want:       false,
+			wantErrsIs: false,
+		},
+		{
+			name:       "returns false when error is nil",
+			err:        nil,
+			code:       codes.NotFound,
+			want:       false,
+			wantErrsIs: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCode(tt.err, tt.code); got != tt.want {
+				t.Errorf("IsCode() = %v, want %v", got, tt.want)
+			}
+
+			if got := errors.Is(tt.err, &AppErr{Code: tt.code}); got != tt.wantErrsIs {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.wantErrsIs)
+			}
+		})
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		sentinel *AppErr
+		wantMsg  string
+	}{
+		{name: "ErrNotFound", sentinel: ErrNotFound, wantMsg: "not found (not_found)"},
+		{name: "ErrInvalidArgument", sentinel: ErrInvalidArgument, wantMsg: "invalid argument (invalid_argument)"},
+		{name: "ErrDeadlineExceeded", sentinel: ErrDeadlineExceeded, wantMsg: "deadline exceeded (deadline_exceeded)"},
+		{name: "ErrInternal", sentinel: ErrInternal, wantMsg: "internal (internal)"},
+		{name: "ErrUnauthenticated", sentinel: ErrUnauthenticated, wantMsg: "unauthenticated (unauthenticated)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sentinel.Error(); got != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMsg)
+			}
+
+			// Errors created with New keep their own message and still match the sentinel by code
+			err := New(tt.sentinel.Code, "custom message")
+			if got, want := err.Error(), fmt.Sprintf("custom message (%s)", tt.sentinel.Code); got != want {
+				t.Errorf("New().Error() = %q, want %q", got, want)
+			}
+
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(New(%s), %s) = false, want true", tt.sentinel.Code, tt.name)
+			}
+		})
+	}
+
+	if errors.Is(New(codes.Internal, "database error"), ErrNotFound) {
+		t.Error("errors.Is(New(Internal), ErrNotFound) = true, want false")
+	}
+
+	// Every sentinel is usable when returned directly
+	for _, sentinel := range []*AppErr{
+		ErrCanceled, ErrUnknown, ErrInvalidArgument, ErrDeadlineExceeded, ErrNotFound, ErrAlreadyExists,
+		ErrPermissionDenied, ErrResourceExhausted, ErrFailedPrecondition, ErrAborted, ErrOutOfRange,
+		ErrUnimplemented, ErrInternal, ErrUnavailable, ErrDataLoss, ErrUnauthenticated,
+	} {
+		if sentinel.Error() == "" {
+			t.Errorf("%s sentinel has an empty message", sentinel.Code)
+		}
+	}
+}
+
+func TestHasAttr(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		key   string
+		value string
+		want  bool
+	}{
+		{
+			name:  "return true when attribute is present",
+			err:   New(codes.InvalidArgument, "invalid email", slog.String("field", "email")),
+			key:   "field",
+			value: "email",
+			want:  true,
+		},
+		{
+			name:  "return false when attribute is absent",
+			err:   New(codes.InvalidArgument, "invalid email"),
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+		{
+			name:  "return false when attribute has another value",
+			err:   New(codes.InvalidArgument, "invalid name", slog.String("field", "name")),
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+		{
+			name:  "compare non-string value by its string form",
+			err:   New(codes.InvalidArgument, "too many", slog.Int("limit", 42)),
+			key:   "limit",
+			value: "42",
+			want:  true,
+		},
+		{
+			name: "find attribute of wrapped AppErr",
+			err: Wrap(
+				New(codes.NotFound, "user not found", slog.String("user_id", "123")),
+				codes.Internal, "failed to get user",
+			),
+			key:   "user_id",
+			value: "123",
+			want:  true,
+		},
+		{
+			name:  "return false for non-AppErr",
+			err:   fmt.Errorf("field=email"),
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+		{
+			name:  "return false for nil error",
+			err:   nil,
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasAttr(tt.err, tt.key, tt.value); got != tt.want {
+				t.Errorf("HasAttr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAppErr_LogValue(t *testing.T) {
 	originalErr := errors.New("database error")
 	attrs := []slog.Attr{
@@ -603,6 +793,71 @@ func TestWrap(t *testing.T) {
 	}
 }
 
+// TestAppErr_LogValue_CauseChain is not parallel because SetCauseChain changes package state.
+func TestAppErr_LogValue_CauseChain(t *testing.T) {
+	// Three levels below the logged error: a plain wrapper, an AppErr, and the root error
+	err := &AppErr{
+		Code: codes.Internal,
+		Msg:  "failed to get user",
+		Cause: fmt.Errorf("query failed: %w", &AppErr{
+			Code:  codes.Unavailable,
+			Msg:   "database unavailable",
+			Cause: errors.New("connection reset"),
+		}),
+	}
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantCauses []map[string]string
+	}{
+		{
+			name:    "log every level of cause chain when enabled",
+			enabled: true,
+			wantCauses: []map[string]string{
+				{"msg": "query failed: database unavailable"},
+				{"code": "unavailable", "msg": "database unavailable"},
+				{"msg": "connection reset"},
+			},
+		},
+		{
+			name:       "log no cause chain when disabled",
+			enabled:    false,
+			wantCauses: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetCauseChain(tt.enabled)
+			t.Cleanup(func() {
+				SetCauseChain(false)
+			})
+
+			var buf bytes.Buffer
+			slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", slog.Any("error", err))
+
+			var entry struct {
+				Error struct {
+					Cause  string              `json:"cause"`
+					Causes []map[string]string `json:"causes"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse log: %v", err)
+			}
+
+			if entry.Error.Cause != "query failed: database unavailable" {
+				t.Errorf("cause = %q, want %q", entry.Error.Cause, "query failed: database unavailable")
+			}
+
+			if !reflect.DeepEqual(entry.Error.Causes, tt.wantCauses) {
+				t.Errorf("causes = %v, want %v", entry.Error.Causes, tt.wantCauses)
+			}
+		})
+	}
+}
+
 // Helper functions for testing
 
 // validateStackTrace validates that the stack trace is properly formatted
@@ -635,3 +890,153 @@ func containsAttr(want []slog.Attr, attr slog.Attr) bool {
 
 	return false
 }
+
+// wrapInOperation wraps err so that it is the function reported as the operation.
+func wrapInOperation(err error, attrs ...slog.Attr) error {
+	return Wrap(err, codes.Internal, "operation failed", attrs...)
+}
+
+// TestWrap_AutoOperation is not parallel because it toggles the package-wide auto operation setting.
+func TestWrap_AutoOperation(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		wrap          func() error
+		wantOperation string
+	}{
+		{
+			name:          "add calling function as operation when enabled",
+			enabled:       true,
+			wrap:          func() error { return wrapInOperation(errors.New("database error")) },
+			wantOperation: "apperr.wrapInOperation",
+		},
+		{
+			name:    "keep operation supplied by caller",
+			enabled: true,
+			wrap: func() error {
+				return wrapInOperation(errors.New("database error"), slog.String("operation", "CreateUser"))
+			},
+			wantOperation: "CreateUser",
+		},
+		{
+			name:    "keep operation of wrapped AppErr",
+			enabled: true,
+			wrap: func() error {
+				return Wrap(wrapInOperation(errors.New("database error")), codes.Internal, "outer")
+			},
+			wantOperation: "apperr.wrapInOperation",
+		},
+		{
+			name:          "add no operation when disabled",
+			enabled:       false,
+			wrap:          func() error { return wrapInOperation(errors.New("database error")) },
+			wantOperation: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetAutoOperation(tt.enabled)
+			t.Cleanup(func() {
+				SetAutoOperation(false)
+			})
+
+			var appErr *AppErr
+			if !errors.As(tt.wrap(), &appErr) {
+				t.Fatal("Expected AppErr")
+			}
+
+			var operations []string
+
+			for _, attr := range appErr.Attrs {
+				if attr.Key == operationKey {
+					operations = append(operations, attr.Value.String())
+				}
+			}
+
+			if tt.wantOperation == "" {
+				if len(operations) != 0 {
+					t.Errorf("Expected no operation, got %v", operations)
+				}
+
+				return
+			}
+
+			if len(operations) != 1 || operations[0] != tt.wantOperation {
+				t.Errorf("Unexpected operation: want [%s], got %v", tt.wantOperation, operations)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	invalid := New(codes.InvalidArgument, "invalid title", slog.String("field", "title"))
+	internal := New(codes.Internal, "failed to store post", slog.String("post_id", "post-1"))
+
+	t.Run("reports the most severe code and matches every joined code", func(t *testing.T) {
+		err := Join(invalid, nil, internal)
+
+		var appErr *AppErr
+		if !errors.As(err, &appErr) {
+			t.Fatalf("Join() = %T, want *AppErr", err)
+		}
+
+		if appErr.Code != codes.Internal {
+			t.Errorf("Join().Code = %v, want %v", appErr.Code, codes.Internal)
+		}
+
+		wantMsg := "2 errors (internal): invalid title (invalid_argument); failed to store post (internal)"
+		if got := err.Error(); got != wantMsg {
+			t.Errorf("Join().Error() = %q, want %q", got, wantMsg)
+		}
+
+		for _, target := range []error{ErrInvalidArgument, ErrInternal} {
+			if !errors.Is(err, target) {
+				t.Errorf("errors.Is(Join(), %v) = false, want true", target)
+			}
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			t.Errorf("errors.Is(Join(), ErrNotFound) = true, want false")
+		}
+
+		if !HasAttr(err, "field", "title") || !HasAttr(err, "post_id", "post-1") {
+			t.Errorf("Join().Attrs = %v, want the attributes of both errors", appErr.Attrs)
+		}
+
+		stacks := 0
+		for _, attr := range appErr.Attrs {
+			if attr.Key == "stacktrace" {
+				stacks++
+			}
+		}
+
+		if stacks != 1 {
+			t.Errorf("Join().Attrs has %d stack traces, want 1", stacks)
+		}
+	})
+
+	t.Run("counts errors other than AppErr as unknown", func(t *testing.T) {
+		err := Join(invalid, sql.ErrNoRows)
+
+		if !IsCode(err, codes.Unknown) {
+			t.Errorf("IsCode(Join(), codes.Unknown) = false, want true")
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("errors.Is(Join(), sql.ErrNoRows) = false, want true")
+		}
+	})
+
+	t.Run("returns a single AppErr unchanged", func(t *testing.T) {
+		if got := Join(nil, invalid); got != invalid {
+			t.Errorf("Join(nil, err) = %v, want %v", got, invalid)
+		}
+	})
+
+	t.Run("returns nil without errors", func(t *testing.T) {
+		if got := Join(nil, nil); got != nil {
+			t.Errorf("Join(nil, nil) = %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+// Package apperrtest provides testify-style assertions for AppErr.
+//
+// Example:
+//
+//	_, err := uc.CreateUser(ctx, &entity.NewUser{Name: "Alice"})
+//	apperrtest.AssertHasAttr(t, err, "field", "email")
+package apperrtest
+
+import (
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+)
+
+// AssertHasAttr asserts that an AppErr in the chain of err carries the attribute key with value,
+// as reported by apperr.HasAttr.
+func AssertHasAttr(t assert.TestingT, err error, key, value string, msgAndArgs ...any) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if apperr.HasAttr(err, key, value) {
+		return true
+	}
+
+	return assert.Fail(t, fmt.Sprintf("Error %q does not carry attribute %s=%s", errorString(err), key, value), msgAndArgs...)
+}
+
+// errorString returns the message of err, or "<nil>" if err is nil.
+func errorString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,82 @@
+package apperrtest_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/apperrtest"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+)
+
+// recordingT records the failures reported to it instead of failing the test.
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertHasAttr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		err   error
+		key   string
+		value string
+		want  bool
+	}{
+		{
+			name:  "pass when attribute is present",
+			err:   apperr.New(codes.InvalidArgument, "invalid email", slog.String("field", "email")),
+			key:   "field",
+			value: "email",
+			want:  true,
+		},
+		{
+			name:  "fail when attribute is absent",
+			err:   apperr.New(codes.InvalidArgument, "invalid email"),
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+		{
+			name:  "fail when attribute has another value",
+			err:   apperr.New(codes.InvalidArgument, "invalid name", slog.String("field", "name")),
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+		{
+			name:  "fail for non-AppErr",
+			err:   errors.New("field=email"),
+			key:   "field",
+			value: "email",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := &recordingT{}
+
+			got := apperrtest.AssertHasAttr(rt, tt.err, tt.key, tt.value)
+
+			assert.Equal(t, tt.want, got)
+			if tt.want {
+				assert.Empty(t, rt.errors)
+			} else {
+				assert.Len(t, rt.errors, 1)
+				assert.Contains(t, rt.errors[0], tt.key+"="+tt.value)
+			}
+		})
+	}
+}
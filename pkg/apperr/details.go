@@ -0,0 +1,193 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+)
+
+// FieldViolation describes a single invalid field of a request.
+// It maps to google.rpc.BadRequest.FieldViolation in Connect error details.
+type FieldViolation struct {
+	Field       string // Path of the invalid field, e.g. "user.email"
+	Description string // Why the field is invalid
+}
+
+// NewInvalidArgument creates a new InvalidArgument AppErr carrying field violations.
+// The interceptor sends the violations to clients as a google.rpc.BadRequest error detail,
+// which can be read back with ParseConnectError.
+//
+// Example:
+//
+//	err := apperr.NewInvalidArgument("invalid user", []apperr.FieldViolation{
+//		{Field: "email", Description: "must be a valid email address"},
+//	})
+func NewInvalidArgument(msg string, violations []FieldViolation, attrs ...slog.Attr) error {
+	attrs = append(attrs, withStack(callers()))
+
+	return &AppErr{
+		Code:       codes.InvalidArgument,
+		Msg:        fmt.Sprintf("%s (%s)", msg, codes.InvalidArgument),
+		Attrs:      attrs,
+		Violations: violations,
+	}
+}
+
+// ParseConnectError extracts the code, field violations, and string metadata from a Connect error
+// received by a client. Field violations are read from any google.rpc.BadRequest error detail.
+// Metadata keys are lower-cased, and since clients receive metadata as response headers,
+// it may include transport headers in addition to the attributes set by the server.
+//
+// If err is not a *connect.Error, the code is codes.Unknown and no details are returned.
+//
+// Example:
+//
+//	code, violations, meta := apperr.ParseConnectError(err)
+//	if code == codes.InvalidArgument {
+//		for _, v := range violations {
+//			fmt.Printf("%s: %s\n", v.Field, v.Description)
+//		}
+//	}
+func ParseConnectError(err error) (codes.Code, []FieldViolation, map[string]string) {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return codes.Unknown, nil, nil
+	}
+
+	var violations []FieldViolation
+
+	for _, detail := range connectErr.Details() {
+		msg, err := detail.Value()
+		if err != nil {
+			continue // Skip details whose types are not linked into the client
+		}
+
+		badRequest, ok := msg.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, v := range badRequest.GetFieldViolations() {
+			violations = append(violations, FieldViolation{
+				Field:       v.GetField(),
+				Description: v.GetDescription(),
+			})
+		}
+	}
+
+	meta := make(map[string]string, len(connectErr.Meta()))
+	for key, values := range connectErr.Meta() {
+		if len(values) > 0 {
+			meta[strings.ToLower(key)] = values[0]
+		}
+	}
+
+	return connectErr.Code(), violations, meta
+}
+
+// FromConnectError converts a Connect error received from another service back into an AppErr,
+// so that it can be handled, wrapped, and returned like errors raised locally.
+// The code, message, and field violations are preserved, and metadata is turned into attributes,
+// except transport headers such as content-type that clients receive alongside it.
+// The Connect error is kept as the cause.
+//
+// If err is not a *connect.Error, it is wrapped with codes.Unknown.
+// It returns a nil error, not a nil *AppErr, if err is nil, so the result can be returned as an error directly.
+//
+// Example:
+//
+//	res, err := userClient.GetUser(ctx, req)
+//	if err != nil {
+//		return nil, apperr.FromConnectError(err)
+//	}
+func FromConnectError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	attrs := []slog.Attr{withStack(callers())}
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return &AppErr{
+			Cause: err,
+			Code:  codes.Unknown,
+			Msg:   fmt.Sprintf("%s (%s)", err.Error(), codes.Unknown),
+			Attrs: attrs,
+		}
+	}
+
+	code, violations, meta := ParseConnectError(connectErr)
+
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		if !isTransportHeader(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	// Map iteration order is random, so sort to keep attributes stable across calls
+	sort.Strings(keys)
+
+	metaAttrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		metaAttrs = append(metaAttrs, slog.String(key, meta[key]))
+	}
+
+	// Messages of AppErrs from services using this package already end with the code
+	msg := connectErr.Message()
+	if suffix := fmt.Sprintf(" (%s)", code); !strings.HasSuffix(msg, suffix) {
+		msg += suffix
+	}
+
+	return &AppErr{
+		Cause:      connectErr,
+		Code:       code,
+		Msg:        msg,
+		Attrs:      append(metaAttrs, attrs...),
+		Violations: violations,
+	}
+}
+
+// transportHeaderPrefixes are prefixes of lower-cased headers set by the transport rather than
+// by the AppErr attributes of the remote service.
+var transportHeaderPrefixes = []string{"content-", "connect-", "grpc-", "accept-"}
+
+// isTransportHeader reports whether the lower-cased metadata key is a transport header.
+func isTransportHeader(key string) bool {
+	switch key {
+	case "date", "server", "vary", "connection", "transfer-encoding", "trailer":
+		return true
+	}
+
+	for _, prefix := range transportHeaderPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newBadRequestDetail converts field violations into a google.rpc.BadRequest error detail.
+func newBadRequestDetail(violations []FieldViolation) (*connect.ErrorDetail, error) {
+	badRequest := &errdetails.BadRequest{
+		FieldViolations: make([]*errdetails.BadRequest_FieldViolation, 0, len(violations)),
+	}
+
+	for _, v := range violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	return connect.NewErrorDetail(badRequest)
+}
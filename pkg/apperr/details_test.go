@@ -0,0 +1,229 @@
+package apperr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
+	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+const testProcedure = "/test.v1.TestService/Call"
+
+func TestParseConnectError(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		code       codes.Code
+		violations []apperr.FieldViolation
+		metadata   map[string]string
+	}
+
+	tests := []struct {
+		name      string
+		serverErr error
+		want      want
+	}{
+		{
+			name: "extract field violations and metadata from InvalidArgument",
+			serverErr: apperr.NewInvalidArgument("invalid user", []apperr.FieldViolation{
+				{Field: "name", Description: "must not be empty"},
+				{Field: "email", Description: "must be a valid email address"},
+			}, slog.String("request_id", "req-123")),
+			want: want{
+				code: codes.InvalidArgument,
+				violations: []apperr.FieldViolation{
+					{Field: "name", Description: "must not be empty"},
+					{Field: "email", Description: "must be a valid email address"},
+				},
+				metadata: map[string]string{"request_id": "req-123"},
+			},
+		},
+		{
+			name: "keep field violations when wrapped",
+			serverErr: apperr.Wrap(
+				apperr.NewInvalidArgument("invalid post", []apperr.FieldViolation{
+					{Field: "title", Description: "must not be empty"},
+				}),
+				codes.InvalidArgument, "failed to create post",
+			),
+			want: want{
+				code: codes.InvalidArgument,
+				violations: []apperr.FieldViolation{
+					{Field: "title", Description: "must not be empty"},
+				},
+			},
+		},
+		{
+			name:      "extract code and metadata without field violations",
+			serverErr: apperr.New(codes.NotFound, "user not found", slog.String("user_id", "user-123")),
+			want: want{
+				code:     codes.NotFound,
+				metadata: map[string]string{"user_id": "user-123"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logging.New(logging.WithWriter(io.Discard))
+			handler := func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				return nil, tt.serverErr
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(testProcedure, connect.NewUnaryHandler(testProcedure, handler,
+				connect.WithInterceptors(apperr.NewInterceptor(logger)),
+			))
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+testProcedure)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.Error(t, err)
+
+			code, violations, metadata := apperr.ParseConnectError(err)
+
+			assert.Equal(t, tt.want.code, code)
+			assert.Equal(t, tt.want.violations, violations)
+
+			for key, value := range tt.want.metadata {
+				assert.Equal(t, value, metadata[key], "metadata key %q", key)
+			}
+
+			assert.NotContains(t, metadata, "stacktrace")
+		})
+	}
+}
+
+func TestParseConnectError_NonConnectError(t *testing.T) {
+	t.Parallel()
+
+	code, violations, metadata := apperr.ParseConnectError(errors.New("connection refused"))
+
+	assert.Equal(t, codes.Unknown, code)
+	assert.Nil(t, violations)
+	assert.Nil(t, metadata)
+}
+
+func TestFromConnectError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		serverErr      error
+		wantCode       codes.Code
+		wantMsg        string
+		wantAttrs      map[string]string
+		wantViolations []apperr.FieldViolation
+	}{
+		{
+			name:      "preserve code, message, and metadata of client errors",
+			serverErr: apperr.New(codes.NotFound, "user not found", slog.String("user_id", "user-123")),
+			wantCode:  codes.NotFound,
+			wantMsg:   "user not found (not_found)",
+			wantAttrs: map[string]string{"user_id": "user-123"},
+		},
+		{
+			name: "preserve field violations",
+			serverErr: apperr.NewInvalidArgument("invalid user", []apperr.FieldViolation{
+				{Field: "email", Description: "must be a valid email address"},
+			}),
+			wantCode:  codes.InvalidArgument,
+			wantMsg:   "invalid user (invalid_argument)",
+			wantAttrs: map[string]string{},
+			wantViolations: []apperr.FieldViolation{
+				{Field: "email", Description: "must be a valid email address"},
+			},
+		},
+		{
+			name:      "preserve code of server errors with the message sent to clients",
+			serverErr: apperr.Wrap(errors.New("connection refused"), codes.Unavailable, "failed to query database"),
+			wantCode:  codes.Unavailable,
+			wantMsg:   "service unavailable (unavailable)",
+			wantAttrs: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := logging.New(logging.WithWriter(io.Discard))
+			handler := func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				return nil, tt.serverErr
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(testProcedure, connect.NewUnaryHandler(testProcedure, handler,
+				connect.WithInterceptors(apperr.NewInterceptor(logger)),
+			))
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+testProcedure)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.Error(t, err)
+
+			err = apperr.FromConnectError(err)
+
+			var got *apperr.AppErr
+			require.ErrorAs(t, err, &got)
+
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.Equal(t, tt.wantMsg, got.Msg)
+			assert.Equal(t, tt.wantViolations, got.Violations)
+			assert.True(t, apperr.IsCode(got, tt.wantCode))
+
+			var connectErr *connect.Error
+			assert.ErrorAs(t, got, &connectErr, "the Connect error must be kept as the cause")
+
+			// Attributes are the metadata set by the server, without transport headers or the stack trace
+			gotAttrs := map[string]string{}
+			for _, attr := range got.Attrs {
+				if attr.Key != "stacktrace" {
+					gotAttrs[attr.Key] = attr.Value.String()
+				}
+			}
+
+			assert.Equal(t, tt.wantAttrs, gotAttrs)
+		})
+	}
+}
+
+func TestFromConnectError_NonConnectError(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("connection refused")
+
+	err := apperr.FromConnectError(cause)
+
+	var got *apperr.AppErr
+	require.ErrorAs(t, err, &got)
+	assert.Equal(t, codes.Unknown, got.Code)
+	assert.ErrorIs(t, got, cause)
+}
+
+func TestFromConnectError_Nil(t *testing.T) {
+	t.Parallel()
+
+	// NoError compares the interface with nil, so it fails on a typed nil *AppErr where assert.Nil would not
+	assert.NoError(t, apperr.FromConnectError(nil))
+}
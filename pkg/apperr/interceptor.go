@@ -2,49 +2,199 @@ package apperr
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"connectrpc.com/connect"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewInterceptor creates a Connect interceptor that handles AppErr conversion and logging.
 // It converts AppErr instances to appropriate Connect errors and logs server errors.
-// Client errors (4xx status codes) are not logged, while server errors (5xx) are logged.
-func NewInterceptor(logger *logging.Logger) connect.UnaryInterceptorFunc {
+// Context errors returned as is are converted to Canceled or DeadlineExceeded, and Connect errors
+// returned by handlers, e.g. for invalid requests, keep their code, message, and details.
+// Client errors (4xx status codes) are not logged, while server errors (5xx) are logged
+// and recorded on the active span, marking it as failed. Clients receive a generic message
+// for server errors, since the detailed one may describe internals.
+//
+// With WithErrorReferences, clients instead receive the generic message followed by ", reference: <reference>"
+// for server errors, e.g. "internal server error, reference: <reference>",
+// and the log carries the same reference so that support can find the detail a client reports.
+//
+// With WithErrorMetrics, every error is also counted by code.
+func NewInterceptor(logger *logging.Logger, opts ...InterceptorOption) connect.UnaryInterceptorFunc {
+	o := &interceptorOptions{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	errorCount := newErrorCounter(logger, o.meter)
+
 	return func(next connect.UnaryFunc) connect.UnaryFunc {
 		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
 			resp, err := next(ctx, req)
 			if err != nil {
-				return resp, handleError(ctx, err, logger)
+				errorCount.Add(ctx, 1, otelmetric.WithAttributes(attribute.String("code", errorCode(err).String())))
+
+				return resp, handleError(ctx, req, err, logger, o)
 			}
 			return resp, nil
 		}
 	}
 }
 
+// InterceptorOption defines a function that configures the error interceptor.
+type InterceptorOption func(*interceptorOptions)
+
+// interceptorOptions holds the error interceptor configuration.
+type interceptorOptions struct {
+	errorReferences bool
+	meter           otelmetric.Meter // nil if errors are not counted
+}
+
+// WithErrorReferences makes server errors (5xx) opaque to clients. Clients receive only the code and
+// the generic message of the code with the reference, e.g. "service unavailable, reference: <reference>",
+// without metadata or field violations, while the logged error carries the full detail and the reference
+// in the "error_reference" attribute.
+//
+// The reference is the X-Request-Id request header if it is at most 64 letters, digits, '-' and '_',
+// otherwise a random ID.
+func WithErrorReferences() InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.errorReferences = true
+	}
+}
+
+// errorsMetric is the name of the counter of errors returned by RPCs.
+const errorsMetric = "rpc.server.errors"
+
+// WithErrorMetrics counts every error returned by RPCs, client and server errors alike, in the
+// rpc.server.errors counter of meter, labeled with its code, e.g. "not_found". Context errors are
+// counted as "canceled" or "deadline_exceeded", Connect errors by their code, and other errors as "unknown".
+// Errors are not counted by default.
+func WithErrorMetrics(meter otelmetric.Meter) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.meter = meter
+	}
+}
+
+// newErrorCounter creates the counter of WithErrorMetrics, or a no-op counter if meter is nil.
+// Failing to create the counter only disables counting, since it must not prevent serving.
+func newErrorCounter(logger *logging.Logger, meter otelmetric.Meter) otelmetric.Int64Counter {
+	if meter == nil {
+		return noop.Int64Counter{}
+	}
+
+	counter, err := meter.Int64Counter(errorsMetric,
+		otelmetric.WithDescription("Number of errors returned by RPCs."),
+		otelmetric.WithUnit("{error}"),
+	)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to create error metric, errors will not be counted", err)
+
+		return noop.Int64Counter{}
+	}
+
+	return counter
+}
+
+// errorCode returns the code err is returned to clients with: the code of an AppErr, a context error,
+// or a Connect error, and codes.Unknown for any other error.
+func errorCode(err error) codes.Code {
+	var appErr *AppErr
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	if code, ok := ContextErrorCode(err); ok {
+		return code
+	}
+
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return connectErr.Code()
+	}
+
+	return codes.Unknown
+}
+
+// ContextErrorCode returns codes.Canceled or codes.DeadlineExceeded if err is or wraps
+// context.Canceled or context.DeadlineExceeded, the codes the interceptor returns such errors with
+// when they are not AppErrs.
+func ContextErrorCode(err error) (codes.Code, bool) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, true
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, true
+	default:
+		return codes.Unknown, false
+	}
+}
+
 // handleError converts AppErr to Connect error and logs server errors.
-func handleError(ctx context.Context, err error, logger *logging.Logger) error {
+func handleError(
+	ctx context.Context,
+	req connect.AnyRequest,
+	err error,
+	logger *logging.Logger,
+	o *interceptorOptions,
+) error {
 	if err == nil {
 		return nil
 	}
 
 	var appErr *AppErr
 	if !errors.As(err, &appErr) {
-		// For non-AppErr errors, treat as unknown error
+		// A context error returned as is means the client canceled or the deadline passed,
+		// which are client errors, so they are neither logged nor recorded on the span
+		if code, ok := ContextErrorCode(err); ok {
+			return connect.NewError(code, errors.New(code.String()))
+		}
+
+		// Handlers return Connect errors themselves, e.g. for missing request fields
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			return handleConnectError(ctx, req, connectErr, logger, o)
+		}
+
+		// For other non-AppErr errors, treat as unknown error
+		if o.errorReferences {
+			return referencedError(ctx, req, connect.CodeUnknown, "Unhandled error occurred", err, logger)
+		}
+
 		logger.Error(ctx, "Unhandled error occurred", err)
-		return connect.NewError(connect.CodeUnknown, err)
+		recordSpanError(ctx, err)
+		return connect.NewError(connect.CodeUnknown, errors.New(clientMessage(connect.CodeUnknown)))
+	}
+
+	if IsServerError(appErr.Code) && o.errorReferences {
+		return referencedError(ctx, req, appErr.Code, "Server error occurred", appErr, logger)
 	}
 
 	// Check if this is a client error (4xx) or server error (5xx)
 	if IsServerError(appErr.Code) {
 		// Log server errors with full context
 		logger.Error(ctx, "Server error occurred", appErr)
+		recordSpanError(ctx, appErr)
 	}
 
-	// Convert AppErr to Connect error
+	// Convert AppErr to Connect error. Server error messages describe internals,
+	// so clients get a generic message while the logs above keep the detailed one.
 	connectErr := connect.NewError(appErr.Code, appErr)
+	if IsServerError(appErr.Code) {
+		connectErr = connect.NewError(appErr.Code, errors.New(clientMessage(appErr.Code)))
+	}
 
 	// Add structured attributes as error details if available
 	// Convert slog.Attr to Connect error details
@@ -55,9 +205,115 @@ func handleError(ctx context.Context, err error, logger *logging.Logger) error {
 		}
 	}
 
+	// Send field violations as a google.rpc.BadRequest detail so clients can parse them
+	if len(appErr.Violations) > 0 {
+		detail, err := newBadRequestDetail(appErr.Violations)
+		if err != nil {
+			logger.Error(ctx, "Failed to create error detail", err)
+		} else {
+			connectErr.AddDetail(detail)
+		}
+	}
+
 	return connectErr
 }
 
+// handleConnectError returns a Connect error returned by a handler with its code, message, and details
+// if it is a client error. A server error is logged and sanitized like an AppErr of the same code.
+func handleConnectError(
+	ctx context.Context,
+	req connect.AnyRequest,
+	connectErr *connect.Error,
+	logger *logging.Logger,
+	o *interceptorOptions,
+) error {
+	if !IsServerError(connectErr.Code()) {
+		return connectErr
+	}
+
+	if o.errorReferences {
+		return referencedError(ctx, req, connectErr.Code(), "Server error occurred", connectErr, logger)
+	}
+
+	logger.Error(ctx, "Server error occurred", connectErr)
+	recordSpanError(ctx, connectErr)
+
+	return connect.NewError(connectErr.Code(), errors.New(clientMessage(connectErr.Code())))
+}
+
+// referencedError logs err with a reference and returns a Connect error that carries only the reference.
+func referencedError(
+	ctx context.Context,
+	req connect.AnyRequest,
+	code codes.Code,
+	msg string,
+	err error,
+	logger *logging.Logger,
+) error {
+	reference := errorReference(req)
+
+	logger.Error(ctx, msg, err, attr.ErrorReference(reference))
+	recordSpanError(ctx, err)
+
+	return connect.NewError(code, fmt.Errorf("%s, reference: %s", clientMessage(code), reference))
+}
+
+// maxRequestIDLength bounds the length of request IDs used as error references.
+const maxRequestIDLength = 64
+
+// errorReference returns the reference for a server error: the request ID if the client sent a valid one,
+// otherwise a random ID. The request ID is echoed to the client and written to the logs, so only short IDs
+// of letters, digits, '-' and '_' are used.
+func errorReference(req connect.AnyRequest) string {
+	if req != nil && req.Header() != nil {
+		if requestID := req.Header().Get("X-Request-Id"); isValidRequestID(requestID) {
+			return requestID
+		}
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // Never returns an error
+
+	return hex.EncodeToString(b)
+}
+
+// isValidRequestID reports whether id is a non-empty request ID of at most maxRequestIDLength
+// letters, digits, '-' and '_'.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// clientMessage returns the generic client-facing message for a server error code.
+func clientMessage(code codes.Code) string {
+	switch code {
+	case codes.Unavailable:
+		return "service unavailable"
+	case codes.Unimplemented:
+		return "not implemented"
+	default:
+		return "internal server error"
+	}
+}
+
+// recordSpanError records err on the span from context and marks the span as failed.
+func recordSpanError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+}
+
 // IsServerError determines if a status code represents a server error (5xx).
 // Client errors (4xx) are not logged, while server errors (5xx) are logged.
 func IsServerError(code codes.Code) bool {
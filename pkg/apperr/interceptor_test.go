@@ -3,12 +3,22 @@ package apperr_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"testing"
 
 	"connectrpc.com/connect"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr"
 	"github.com/pannpers/go-backend-scaffold/pkg/apperr/codes"
@@ -81,6 +91,28 @@ func TestInterceptor(t *testing.T) {
 				metadata:        map[string]string{}, // No metadata for non-AppErr errors
 			},
 		},
+		{
+			name: "convert canceled context error to Canceled without logging",
+			args: args{
+				err: fmt.Errorf("failed to get user: %w", context.Canceled),
+			},
+			want: want{
+				connectCode:     connect.CodeCanceled,
+				loggedErrString: "",
+				metadata:        map[string]string{},
+			},
+		},
+		{
+			name: "convert deadline exceeded context error to DeadlineExceeded without logging",
+			args: args{
+				err: context.DeadlineExceeded,
+			},
+			want: want{
+				connectCode:     connect.CodeDeadlineExceeded,
+				loggedErrString: "",
+				metadata:        map[string]string{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -147,6 +179,387 @@ func TestInterceptor(t *testing.T) {
 	}
 }
 
+func TestInterceptor_SanitizesServerErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		err           error
+		wantClientMsg string
+		wantLoggedMsg string
+	}{
+		{
+			name:          "replace server error message with generic message",
+			err:           apperr.New(codes.Internal, "failed to query users: relation \"users\" does not exist"),
+			wantClientMsg: "internal server error",
+			wantLoggedMsg: `relation \"users\" does not exist`,
+		},
+		{
+			name:          "replace unavailable error message with generic message",
+			err:           apperr.New(codes.Unavailable, "connection pool exhausted"),
+			wantClientMsg: "service unavailable",
+			wantLoggedMsg: "connection pool exhausted",
+		},
+		{
+			name:          "replace non-AppErr error message with generic message",
+			err:           errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			wantClientMsg: "internal server error",
+			wantLoggedMsg: "dial tcp 10.0.0.5:5432: connection refused",
+		},
+		{
+			name:          "replace Connect server error message with generic message",
+			err:           connect.NewError(connect.CodeUnavailable, errors.New("upstream 10.0.0.7 refused the connection")),
+			wantClientMsg: "service unavailable",
+			wantLoggedMsg: "upstream 10.0.0.7 refused the connection",
+		},
+		{
+			name:          "keep Connect client error message",
+			err:           connect.NewError(connect.CodeInvalidArgument, errors.New("user_id is required")),
+			wantClientMsg: "user_id is required",
+			wantLoggedMsg: "",
+		},
+		{
+			name:          "keep client error message",
+			err:           apperr.New(codes.InvalidArgument, "user ID cannot be empty"),
+			wantClientMsg: "user ID cannot be empty (invalid_argument)",
+			wantLoggedMsg: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logBuffer := &bytes.Buffer{}
+			logger := logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON))
+			interceptor := apperr.NewInterceptor(logger)
+
+			mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				return nil, tt.err
+			}
+
+			_, err := interceptor(mockHandler)(context.Background(), connect.NewRequest(&struct{}{}))
+
+			var connectErr *connect.Error
+			require.True(t, errors.As(err, &connectErr))
+			assert.Equal(t, tt.wantClientMsg, connectErr.Message())
+
+			if tt.wantLoggedMsg == "" {
+				assert.Empty(t, logBuffer.String())
+
+				return
+			}
+
+			assert.Contains(t, logBuffer.String(), tt.wantLoggedMsg)
+			assert.NotContains(t, connectErr.Error(), tt.wantLoggedMsg)
+		})
+	}
+}
+
+func TestInterceptor_ConnectClientError(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := &bytes.Buffer{}
+	logger := logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON))
+	interceptor := apperr.NewInterceptor(logger, apperr.WithErrorReferences())
+
+	handlerErr := connect.NewError(connect.CodeInvalidArgument, errors.New("user_id is required"))
+	detail, err := connect.NewErrorDetail(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "user_id", Description: "is required"}},
+	})
+	require.NoError(t, err)
+	handlerErr.AddDetail(detail)
+
+	mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		return nil, handlerErr
+	}
+
+	_, err = interceptor(mockHandler)(context.Background(), connect.NewRequest(&struct{}{}))
+
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	assert.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+	assert.Equal(t, "user_id is required", connectErr.Message())
+	assert.Len(t, connectErr.Details(), 1)
+	assert.Empty(t, logBuffer.String(), "client errors must not be logged")
+}
+
+func TestInterceptor_ErrorReferences(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		err           error
+		requestID     string
+		wantCode      codes.Code
+		wantMessage   string
+		wantReference string // empty if a random reference is expected
+		wantLoggedMsg string
+	}{
+		{
+			name: "reference server error by request ID",
+			err: apperr.New(codes.Internal, "failed to query users: relation \"users\" does not exist",
+				slog.String("table", "users"),
+			),
+			requestID:     "req-123",
+			wantCode:      codes.Internal,
+			wantMessage:   "internal server error",
+			wantReference: "req-123",
+			wantLoggedMsg: `relation \"users\" does not exist`,
+		},
+		{
+			name:          "reference server error by random ID without request ID",
+			err:           apperr.New(codes.Unavailable, "connection pool exhausted"),
+			wantCode:      codes.Unavailable,
+			wantMessage:   "service unavailable",
+			wantLoggedMsg: "connection pool exhausted",
+		},
+		{
+			name:          "reference server error by random ID when request ID has invalid characters",
+			err:           apperr.New(codes.Internal, "connection pool exhausted"),
+			requestID:     "req 123<script>",
+			wantCode:      codes.Internal,
+			wantMessage:   "internal server error",
+			wantLoggedMsg: "connection pool exhausted",
+		},
+		{
+			name:          "reference server error by random ID when request ID is too long",
+			err:           apperr.New(codes.Internal, "connection pool exhausted"),
+			requestID:     strings.Repeat("a", 65),
+			wantCode:      codes.Internal,
+			wantMessage:   "internal server error",
+			wantLoggedMsg: "connection pool exhausted",
+		},
+		{
+			name:          "reference non-AppErr error",
+			err:           errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			requestID:     "req_456",
+			wantCode:      codes.Unknown,
+			wantMessage:   "internal server error",
+			wantReference: "req_456",
+			wantLoggedMsg: "dial tcp 10.0.0.5:5432: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logBuffer := &bytes.Buffer{}
+			logger := logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON))
+			interceptor := apperr.NewInterceptor(logger, apperr.WithErrorReferences())
+
+			mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				return nil, tt.err
+			}
+
+			req := connect.NewRequest(&struct{}{})
+			if tt.requestID != "" {
+				req.Header().Set("X-Request-Id", tt.requestID)
+			}
+
+			_, err := interceptor(mockHandler)(context.Background(), req)
+
+			var connectErr *connect.Error
+			require.True(t, errors.As(err, &connectErr))
+			assert.Equal(t, tt.wantCode, connectErr.Code())
+			assert.Empty(t, connectErr.Meta().Values("table"), "server error metadata must not reach clients")
+
+			reference, ok := strings.CutPrefix(connectErr.Message(), tt.wantMessage+", reference: ")
+			require.True(t, ok, "want generic message, got %q", connectErr.Message())
+			require.NotEmpty(t, reference)
+			if tt.wantReference != "" {
+				assert.Equal(t, tt.wantReference, reference)
+			} else {
+				assert.NotEqual(t, tt.requestID, reference)
+				assert.Regexp(t, "^[0-9a-f]{32}$", reference)
+			}
+
+			var logEntry map[string]any
+			require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &logEntry))
+			assert.Equal(t, reference, logEntry["error_reference"])
+			assert.Contains(t, logBuffer.String(), tt.wantLoggedMsg)
+			assert.NotContains(t, connectErr.Error(), tt.wantLoggedMsg)
+		})
+	}
+}
+
+func TestInterceptor_ErrorReferences_ClientError(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := &bytes.Buffer{}
+	logger := logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON))
+	interceptor := apperr.NewInterceptor(logger, apperr.WithErrorReferences())
+
+	mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		return nil, apperr.New(codes.InvalidArgument, "user ID cannot be empty")
+	}
+
+	_, err := interceptor(mockHandler)(context.Background(), connect.NewRequest(&struct{}{}))
+
+	// Client errors keep their message, since it tells the client what to fix
+	var connectErr *connect.Error
+	require.True(t, errors.As(err, &connectErr))
+	assert.Equal(t, "user ID cannot be empty (invalid_argument)", connectErr.Message())
+	assert.Empty(t, logBuffer.String())
+}
+
+func TestInterceptor_RecordsSpanError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus otelcodes.Code
+		wantEvent  bool
+	}{
+		{
+			name:       "record server error on span",
+			err:        apperr.New(codes.Internal, "database error"),
+			wantStatus: otelcodes.Error,
+			wantEvent:  true,
+		},
+		{
+			name:       "record non-AppErr error on span",
+			err:        errors.New("unexpected error"),
+			wantStatus: otelcodes.Error,
+			wantEvent:  true,
+		},
+		{
+			name:       "leave span status unset for client error",
+			err:        apperr.New(codes.NotFound, "user not found"),
+			wantStatus: otelcodes.Unset,
+			wantEvent:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			ctx, span := provider.Tracer("test").Start(context.Background(), "handler")
+
+			interceptor := apperr.NewInterceptor(logging.New(logging.WithWriter(&bytes.Buffer{})))
+			mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				return nil, tt.err
+			}
+
+			_, err := interceptor(mockHandler)(ctx, connect.NewRequest(&struct{}{}))
+			require.Error(t, err)
+
+			span.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.wantStatus, spans[0].Status().Code)
+
+			if !tt.wantEvent {
+				assert.Empty(t, spans[0].Events())
+
+				return
+			}
+
+			require.Len(t, spans[0].Events(), 1)
+			assert.Equal(t, "exception", spans[0].Events()[0].Name)
+			assert.Contains(t, spans[0].Status().Description, tt.err.Error())
+		})
+	}
+}
+
+func TestInterceptor_ErrorMetrics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		errs []error
+		want map[string]int64 // error counts by code label
+	}{
+		{
+			name: "count client and server errors by code",
+			errs: []error{
+				apperr.New(codes.NotFound, "user not found"),
+				apperr.New(codes.NotFound, "post not found"),
+				apperr.New(codes.Internal, "database error"),
+			},
+			want: map[string]int64{"not_found": 2, "internal": 1},
+		},
+		{
+			name: "count non-AppErr error as unknown",
+			errs: []error{errors.New("unexpected error")},
+			want: map[string]int64{"unknown": 1},
+		},
+		{
+			name: "count context errors by their code",
+			errs: []error{context.Canceled, fmt.Errorf("query failed: %w", context.DeadlineExceeded)},
+			want: map[string]int64{"canceled": 1, "deadline_exceeded": 1},
+		},
+		{
+			name: "count nothing for successful calls",
+			errs: []error{nil},
+			want: map[string]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := sdkmetric.NewManualReader()
+			provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+			interceptor := apperr.NewInterceptor(logging.New(logging.WithWriter(&bytes.Buffer{})),
+				apperr.WithErrorMetrics(provider.Meter("test")),
+			)
+
+			for _, err := range tt.errs {
+				mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+					return nil, err
+				}
+
+				_, _ = interceptor(mockHandler)(context.Background(), connect.NewRequest(&struct{}{}))
+			}
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(context.Background(), &rm))
+
+			got := make(map[string]int64)
+
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					if m.Name != "rpc.server.errors" {
+						continue
+					}
+
+					sum, ok := m.Data.(metricdata.Sum[int64])
+					require.True(t, ok)
+
+					for _, dp := range sum.DataPoints {
+						code, _ := dp.Attributes.Value("code")
+						got[code.AsString()] = dp.Value
+					}
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInterceptor_ErrorMetrics_NoMeter(t *testing.T) {
+	t.Parallel()
+
+	interceptor := apperr.NewInterceptor(logging.New(logging.WithWriter(&bytes.Buffer{})), apperr.WithErrorMetrics(nil))
+	mockHandler := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		return nil, apperr.New(codes.NotFound, "user not found")
+	}
+
+	_, err := interceptor(mockHandler)(context.Background(), connect.NewRequest(&struct{}{}))
+	assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+}
+
 func TestIsServerError(t *testing.T) {
 	t.Parallel()
 
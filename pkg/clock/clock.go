@@ -0,0 +1,72 @@
+// Package clock provides an abstraction over the current time so that time-dependent
+// behavior can be tested deterministically.
+//
+// Production code uses the real clock:
+//
+//	c := clock.New()
+//	now := c.Now()
+//
+// Tests use a fake clock that only moves when told to:
+//
+//	c := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+//	c.Advance(time.Hour)
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is a Clock backed by time.Now.
+type realClock struct{}
+
+// New returns a Clock that reports the system time.
+func New() Clock {
+	return realClock{}
+}
+
+// Now returns the current system time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Fake is a Clock that reports a fixed time until it is changed.
+// It is safe for concurrent use.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake returns a fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the time the fake clock is set to.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Set sets the fake clock to now.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+}
+
+// Advance moves the fake clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
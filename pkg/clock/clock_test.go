@@ -0,0 +1,38 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/clock"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	got := clock.New().Now()
+	after := time.Now()
+
+	assert.False(t, got.Before(before), "clock reported a time before the call")
+	assert.False(t, got.After(after), "clock reported a time after the call")
+}
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	assert.Equal(t, start, c.Now())
+	assert.Equal(t, start, c.Now(), "fake clock must not move on its own")
+
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+
+	later := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Set(later)
+	assert.Equal(t, later, c.Now())
+}
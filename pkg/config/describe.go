@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldDescriptor describes an environment variable supported by Config.
+type FieldDescriptor struct {
+	// Name is the environment variable name, including the prefix
+	Name string `json:"name"`
+
+	// Type is the Go type the value is parsed into, e.g. "int" or "time.Duration"
+	Type string `json:"type"`
+
+	// Default is the value used when the variable is unset, empty if there is none
+	Default string `json:"default,omitempty"`
+
+	// Required reports whether loading fails when the variable is unset.
+	// Secrets are required outside development only, see Load.
+	Required bool `json:"required"`
+}
+
+// Describe reflects over the Config struct tags and returns a descriptor for every supported
+// environment variable with the given prefix, in declaration order.
+//
+// Example:
+//
+//	for _, field := range config.Describe("APP") {
+//		fmt.Printf("%s (default: %s)\n", field.Name, field.Default)
+//	}
+func Describe(prefix string) []FieldDescriptor {
+	fields := describeStruct(reflect.TypeOf(Config{}), strings.ToUpper(prefix))
+
+	secretKeys := make(map[string]bool)
+	for _, secret := range (&Config{}).secrets() {
+		secretKeys[envKey(prefix, secret.key)] = true
+	}
+
+	for i := range fields {
+		if secretKeys[fields[i].Name] {
+			fields[i].Required = true
+		}
+	}
+
+	return fields
+}
+
+// describeStruct returns the descriptors of the envconfig-tagged fields of t, recursing into nested structs.
+func describeStruct(t reflect.Type, prefix string) []FieldDescriptor {
+	var fields []FieldDescriptor
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		key, ok := field.Tag.Lookup("envconfig")
+		if !ok {
+			continue
+		}
+
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, describeStruct(field.Type, key)...)
+
+			continue
+		}
+
+		fields = append(fields, FieldDescriptor{
+			Name:     key,
+			Type:     field.Type.String(),
+			Default:  field.Tag.Get("default"),
+			Required: field.Tag.Get("required") == "true",
+		})
+	}
+
+	return fields
+}
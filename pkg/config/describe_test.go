@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	fields := make(map[string]FieldDescriptor)
+	for _, field := range Describe("APP") {
+		fields[field.Name] = field
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want FieldDescriptor
+	}{
+		{
+			name: "report database name as required",
+			key:  "APP_DATABASE_NAME",
+			want: FieldDescriptor{Name: "APP_DATABASE_NAME", Type: "string", Required: true},
+		},
+		{
+			name: "report server port default",
+			key:  "APP_SERVER_PORT",
+			want: FieldDescriptor{Name: "APP_SERVER_PORT", Type: "int", Default: "8080"},
+		},
+		{
+			name: "report secrets as required",
+			key:  "APP_DATABASE_PASSWORD",
+			want: FieldDescriptor{Name: "APP_DATABASE_PASSWORD", Type: "string", Required: true},
+		},
+		{
+			name: "report top-level fields",
+			key:  "APP_SHUTDOWN_TIMEOUT",
+			want: FieldDescriptor{Name: "APP_SHUTDOWN_TIMEOUT", Type: "time.Duration", Default: "30s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, fields[tt.key])
+		})
+	}
+}
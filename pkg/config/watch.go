@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Watch reloads the configuration from environment variables whenever the process receives SIGHUP.
+// The onChange callback is invoked with the new configuration only if it passes Validate;
+// invalid reloads are reported to onError and otherwise ignored, so the current configuration stays in effect.
+// The returned function stops watching and is safe to call more than once.
+//
+// Example:
+//
+//	stop := config.Watch("APP", func(cfg *config.Config) {
+//		// Apply the reloaded configuration
+//	}, func(err error) {
+//		logger.Warn(ctx, "Ignoring configuration reload", slog.String("error", err.Error()))
+//	})
+//	defer stop()
+func Watch(prefix string, onChange func(*Config), onError func(error)) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				cfg, err := reload(prefix)
+				if err != nil {
+					onError(err)
+
+					continue
+				}
+
+				onChange(cfg)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// reload loads and validates the configuration.
+func reload(prefix string) (*Config, error) {
+	cfg, err := Load(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatch is not parallel because it modifies environment variables and signals the test process.
+func TestWatch(t *testing.T) {
+	const prefix = "WATCH"
+
+	t.Setenv("WATCH_DATABASE_NAME", "testdb")
+	t.Setenv("WATCH_DATABASE_USER", "testuser")
+	t.Setenv("WATCH_DATABASE_PASSWORD", "testpass")
+	t.Setenv("WATCH_LOGGING_LEVEL", "info")
+
+	changes := make(chan *Config, 1)
+	errs := make(chan error, 1)
+
+	stop := Watch(prefix, func(cfg *Config) { changes <- cfg }, func(err error) { errs <- err })
+	t.Cleanup(stop)
+
+	// An invalid reload is reported and does not invoke the callback
+	t.Setenv("WATCH_LOGGING_LEVEL", "verbose")
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "invalid log level: verbose")
+	case cfg := <-changes:
+		t.Fatalf("unexpected reload with invalid configuration: %+v", cfg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	// A valid reload invokes the callback with the new values
+	t.Setenv("WATCH_LOGGING_LEVEL", "debug")
+	t.Setenv("WATCH_SERVER_PORT", "9090")
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case cfg := <-changes:
+		assert.Equal(t, "debug", cfg.Logging.Level)
+		assert.Equal(t, 9090, cfg.Server.Port)
+	case err := <-errs:
+		t.Fatalf("unexpected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+}
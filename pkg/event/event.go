@@ -0,0 +1,95 @@
+// Package event provides a minimal in-process event bus for domain events.
+//
+// Subscribers register a handler for an event name and are called asynchronously,
+// each in its own goroutine, when an event with that name is published:
+//
+//	bus := event.NewBus(logger)
+//	bus.Subscribe(entity.UserCreatedEvent, func(ctx context.Context, e event.Event) {
+//		created := e.(entity.UserCreated)
+//		// React to the new user
+//	})
+//
+//	bus.Publish(ctx, entity.UserCreated{User: user})
+//
+// A panicking handler is recovered and logged without affecting other handlers.
+// Events are not persisted, so they are lost if the process stops before delivery;
+// call Close on shutdown to wait for in-flight deliveries.
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/safego"
+)
+
+// Event is a domain event identified by its name.
+type Event interface {
+	EventName() string
+}
+
+// Handler handles a published event.
+type Handler func(ctx context.Context, e Event)
+
+// Bus delivers published events to the handlers subscribed to their name.
+type Bus struct {
+	logger *logging.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+
+	wg sync.WaitGroup
+}
+
+// NewBus creates an event bus that logs recovered handler panics with logger.
+func NewBus(logger *logging.Logger) *Bus {
+	return &Bus{
+		logger:   logger,
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe registers h to be called for every event published with the given name.
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = append(b.handlers[name], h)
+}
+
+// Publish delivers e asynchronously to every handler subscribed to its name and returns immediately.
+// Handlers receive a context that keeps the values of ctx but is not canceled with it,
+// since publishers typically return, and cancel their request context, before delivery.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.EventName()]
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+
+	for _, h := range handlers {
+		b.wg.Add(1)
+
+		go b.deliver(ctx, h, e)
+	}
+}
+
+// deliver calls h with e, recovering and logging a panic so it does not crash the process.
+// Done is deferred first, so a panic is logged before Close returns.
+func (b *Bus) deliver(ctx context.Context, h Handler, e Event) {
+	defer b.wg.Done()
+
+	defer safego.Recover(ctx, b.logger, slog.String("event", e.EventName()))
+
+	h(ctx, e)
+}
+
+// Close waits for in-flight deliveries to finish.
+// Call it after publishers have stopped, e.g. once the server has shut down.
+func (b *Bus) Close() error {
+	b.wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package event_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/event"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+)
+
+type testEvent struct {
+	name string
+}
+
+func (e testEvent) EventName() string {
+	return e.name
+}
+
+type ctxKey struct{}
+
+func TestBus_Publish(t *testing.T) {
+	t.Parallel()
+
+	bus := event.NewBus(logging.New())
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+
+	record := func(subscriber string) event.Handler {
+		return func(ctx context.Context, e event.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			received = append(received, subscriber+":"+e.EventName()+":"+ctx.Value(ctxKey{}).(string))
+		}
+	}
+
+	bus.Subscribe("created", record("a"))
+	bus.Subscribe("created", record("b"))
+	bus.Subscribe("deleted", record("c"))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+
+	bus.Publish(ctx, testEvent{name: "created"})
+
+	// Handlers must not be affected by the publisher canceling its context
+	cancel()
+
+	require.NoError(t, bus.Close())
+
+	assert.ElementsMatch(t, []string{"a:created:value", "b:created:value"}, received)
+}
+
+func TestBus_Publish_PanickingHandler(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := &bytes.Buffer{}
+	bus := event.NewBus(logging.New(logging.WithWriter(logBuffer), logging.WithFormat(logging.FormatJSON)))
+
+	delivered := make(chan struct{}, 1)
+
+	bus.Subscribe("created", func(context.Context, event.Event) {
+		panic("subscriber failed")
+	})
+	bus.Subscribe("created", func(context.Context, event.Event) {
+		delivered <- struct{}{}
+	})
+
+	bus.Publish(context.Background(), testEvent{name: "created"})
+
+	require.NoError(t, bus.Close())
+
+	assert.Len(t, delivered, 1, "expected the other subscriber to receive the event")
+	assert.Contains(t, logBuffer.String(), "Panic recovered in goroutine")
+	assert.Contains(t, logBuffer.String(), "subscriber failed")
+	assert.Contains(t, logBuffer.String(), `"event":"created"`)
+	assert.Contains(t, logBuffer.String(), `"stacktrace":"goroutine`)
+}
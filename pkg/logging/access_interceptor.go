@@ -2,11 +2,14 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"connectrpc.com/connect"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
 )
 
 // NewAccessLogInterceptor creates a Connect interceptor that logs access information for all requests.
@@ -14,12 +17,24 @@ import (
 //
 // Sample log attributes:
 // - procedure: "/api.UserService/GetUser"
-// - method: "POST"
-// - status: "ok" or "invalid_argument"
+// - method: "POST", or the original method of transcoded requests, e.g. "DELETE"
+// - protocol: "connect", "grpc", or "grpcweb", the wire protocol of the client
+// - status: "ok" or "invalid_argument", and "canceled" or "deadline_exceeded" for context errors of handlers
 // - duration_ms: 150 (milliseconds as integer)
 // - user_agent: "connect-go/1.11.1 (go1.21.0)"
-// - remote_addr: "192.168.1.100" or "10.0.0.1"
-func NewAccessLogInterceptor(logger *Logger) connect.UnaryInterceptorFunc {
+// - remote_addr: "192.168.1.100" or "10.0.0.1", from X-Forwarded-For, X-Real-IP, or the peer of direct connections
+// - result_count: 20, only if the handler called SetResultCount
+// - has_next_page: true, only if the handler called SetHasNextPage
+// - slow: true, only if the request took longer than the WithSlowThreshold threshold
+//
+// All requests are logged at Info unless WithStatusClassLevels or WithSlowThreshold is given.
+func NewAccessLogInterceptor(logger *Logger, opts ...AccessLogOption) connect.UnaryInterceptorFunc {
+	o := defaultAccessLogOptions()
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return func(next connect.UnaryFunc) connect.UnaryFunc {
 		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
 			start := time.Now()
@@ -34,37 +49,157 @@ func NewAccessLogInterceptor(logger *Logger) connect.UnaryInterceptorFunc {
 				if remoteAddr == "" {
 					remoteAddr = header.Get("X-Real-IP")
 				}
+				// Set by REST transcoding in front of the handler to report the original method, e.g. DELETE
 				method = header.Get("X-Http-Method")
-				if method == "" {
-					method = http.MethodPost // Connect uses POST by default
-				}
 			}
 
+			if remoteAddr == "" {
+				// Direct connections have no proxy headers, so use the address of the connection itself
+				remoteAddr = peerHost(req.Peer().Addr)
+			}
+
+			if method == "" {
+				method = req.HTTPMethod() // GET for Connect GET requests of side-effect-free procedures
+			}
+
+			if method == "" {
+				method = http.MethodPost // Connect uses POST by default
+			}
+
+			ctx, res := withResult(ctx, start)
+
 			resp, err := next(ctx, req)
 
-			durationMs := time.Since(start).Milliseconds()
+			duration := time.Since(start)
 
-			// Determine status from error
+			// Determine status and log level from error
 			status := "ok"
+			level := o.successLevel
 			if err != nil {
-				if connectErr, ok := err.(*connect.Error); ok {
-					status = connectErr.Code().String()
-				} else {
-					status = "unknown"
-				}
+				code := errorCode(err)
+				status = code.String()
+				level = o.errorLevel(code)
 			}
 
 			// Log essential access information
-			logger.Info(ctx, "Access log",
-				slog.String("procedure", procedure),
-				slog.String("method", method),
-				slog.String("status", status),
-				slog.Int64("duration_ms", durationMs),
-				slog.String("user_agent", userAgent),
-				slog.String("remote_addr", remoteAddr),
-			)
+			attrs := []slog.Attr{
+				attr.Procedure(procedure),
+				slog.String(attr.Method, method),
+				attr.Protocol(req.Peer().Protocol),
+				attr.Status(status),
+				attr.DurationMs(duration.Milliseconds()),
+				attr.UserAgent(userAgent),
+				attr.RemoteAddr(remoteAddr),
+			}
+			attrs = append(attrs, res.attrs()...)
+
+			if o.slowThreshold > 0 && duration > o.slowThreshold {
+				attrs = append(attrs, attr.Slow(true))
+				level = max(level, slog.LevelWarn)
+			}
+
+			logger.log(ctx, level, "Access log", attrs...)
 
 			return resp, err
 		}
 	}
 }
+
+// errorCode returns the code of a Connect error, or of a context error that a handler returned as is
+// when the request was cancelled or timed out, and CodeUnknown for any other error.
+func errorCode(err error) connect.Code {
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return connectErr.Code()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return connect.CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.CodeDeadlineExceeded
+	default:
+		return connect.CodeUnknown
+	}
+}
+
+// peerHost returns the host of a peer address such as "192.168.1.100:54321", or addr itself if it has no port.
+func peerHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// AccessLogOption defines a function that configures the access log interceptor.
+type AccessLogOption func(*accessLogOptions)
+
+// accessLogOptions holds the access log interceptor configuration.
+type accessLogOptions struct {
+	successLevel     slog.Level
+	clientErrorLevel slog.Level
+	serverErrorLevel slog.Level
+	slowThreshold    time.Duration
+}
+
+// defaultAccessLogOptions returns the default access log options, which log every request at Info.
+func defaultAccessLogOptions() *accessLogOptions {
+	return &accessLogOptions{
+		successLevel:     slog.LevelInfo,
+		clientErrorLevel: slog.LevelInfo,
+		serverErrorLevel: slog.LevelInfo,
+	}
+}
+
+// WithStatusClassLevels sets the level of access logs by status class:
+// successful requests, client errors (e.g. invalid_argument, not_found), and server errors
+// (e.g. internal, unavailable).
+//
+// Example:
+//
+//	logging.NewAccessLogInterceptor(logger,
+//		logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError),
+//	)
+func WithStatusClassLevels(success, clientError, serverError slog.Level) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.successLevel = success
+		o.clientErrorLevel = clientError
+		o.serverErrorLevel = serverError
+	}
+}
+
+// WithSlowThreshold marks requests that take longer than threshold with slow: true and logs them
+// at Warn, or at their status class level if higher, so that slow requests stand out among
+// uninteresting successful ones. A non-positive threshold, the default, disables it.
+func WithSlowThreshold(threshold time.Duration) AccessLogOption {
+	return func(o *accessLogOptions) {
+		o.slowThreshold = threshold
+	}
+}
+
+// errorLevel returns the log level for a failed request with the given code.
+func (o *accessLogOptions) errorLevel(code connect.Code) slog.Level {
+	if isServerErrorCode(code) {
+		return o.serverErrorLevel
+	}
+
+	return o.clientErrorLevel
+}
+
+// isServerErrorCode reports whether the code represents a server error (5xx).
+// It classifies codes the same way as apperr.IsServerError, which cannot be imported here
+// because apperr depends on this package.
+func isServerErrorCode(code connect.Code) bool {
+	switch code {
+	case connect.CodeInternal,
+		connect.CodeUnknown,
+		connect.CodeDataLoss,
+		connect.CodeUnavailable,
+		connect.CodeUnimplemented:
+		return true
+	default:
+		return false
+	}
+}
@@ -3,15 +3,21 @@ package logging_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // mockMessage represents a simple message for testing.
@@ -120,20 +126,7 @@ func TestNewAccessLogInterceptor(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			var buf bytes.Buffer
-
-			// Create logger with buffer output and without time/duration for consistent testing
-			logger := logging.New(
-				logging.WithLevel(slog.LevelInfo),
-				logging.WithFormat(logging.FormatJSON),
-				logging.WithWriter(&buf),
-				logging.WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
-					if a.Key == slog.TimeKey || a.Key == "duration_ms" {
-						return slog.Attr{} // Discard time and duration attributes for test stability
-					}
-					return a
-				}),
-			)
+			logger, buf := logging.NewTestLogger()
 
 			// Create access log interceptor
 			interceptor := logging.NewAccessLogInterceptor(logger)
@@ -194,6 +187,7 @@ func TestNewAccessLogInterceptor(t *testing.T) {
 				"msg": "Access log",
 				"procedure": "%s",
 				"method": "%s",
+				"protocol": "",
 				"status": "%s",
 				"user_agent": "%s",
 				"remote_addr": "%s"
@@ -238,6 +232,16 @@ func TestAccessLogInterceptor_HeaderExtraction(t *testing.T) {
 			expectedRemoteAddr: "10.0.0.1",
 			expectedMethod:     "PUT",
 		},
+		{
+			name: "extract original method of transcoded DELETE request",
+			headers: map[string]string{
+				"User-Agent":    "curl/8.7.1",
+				"X-Http-Method": "DELETE",
+			},
+			expectedUserAgent:  "curl/8.7.1",
+			expectedRemoteAddr: "",
+			expectedMethod:     "DELETE",
+		},
 		{
 			name: "use default method when X-Http-Method is not present",
 			headers: map[string]string{
@@ -260,19 +264,7 @@ func TestAccessLogInterceptor_HeaderExtraction(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			var buf bytes.Buffer
-
-			logger := logging.New(
-				logging.WithLevel(slog.LevelInfo),
-				logging.WithFormat(logging.FormatJSON),
-				logging.WithWriter(&buf),
-				logging.WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
-					if a.Key == slog.TimeKey || a.Key == "duration_ms" {
-						return slog.Attr{}
-					}
-					return a
-				}),
-			)
+			logger, buf := logging.NewTestLogger()
 
 			interceptor := logging.NewAccessLogInterceptor(logger)
 
@@ -308,6 +300,7 @@ func TestAccessLogInterceptor_HeaderExtraction(t *testing.T) {
 				"msg": "Access log",
 				"procedure": "/api.UserService/GetUser",
 				"method": "%s",
+				"protocol": "",
 				"status": "ok",
 				"user_agent": "%s",
 				"remote_addr": "%s"
@@ -318,3 +311,526 @@ func TestAccessLogInterceptor_HeaderExtraction(t *testing.T) {
 		})
 	}
 }
+
+// TestAccessLogInterceptor_StatusClassLevels tests that the log level follows the status class.
+func TestAccessLogInterceptor_StatusClassLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []logging.AccessLogOption
+		err       error
+		wantLevel string
+	}{
+		{
+			name:      "log success at INFO by default",
+			err:       nil,
+			wantLevel: "INFO",
+		},
+		{
+			name:      "log server error at INFO by default",
+			err:       connect.NewError(connect.CodeInternal, errors.New("database error")),
+			wantLevel: "INFO",
+		},
+		{
+			name:      "log success at configured level",
+			opts:      []logging.AccessLogOption{logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError)},
+			err:       nil,
+			wantLevel: "INFO",
+		},
+		{
+			name:      "log client error at configured level",
+			opts:      []logging.AccessLogOption{logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError)},
+			err:       connect.NewError(connect.CodeNotFound, errors.New("user not found")),
+			wantLevel: "WARN",
+		},
+		{
+			name:      "log server error at configured level",
+			opts:      []logging.AccessLogOption{logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError)},
+			err:       connect.NewError(connect.CodeUnavailable, errors.New("database unavailable")),
+			wantLevel: "ERROR",
+		},
+		{
+			name:      "log non-connect error as server error",
+			opts:      []logging.AccessLogOption{logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError)},
+			err:       errors.New("unexpected error"),
+			wantLevel: "ERROR",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithLevel(slog.LevelDebug),
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithWriter(&buf),
+			)
+
+			interceptor := logging.NewAccessLogInterceptor(logger, tc.opts...)
+
+			next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return connect.NewResponse(&mockMessage{Value: "response"}), nil
+			}
+
+			_, _ = interceptor(next)(context.Background(), connect.NewRequest(&mockMessage{Value: "test"}))
+
+			assert.Contains(t, buf.String(), fmt.Sprintf(`"level":"%s"`, tc.wantLevel))
+		})
+	}
+}
+
+// TestAccessLogInterceptor_ContextErrors tests that context errors returned by handlers are logged with their status.
+func TestAccessLogInterceptor_ContextErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		ctx        func() (context.Context, context.CancelFunc)
+		wrap       func(err error) error
+		wantStatus string
+		wantLevel  string
+	}{
+		{
+			name:       "log canceled when client cancels request",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantStatus: "canceled",
+			wantLevel:  "WARN",
+		},
+		{
+			name: "log deadline_exceeded when request times out",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			},
+			wantStatus: "deadline_exceeded",
+			wantLevel:  "WARN",
+		},
+		{
+			name:       "log canceled for wrapped context error",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wrap:       func(err error) error { return fmt.Errorf("failed to get user: %w", err) },
+			wantStatus: "canceled",
+			wantLevel:  "WARN",
+		},
+		{
+			name: "log code of connect error wrapping context error",
+			ctx:  func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wrap: func(err error) error {
+				return connect.NewError(connect.CodeUnavailable, err)
+			},
+			wantStatus: "unavailable",
+			wantLevel:  "ERROR",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(logging.WithFormat(logging.FormatJSON), logging.WithWriter(&buf))
+
+			interceptor := logging.NewAccessLogInterceptor(logger,
+				logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError),
+			)
+
+			ctx, cancel := tc.ctx()
+			cancel()
+
+			next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				err := ctx.Err()
+				if tc.wrap != nil {
+					err = tc.wrap(err)
+				}
+
+				return nil, err
+			}
+
+			_, err := interceptor(next)(ctx, connect.NewRequest(&mockMessage{Value: "test"}))
+			require.Error(t, err)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+			assert.Equal(t, tc.wantStatus, entry["status"])
+			assert.Equal(t, tc.wantLevel, entry["level"])
+		})
+	}
+}
+
+// TestAccessLogInterceptor_SlowThreshold tests that requests slower than the threshold are logged at an elevated level.
+func TestAccessLogInterceptor_SlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []logging.AccessLogOption
+		delay     time.Duration
+		err       error
+		wantLevel string
+		wantSlow  bool
+	}{
+		{
+			name:      "log fast request at INFO without slow",
+			opts:      []logging.AccessLogOption{logging.WithSlowThreshold(time.Second)},
+			wantLevel: "INFO",
+		},
+		{
+			name:      "log slow request at WARN with slow",
+			opts:      []logging.AccessLogOption{logging.WithSlowThreshold(10 * time.Millisecond)},
+			delay:     20 * time.Millisecond,
+			wantLevel: "WARN",
+			wantSlow:  true,
+		},
+		{
+			name: "keep higher status class level of slow request",
+			opts: []logging.AccessLogOption{
+				logging.WithStatusClassLevels(slog.LevelInfo, slog.LevelWarn, slog.LevelError),
+				logging.WithSlowThreshold(10 * time.Millisecond),
+			},
+			delay:     20 * time.Millisecond,
+			err:       connect.NewError(connect.CodeInternal, errors.New("database error")),
+			wantLevel: "ERROR",
+			wantSlow:  true,
+		},
+		{
+			name:      "not mark slow request without threshold",
+			delay:     20 * time.Millisecond,
+			wantLevel: "INFO",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithLevel(slog.LevelDebug),
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithWriter(&buf),
+			)
+
+			interceptor := logging.NewAccessLogInterceptor(logger, tc.opts...)
+
+			next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				time.Sleep(tc.delay)
+
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return connect.NewResponse(&mockMessage{Value: "response"}), nil
+			}
+
+			_, _ = interceptor(next)(context.Background(), connect.NewRequest(&mockMessage{Value: "test"}))
+
+			var logEntry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &logEntry))
+
+			assert.Equal(t, tc.wantLevel, logEntry["level"])
+
+			if tc.wantSlow {
+				assert.Equal(t, true, logEntry["slow"])
+			} else {
+				assert.NotContains(t, logEntry, "slow")
+			}
+		})
+	}
+}
+
+// TestAccessLogInterceptor_ResultAnnotations tests that list results annotated by the handler are logged.
+func TestAccessLogInterceptor_ResultAnnotations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		annotate  func(ctx context.Context)
+		wantAttrs map[string]any
+	}{
+		{
+			name: "log result count and next page set by handler",
+			annotate: func(ctx context.Context) {
+				logging.SetResultCount(ctx, 20)
+				logging.SetHasNextPage(ctx, true)
+			},
+			wantAttrs: map[string]any{
+				"result_count":  float64(20),
+				"has_next_page": true,
+			},
+		},
+		{
+			name: "log zero result count",
+			annotate: func(ctx context.Context) {
+				logging.SetResultCount(ctx, 0)
+			},
+			wantAttrs: map[string]any{
+				"result_count": float64(0),
+			},
+		},
+		{
+			name:      "omit result attributes when handler does not set them",
+			annotate:  func(context.Context) {},
+			wantAttrs: map[string]any{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithWriter(&buf),
+			)
+
+			interceptor := logging.NewAccessLogInterceptor(logger)
+
+			next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				tc.annotate(ctx)
+
+				return connect.NewResponse(&mockMessage{Value: "response"}), nil
+			}
+
+			_, err := interceptor(next)(context.Background(), connect.NewRequest(&mockMessage{Value: "test"}))
+			require.NoError(t, err)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+			for _, key := range []string{"result_count", "has_next_page"} {
+				want, ok := tc.wantAttrs[key]
+				if !ok {
+					assert.NotContains(t, entry, key)
+
+					continue
+				}
+
+				assert.Equal(t, want, entry[key])
+			}
+		})
+	}
+}
+
+// TestAccessLogInterceptor_HTTPMethod tests that the actual HTTP method of served requests is logged.
+func TestAccessLogInterceptor_HTTPMethod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		clientOpts []connect.ClientOption
+		wantMethod string
+	}{
+		{
+			name:       "log GET for Connect GET requests",
+			clientOpts: []connect.ClientOption{connect.WithHTTPGet()},
+			wantMethod: http.MethodGet,
+		},
+		{
+			name:       "log POST for Connect POST requests",
+			clientOpts: nil,
+			wantMethod: http.MethodPost,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithWriter(&buf),
+			)
+
+			const procedure = "/test.v1.TestService/Get"
+
+			mux := http.NewServeMux()
+			mux.Handle(procedure, connect.NewUnaryHandler(procedure,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+				connect.WithInterceptors(logging.NewAccessLogInterceptor(logger)),
+			))
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+procedure,
+				append(tc.clientOpts, connect.WithIdempotency(connect.IdempotencyNoSideEffects))...,
+			)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.NoError(t, err)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, tc.wantMethod, entry["method"])
+		})
+	}
+}
+
+// TestAccessLogInterceptor_PeerAddr tests that the peer address is logged for direct connections without proxy headers.
+func TestAccessLogInterceptor_PeerAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		wantRemoteAddr string
+	}{
+		{
+			name:           "log peer address of direct connection",
+			header:         http.Header{},
+			wantRemoteAddr: "127.0.0.1",
+		},
+		{
+			name:           "prefer X-Forwarded-For over peer address",
+			header:         http.Header{"X-Forwarded-For": []string{"192.168.1.100"}},
+			wantRemoteAddr: "192.168.1.100",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithWriter(&buf),
+			)
+
+			const procedure = "/test.v1.TestService/Call"
+
+			mux := http.NewServeMux()
+			mux.Handle(procedure, connect.NewUnaryHandler(procedure,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				connect.WithInterceptors(logging.NewAccessLogInterceptor(logger)),
+			))
+
+			// httptest.NewServer listens on the loopback interface, so the client connects from 127.0.0.1
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+procedure)
+
+			req := connect.NewRequest(&emptypb.Empty{})
+			for key, values := range tc.header {
+				req.Header()[key] = values
+			}
+
+			_, err := client.CallUnary(context.Background(), req)
+			require.NoError(t, err)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, tc.wantRemoteAddr, entry["remote_addr"])
+		})
+	}
+}
+
+// TestAccessLogInterceptor_Protocol tests that the wire protocol of the client is logged.
+func TestAccessLogInterceptor_Protocol(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		clientOpts   []connect.ClientOption
+		wantProtocol string
+	}{
+		{
+			name:         "log Connect protocol",
+			wantProtocol: "connect",
+		},
+		{
+			name:         "log gRPC protocol",
+			clientOpts:   []connect.ClientOption{connect.WithGRPC()},
+			wantProtocol: "grpc",
+		},
+		{
+			name:         "log gRPC-Web protocol",
+			clientOpts:   []connect.ClientOption{connect.WithGRPCWeb()},
+			wantProtocol: "grpcweb",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithWriter(&buf),
+			)
+
+			const procedure = "/test.v1.TestService/Call"
+
+			mux := http.NewServeMux()
+			mux.Handle(procedure, connect.NewUnaryHandler(procedure,
+				func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				connect.WithInterceptors(logging.NewAccessLogInterceptor(logger)),
+			))
+
+			// gRPC requires HTTP/2, which the test server only negotiates over TLS
+			srv := httptest.NewUnstartedServer(mux)
+			srv.EnableHTTP2 = true
+			srv.StartTLS()
+			t.Cleanup(srv.Close)
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+procedure, tc.clientOpts...)
+
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.NoError(t, err)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, tc.wantProtocol, entry["protocol"])
+		})
+	}
+}
+
+// TestRequestDuration tests that handlers can read the duration measured by the access log.
+func TestRequestDuration(t *testing.T) {
+	t.Parallel()
+
+	_, ok := logging.RequestDuration(context.Background())
+	assert.False(t, ok, "no duration outside an access-logged request")
+
+	logger, _ := logging.NewTestLogger()
+
+	var got time.Duration
+
+	next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		time.Sleep(10 * time.Millisecond)
+
+		var ok bool
+		got, ok = logging.RequestDuration(ctx)
+		assert.True(t, ok)
+
+		return connect.NewResponse(&mockMessage{Value: "response"}), nil
+	}
+
+	_, err := logging.NewAccessLogInterceptor(logger)(next)(context.Background(), connect.NewRequest(&mockMessage{Value: "test"}))
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, got, 10*time.Millisecond)
+}
@@ -1,5 +1,9 @@
+// Package attr defines key names and typed constructors for commonly used slog.Attr values.
+// Prefer the constructors over slog.String with a literal key to keep key names consistent.
 package attr
 
+import "log/slog"
+
 // Key name for slog.Attr.
 const (
 	Address = "address"
@@ -8,4 +12,101 @@ const (
 	Request = "request"
 	SpanID  = "span_id"  // Following https://opentelemetry.io/docs/specs/semconv/general/naming/.
 	TraceID = "trace_id" // Following https://opentelemetry.io/docs/specs/semconv/general/naming/.
+
+	ConstraintKey     = "constraint"
+	DurationMsKey     = "duration_ms"
+	ErrorReferenceKey = "error_reference"
+	HasNextPageKey    = "has_next_page"
+	OperationKey      = "operation"
+	PostIDKey         = "post_id"
+	ProcedureKey      = "procedure"
+	ProtocolKey       = "protocol"
+	ReasonKey         = "reason"
+	RemoteAddrKey     = "remote_addr"
+	ResultCountKey    = "result_count"
+	SlowKey           = "slow"
+	StacktraceKey     = "stacktrace"
+	StatusKey         = "status"
+	UserAgentKey      = "user_agent"
+	UserIDKey         = "user_id"
 )
+
+// Constraint returns an attribute for the name of a violated database constraint, e.g. "posts_title_not_empty".
+func Constraint(name string) slog.Attr {
+	return slog.String(ConstraintKey, name)
+}
+
+// DurationMs returns an attribute for an elapsed duration in milliseconds.
+func DurationMs(ms int64) slog.Attr {
+	return slog.Int64(DurationMsKey, ms)
+}
+
+// ErrorReference returns an attribute for the reference of a server error that clients can report.
+func ErrorReference(reference string) slog.Attr {
+	return slog.String(ErrorReferenceKey, reference)
+}
+
+// HasNextPage returns an attribute for whether a list result has a next page.
+func HasNextPage(hasNext bool) slog.Attr {
+	return slog.Bool(HasNextPageKey, hasNext)
+}
+
+// Operation returns an attribute for the name of an operation, e.g. "UserRepository.Get".
+func Operation(name string) slog.Attr {
+	return slog.String(OperationKey, name)
+}
+
+// PostID returns an attribute for a post ID.
+func PostID(id string) slog.Attr {
+	return slog.String(PostIDKey, id)
+}
+
+// Procedure returns an attribute for a Connect procedure name, e.g. "/api.UserService/GetUser".
+func Procedure(procedure string) slog.Attr {
+	return slog.String(ProcedureKey, procedure)
+}
+
+// Protocol returns an attribute for the wire protocol of a request, e.g. "connect", "grpc", or "grpcweb".
+func Protocol(protocol string) slog.Attr {
+	return slog.String(ProtocolKey, protocol)
+}
+
+// Reason returns an attribute for the machine-readable reason of an error, e.g. "EMAIL_INVALID".
+func Reason(reason string) slog.Attr {
+	return slog.String(ReasonKey, reason)
+}
+
+// RemoteAddr returns an attribute for a client address.
+func RemoteAddr(addr string) slog.Attr {
+	return slog.String(RemoteAddrKey, addr)
+}
+
+// ResultCount returns an attribute for the number of items returned by a list call.
+func ResultCount(n int) slog.Attr {
+	return slog.Int(ResultCountKey, n)
+}
+
+// Slow returns an attribute for whether a request took longer than the slow request threshold.
+func Slow(slow bool) slog.Attr {
+	return slog.Bool(SlowKey, slow)
+}
+
+// Stacktrace returns an attribute for a formatted stack trace, e.g. of a recovered panic.
+func Stacktrace(stack string) slog.Attr {
+	return slog.String(StacktraceKey, stack)
+}
+
+// Status returns an attribute for a request status, e.g. "ok" or "not_found".
+func Status(status string) slog.Attr {
+	return slog.String(StatusKey, status)
+}
+
+// UserAgent returns an attribute for a client user agent.
+func UserAgent(userAgent string) slog.Attr {
+	return slog.String(UserAgentKey, userAgent)
+}
+
+// UserID returns an attribute for a user ID.
+func UserID(id string) slog.Attr {
+	return slog.String(UserIDKey, id)
+}
@@ -0,0 +1,105 @@
+package attr_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+func TestConstructors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		got  slog.Attr
+		want slog.Attr
+	}{
+		{
+			name: "Constraint",
+			got:  attr.Constraint("posts_title_not_empty"),
+			want: slog.String("constraint", "posts_title_not_empty"),
+		},
+		{
+			name: "DurationMs",
+			got:  attr.DurationMs(150),
+			want: slog.Int64("duration_ms", 150),
+		},
+		{
+			name: "ErrorReference",
+			got:  attr.ErrorReference("req-123"),
+			want: slog.String("error_reference", "req-123"),
+		},
+		{
+			name: "HasNextPage",
+			got:  attr.HasNextPage(true),
+			want: slog.Bool("has_next_page", true),
+		},
+		{
+			name: "Operation",
+			got:  attr.Operation("UserRepository.Get"),
+			want: slog.String("operation", "UserRepository.Get"),
+		},
+		{
+			name: "PostID",
+			got:  attr.PostID("post-123"),
+			want: slog.String("post_id", "post-123"),
+		},
+		{
+			name: "Procedure",
+			got:  attr.Procedure("/api.UserService/GetUser"),
+			want: slog.String("procedure", "/api.UserService/GetUser"),
+		},
+		{
+			name: "Protocol",
+			got:  attr.Protocol("grpc"),
+			want: slog.String("protocol", "grpc"),
+		},
+		{
+			name: "Reason",
+			got:  attr.Reason("EMAIL_INVALID"),
+			want: slog.String("reason", "EMAIL_INVALID"),
+		},
+		{
+			name: "RemoteAddr",
+			got:  attr.RemoteAddr("192.168.1.100"),
+			want: slog.String("remote_addr", "192.168.1.100"),
+		},
+		{
+			name: "ResultCount",
+			got:  attr.ResultCount(20),
+			want: slog.Int("result_count", 20),
+		},
+		{
+			name: "Slow",
+			got:  attr.Slow(true),
+			want: slog.Bool("slow", true),
+		},
+		{
+			name: "Status",
+			got:  attr.Status("not_found"),
+			want: slog.String("status", "not_found"),
+		},
+		{
+			name: "UserAgent",
+			got:  attr.UserAgent("connect-go/1.18.1"),
+			want: slog.String("user_agent", "connect-go/1.18.1"),
+		},
+		{
+			name: "UserID",
+			got:  attr.UserID("user-123"),
+			want: slog.String("user_id", "user-123"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want.Key, tt.got.Key)
+			assert.True(t, tt.want.Value.Equal(tt.got.Value), "want %v, got %v", tt.want.Value, tt.got.Value)
+		})
+	}
+}
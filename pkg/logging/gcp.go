@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"log/slog"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// Keys of the special fields recognized by Google Cloud Logging in structured JSON logs.
+// See https://cloud.google.com/logging/docs/structured-logging#special-payload-fields.
+const (
+	gcpSeverityKey = "severity"
+	gcpMessageKey  = "message"
+	gcpTraceKey    = "logging.googleapis.com/trace"
+	gcpSpanIDKey   = "logging.googleapis.com/spanId"
+)
+
+// gcpReplaceAttr renames the top-level attributes of a record to the fields Google Cloud Logging recognizes.
+// The trace field must be the resource name of the trace in projectID, so without a project ID
+// the trace ID is left in its standard attribute.
+func gcpReplaceAttr(projectID string, groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+
+	switch a.Key {
+	case slog.LevelKey:
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(gcpSeverityKey, gcpSeverity(level))
+		}
+
+		return slog.Attr{Key: gcpSeverityKey, Value: a.Value}
+	case slog.MessageKey:
+		return slog.Attr{Key: gcpMessageKey, Value: a.Value}
+	case attr.TraceID:
+		if projectID == "" {
+			return a
+		}
+
+		return slog.String(gcpTraceKey, gcpTraceName(projectID, a.Value.String()))
+	case attr.SpanID:
+		return slog.Attr{Key: gcpSpanIDKey, Value: a.Value}
+	default:
+		return a
+	}
+}
+
+// gcpTraceName returns the resource name of a trace in the format Google Cloud Logging links to Cloud Trace,
+// projects/<PROJECT_ID>/traces/<TRACE_ID>.
+func gcpTraceName(projectID, traceID string) string {
+	return "projects/" + projectID + "/traces/" + traceID
+}
+
+// gcpSeverity returns the Google Cloud Logging severity for a level.
+// Levels between the standard ones map to the severity of the standard level below them.
+func gcpSeverity(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "DEBUG"
+	case level < slog.LevelWarn:
+		return "INFO"
+	case level < slog.LevelError:
+		return "WARNING"
+	default:
+		return "ERROR"
+	}
+}
@@ -1,9 +1,11 @@
 package logging
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 )
 
 // Format represents the log output format.
@@ -14,8 +16,18 @@ const (
 	FormatJSON Format = iota
 	// FormatText specifies the human-readable text output format.
 	FormatText
+	// FormatGCP specifies the JSON output format with the field names of Google Cloud Logging:
+	// severity (DEBUG, INFO, WARNING, ERROR) instead of level, message instead of msg,
+	// and logging.googleapis.com/trace and logging.googleapis.com/spanId instead of trace_id and span_id.
+	// The trace is renamed only with the project set by WithGCPProjectID, since Cloud Logging expects
+	// its resource name, projects/<PROJECT_ID>/traces/<TRACE_ID>.
+	FormatGCP
 )
 
+// TimeEpochMillis is a special time format for WithTimeFormat that renders the time
+// as the number of milliseconds since the Unix epoch.
+const TimeEpochMillis = "epoch_millis"
+
 // DefaultLevel is the default logging level.
 const DefaultLevel = slog.LevelInfo
 
@@ -27,15 +39,29 @@ type options struct {
 	writer          io.Writer
 	level           slog.Level
 	format          Format
+	timeFormat      string
+	addSource       bool
+	sourcePrefix    string
 	replaceAttrFunc func(groups []string, a slog.Attr) slog.Attr
+	onError         OnErrorFunc
+	baggageKeys     []string
+	baseAttrs       []slog.Attr
+	traceAttrs      bool
+	gcpProjectID    string
+	handler         slog.Handler // nil to use the handler for format
 }
 
+// OnErrorFunc is called for every message logged with Logger.Error.
+type OnErrorFunc func(ctx context.Context, msg string, err error)
+
 // defaultOptions returns the default logger options.
 func defaultOptions() *options {
 	return &options{
-		writer: os.Stdout,
-		level:  DefaultLevel,
-		format: FormatText, // Default to human-readable text format.
+		writer:     os.Stdout,
+		level:      DefaultLevel,
+		format:     FormatText, // Default to human-readable text format.
+		traceAttrs: true,
+		// timeFormat is empty by default, meaning slog renders the time as RFC3339 with milliseconds.
 		// replaceAttrFunc is nil by default, meaning no attributes are replaced.
 	}
 }
@@ -56,16 +82,150 @@ func WithLevel(level slog.Level) Option {
 	}
 }
 
-// WithFormat sets the output format for the logger.
+// WithFormat sets the output format for the logger. An unknown format falls back to FormatJSON.
 func WithFormat(f Format) Option {
 	return func(o *options) {
 		o.format = f
 	}
 }
 
+// WithHandler sets the slog handler records are written to, e.g. a fan-out or third-party handler,
+// instead of the handler for the format. The level still applies and can be changed with SetLevel,
+// but the writer, format, time format, source, and WithReplaceAttr options do not, since they
+// configure the built-in handlers.
+func WithHandler(h slog.Handler) Option {
+	return func(o *options) {
+		o.handler = h
+	}
+}
+
+// WithGCPProjectID sets the Google Cloud project of the traces logged with FormatGCP, so that
+// logging.googleapis.com/trace holds their resource name and Cloud Logging links entries to Cloud Trace.
+func WithGCPProjectID(projectID string) Option {
+	return func(o *options) {
+		o.gcpProjectID = projectID
+	}
+}
+
+// WithTimeFormat sets the layout used to render the time of each record, e.g. time.RFC3339,
+// or TimeEpochMillis to render it as Unix milliseconds.
+// It is applied before the function set with WithReplaceAttr.
+func WithTimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = layout
+	}
+}
+
+// WithSource adds the file and line of the calling code to every record in the source attribute.
+func WithSource() Option {
+	return func(o *options) {
+		o.addSource = true
+	}
+}
+
+// WithTrimSourcePath trims prefix, e.g. the directory the binary was built in, from the file
+// of the source attribute added with WithSource, so that logs show repo-relative paths such as
+// "internal/usecase/user.go" instead of absolute build paths.
+// Files outside prefix, e.g. those of dependencies, keep their full path.
+// It is applied before the function set with WithReplaceAttr.
+func WithTrimSourcePath(prefix string) Option {
+	return func(o *options) {
+		o.sourcePrefix = prefix
+	}
+}
+
 // WithReplaceAttr sets the ReplaceAttr function for the slog handler.
 func WithReplaceAttr(f func(groups []string, a slog.Attr) slog.Attr) Option {
 	return func(o *options) {
 		o.replaceAttrFunc = f
 	}
 }
+
+// WithOnError sets a callback invoked by Logger.Error before the message is emitted,
+// e.g. to increment an error counter or send a notification. A panic in the callback is
+// recovered and logged, so it never reaches the caller.
+func WithOnError(f OnErrorFunc) Option {
+	return func(o *options) {
+		o.onError = f
+	}
+}
+
+// WithAttrsFromContext sets the OpenTelemetry baggage keys whose values are added as attributes
+// to every record, e.g. a tenant or user ID propagated across services.
+// Keys missing from the baggage of the context are omitted.
+func WithAttrsFromContext(baggageKeys ...string) Option {
+	return func(o *options) {
+		o.baggageKeys = baggageKeys
+	}
+}
+
+// WithTraceAttrs sets whether the trace and span IDs of the span in the context are added to every record,
+// which they are by default. Disable it where a log pipeline already injects the trace context.
+func WithTraceAttrs(enabled bool) Option {
+	return func(o *options) {
+		o.traceAttrs = enabled
+	}
+}
+
+// WithBaseAttrs sets attributes added to every record, such as the service name and version
+// that identify the source of logs aggregated from several services.
+func WithBaseAttrs(attrs ...slog.Attr) Option {
+	return func(o *options) {
+		o.baseAttrs = append(o.baseAttrs, attrs...)
+	}
+}
+
+// replaceAttr returns the ReplaceAttr function for the slog handler, combining the time format,
+// the source path trimming, the function set with WithReplaceAttr, and the field names of FormatGCP,
+// in that order. The function set with WithReplaceAttr therefore always sees the standard slog keys.
+// It returns nil if none is set.
+func (o *options) replaceAttr() func(groups []string, a slog.Attr) slog.Attr {
+	if o.timeFormat == "" && o.sourcePrefix == "" && o.format != FormatGCP {
+		return o.replaceAttrFunc
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		// Only the record time is formatted, not time attributes within groups
+		if o.timeFormat != "" && len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			a = formatTime(a, o.timeFormat)
+		}
+
+		if o.sourcePrefix != "" && len(groups) == 0 && a.Key == slog.SourceKey {
+			a = trimSourcePath(a, o.sourcePrefix)
+		}
+
+		if o.replaceAttrFunc != nil {
+			a = o.replaceAttrFunc(groups, a)
+		}
+
+		if o.format == FormatGCP {
+			a = gcpReplaceAttr(o.gcpProjectID, groups, a)
+		}
+
+		return a
+	}
+}
+
+// formatTime renders the time of a in the given layout.
+func formatTime(a slog.Attr, layout string) slog.Attr {
+	t := a.Value.Time()
+
+	if layout == TimeEpochMillis {
+		return slog.Int64(a.Key, t.UnixMilli())
+	}
+
+	return slog.String(a.Key, t.Format(layout))
+}
+
+// trimSourcePath trims prefix and the following separator from the file of the source attribute a.
+func trimSourcePath(a slog.Attr, prefix string) slog.Attr {
+	source, ok := a.Value.Any().(*slog.Source)
+	if !ok || !strings.HasPrefix(source.File, prefix) {
+		return a
+	}
+
+	trimmed := *source
+	trimmed.File = strings.TrimPrefix(strings.TrimPrefix(source.File, prefix), "/")
+
+	return slog.Any(a.Key, &trimmed)
+}
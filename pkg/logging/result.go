@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// resultKey is the context key of the result annotations of the current request.
+type resultKey struct{}
+
+// result holds annotations a handler adds to the access log of its request.
+type result struct {
+	start time.Time
+
+	mu          sync.Mutex
+	count       *int
+	hasNextPage *bool
+}
+
+// withResult returns a context that collects result annotations for the access log
+// of a request that started at start.
+func withResult(ctx context.Context, start time.Time) (context.Context, *result) {
+	r := &result{start: start}
+
+	return context.WithValue(ctx, resultKey{}, r), r
+}
+
+// SetResultCount records the number of items returned by a list call,
+// which the access log includes as result_count. It does nothing outside an access-logged request.
+func SetResultCount(ctx context.Context, n int) {
+	if r, ok := ctx.Value(resultKey{}).(*result); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.count = &n
+	}
+}
+
+// SetHasNextPage records whether a list call has a next page, i.e. returned a next cursor,
+// which the access log includes as has_next_page. It does nothing outside an access-logged request.
+func SetHasNextPage(ctx context.Context, hasNext bool) {
+	if r, ok := ctx.Value(resultKey{}).(*result); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.hasNextPage = &hasNext
+	}
+}
+
+// RequestDuration returns the time elapsed since the access log started measuring the current request,
+// i.e. the duration_ms it will log if the request ends now. It reports false outside an access-logged request.
+func RequestDuration(ctx context.Context) (time.Duration, bool) {
+	if r, ok := ctx.Value(resultKey{}).(*result); ok {
+		return time.Since(r.start), true
+	}
+
+	return 0, false
+}
+
+// attrs returns the attributes of the annotations that were set.
+func (r *result) attrs() []slog.Attr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var attrs []slog.Attr
+
+	if r.count != nil {
+		attrs = append(attrs, attr.ResultCount(*r.count))
+	}
+
+	if r.hasNextPage != nil {
+		attrs = append(attrs, attr.HasNextPage(*r.hasNextPage))
+	}
+
+	return attrs
+}
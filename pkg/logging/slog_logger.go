@@ -4,17 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"runtime"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // Logger is a structured logger using slog.
 type Logger struct {
-	logger *slog.Logger
+	logger      *slog.Logger
+	level       *slog.LevelVar // shared with loggers derived by With
+	onError     OnErrorFunc    // nil if no callback is set
+	baggageKeys []string       // baggage members logged as attributes
+	traceAttrs  bool           // whether trace and span IDs are logged
 }
 
 // New creates a new Logger with the given options.
+// An unknown format falls back to FormatJSON, logging a warning as the first record.
+// A handler set with WithHandler replaces the handler for the format.
 func New(opts ...Option) *Logger {
 	o := defaultOptions()
 
@@ -22,27 +31,88 @@ func New(opts ...Option) *Logger {
 		opt(o)
 	}
 
+	level := new(slog.LevelVar)
+	level.Set(o.level)
+
 	handlerOpts := &slog.HandlerOptions{
-		Level:       o.level,
-		ReplaceAttr: o.replaceAttrFunc,
+		AddSource:   o.addSource,
+		Level:       level,
+		ReplaceAttr: o.replaceAttr(),
 	}
 
 	var handler slog.Handler
 
-	switch o.format {
-	case FormatText:
+	unknownFormat := false
+
+	switch {
+	case o.handler != nil:
+		handler = &levelHandler{Handler: o.handler, level: level}
+	case o.format == FormatText:
 		handler = slog.NewTextHandler(o.writer, handlerOpts)
-	case FormatJSON:
+	case o.format == FormatJSON, o.format == FormatGCP:
 		handler = slog.NewJSONHandler(o.writer, handlerOpts)
 	default:
-		panic(fmt.Sprintf("unknown logger format: %d", o.format))
+		// A misconfigured format should not crash the process, so fall back to JSON
+		handler = slog.NewJSONHandler(o.writer, handlerOpts)
+		unknownFormat = true
 	}
 
 	logger := slog.New(handler)
 
-	return &Logger{
-		logger: logger,
+	if len(o.baseAttrs) > 0 {
+		baseArgs := make([]any, len(o.baseAttrs))
+		for i, v := range o.baseAttrs {
+			baseArgs[i] = v
+		}
+
+		logger = logger.With(baseArgs...)
 	}
+
+	l := &Logger{
+		logger:      logger,
+		level:       level,
+		onError:     o.onError,
+		baggageKeys: o.baggageKeys,
+		traceAttrs:  o.traceAttrs,
+	}
+
+	if unknownFormat {
+		l.Warn(context.Background(), "Unknown logger format, falling back to JSON", slog.Int("format", int(o.format)))
+	}
+
+	return l
+}
+
+// levelHandler applies the level of a Logger to a handler set with WithHandler.
+type levelHandler struct {
+	slog.Handler
+	level *slog.LevelVar
+}
+
+// Enabled reports whether the level is at least the logger level and enabled by the wrapped handler.
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.Handler.Enabled(ctx, level)
+}
+
+// WithAttrs implements slog.Handler, keeping the level of the logger.
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+// WithGroup implements slog.Handler, keeping the level of the logger.
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
+// SetLevel changes the minimum level of the logger at runtime.
+// The change also applies to loggers derived from it with With.
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// Level returns the current minimum level of the logger.
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
 }
 
 // Debug logs a debug message.
@@ -60,8 +130,10 @@ func (l *Logger) Warn(ctx context.Context, msg string, args ...slog.Attr) {
 	l.log(ctx, slog.LevelWarn, msg, args...)
 }
 
-// Error logs an error message.
+// Error logs an error message, invoking the callback set with WithOnError first.
 func (l *Logger) Error(ctx context.Context, msg string, err error, args ...slog.Attr) {
+	l.notifyError(ctx, msg, err)
+
 	errorAttr := slog.String(attr.Error, err.Error())
 
 	allArgs := make([]slog.Attr, 0, len(args)+1)
@@ -71,6 +143,22 @@ func (l *Logger) Error(ctx context.Context, msg string, err error, args ...slog.
 	l.log(ctx, slog.LevelError, msg, allArgs...)
 }
 
+// notifyError invokes the OnErrorFunc, recovering and logging a panic so it never reaches the caller.
+func (l *Logger) notifyError(ctx context.Context, msg string, err error) {
+	if l.onError == nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			// Logged with log rather than Error to avoid invoking the callback again
+			l.log(ctx, slog.LevelError, "Panic recovered in OnError callback", slog.String(attr.Error, fmt.Sprintf("panic: %v", p)))
+		}
+	}()
+
+	l.onError(ctx, msg, err)
+}
+
 // With returns a logger with the given attributes.
 func (l *Logger) With(args ...slog.Attr) *Logger {
 	slogArgs := make([]any, len(args))
@@ -79,26 +167,48 @@ func (l *Logger) With(args ...slog.Attr) *Logger {
 	}
 
 	return &Logger{
-		logger: l.logger.With(slogArgs...),
+		logger:      l.logger.With(slogArgs...),
+		level:       l.level,
+		onError:     l.onError,
+		baggageKeys: l.baggageKeys,
+		traceAttrs:  l.traceAttrs,
 	}
 }
 
 // log is the internal logging method that handles context.
 func (l *Logger) log(ctx context.Context, level slog.Level, msg string, args ...slog.Attr) {
-	// Extract trace and span IDs from context.
-	contextAttrs := fromContext(ctx)
+	// Extract trace and span IDs and the configured baggage members from context.
+	contextAttrs := fromContext(ctx, l.traceAttrs)
+	contextAttrs = append(contextAttrs, baggageAttrs(ctx, l.baggageKeys)...)
 
 	allArgs := make([]slog.Attr, 0, len(contextAttrs)+len(args))
 	allArgs = append(allArgs, contextAttrs...)
 	allArgs = append(allArgs, args...)
 
-	l.logger.LogAttrs(ctx, level, msg, allArgs...)
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	// Record the caller of the Logger method as the source rather than this method,
+	// skipping runtime.Callers, log, and the Logger method
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.AddAttrs(allArgs...)
+
+	_ = l.logger.Handler().Handle(ctx, record)
 }
 
 // fromContext extracts trace and span IDs from context using OpenTelemetry.
-func fromContext(ctx context.Context) []slog.Attr {
+// It is a no-op if traceAttrs is false.
+func fromContext(ctx context.Context, traceAttrs bool) []slog.Attr {
 	var attrs []slog.Attr
 
+	if !traceAttrs {
+		return attrs
+	}
+
 	spanContext := trace.SpanFromContext(ctx).SpanContext()
 
 	if !spanContext.IsValid() {
@@ -112,3 +222,22 @@ func fromContext(ctx context.Context) []slog.Attr {
 
 	return attrs
 }
+
+// baggageAttrs extracts the members of the given keys from the OpenTelemetry baggage of context.
+func baggageAttrs(ctx context.Context, keys []string) []slog.Attr {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	bag := baggage.FromContext(ctx)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+
+	for _, key := range keys {
+		if member := bag.Member(key); member.Key() != "" {
+			attrs = append(attrs, slog.String(key, member.Value()))
+		}
+	}
+
+	return attrs
+}
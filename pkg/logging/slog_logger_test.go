@@ -3,13 +3,20 @@ package logging_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -215,3 +222,593 @@ func TestLogger_LevelMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestLogger_SetLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	logger := logging.New(
+		logging.WithWriter(&buf),
+		logging.WithLevel(slog.LevelInfo),
+		logging.WithFormat(logging.FormatJSON),
+	)
+	derived := logger.With(slog.String("component", "test"))
+
+	derived.Debug(context.Background(), "before set level")
+	if buf.Len() != 0 {
+		t.Fatalf("Expected debug log to be discarded at INFO level, got %q", buf.String())
+	}
+
+	logger.SetLevel(slog.LevelDebug)
+
+	if got := logger.Level(); got != slog.LevelDebug {
+		t.Errorf("Unexpected level: want %v, got %v", slog.LevelDebug, got)
+	}
+
+	// The level is shared with loggers derived by With
+	derived.Debug(context.Background(), "after set level")
+	if !bytes.Contains(buf.Bytes(), []byte(`"msg":"after set level"`)) {
+		t.Errorf("Expected debug log after SetLevel, got %q", buf.String())
+	}
+}
+
+func TestLogger_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	// New must not panic on a misconfigured format
+	logger := logging.New(
+		logging.WithWriter(&buf),
+		logging.WithFormat(logging.Format(99)),
+	)
+
+	logger.Info(context.Background(), "after fallback")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a warning and the record, got %q", buf.String())
+	}
+
+	wantMsgs := []string{"Unknown logger format, falling back to JSON", "after fallback"}
+	for i, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected JSON output, got %q: %v", line, err)
+		}
+
+		if entry["msg"] != wantMsgs[i] {
+			t.Errorf("Unexpected msg: want %q, got %v", wantMsgs[i], entry["msg"])
+		}
+	}
+
+	if !strings.Contains(lines[0], `"level":"WARN"`) || !strings.Contains(lines[0], `"format":99`) {
+		t.Errorf("Unexpected warning: %q", lines[0])
+	}
+}
+
+// recordingHandler is an in-memory slog.Handler that records the messages and attributes it handles.
+type recordingHandler struct {
+	mu      *sync.Mutex
+	records *[]string
+	attrs   []slog.Attr
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{mu: &sync.Mutex{}, records: &[]string{}}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	parts := []string{r.Level.String(), r.Message}
+	for _, a := range h.attrs {
+		parts = append(parts, a.String())
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		parts = append(parts, a.String())
+		return true
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, strings.Join(parts, " "))
+
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recordingHandler{mu: h.mu, records: h.records, attrs: append(slices.Clone(h.attrs), attrs...)}
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestLogger_WithHandler(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	handler := newRecordingHandler()
+
+	logger := logging.New(
+		logging.WithHandler(handler),
+		logging.WithWriter(&buf), // ignored in favor of the handler
+		logging.WithLevel(slog.LevelInfo),
+		logging.WithBaseAttrs(slog.String("service", "api")),
+	)
+
+	ctx := context.Background()
+
+	logger.Debug(ctx, "below level")
+	logger.Info(ctx, "info message", slog.Int("count", 2))
+	logger.With(slog.String("component", "auth")).Warn(ctx, "warn message")
+
+	logger.SetLevel(slog.LevelDebug)
+	logger.Debug(ctx, "after set level")
+
+	want := []string{
+		"INFO info message service=api count=2",
+		"WARN warn message service=api component=auth",
+		"DEBUG after set level service=api",
+	}
+
+	if !slices.Equal(*handler.records, want) {
+		t.Errorf("Unexpected records:\nwant: %q\ngot:  %q", want, *handler.records)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output to the writer, got %q", buf.String())
+	}
+}
+
+func TestLogger_TimeFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		layout    string
+		parseTime func(v any) (time.Time, error)
+	}{
+		{
+			name:   "render time in the given layout",
+			layout: time.RFC1123Z,
+			parseTime: func(v any) (time.Time, error) {
+				s, ok := v.(string)
+				if !ok {
+					return time.Time{}, fmt.Errorf("expected string, got %T", v)
+				}
+
+				return time.Parse(time.RFC1123Z, s)
+			},
+		},
+		{
+			name:   "render time as epoch millis",
+			layout: logging.TimeEpochMillis,
+			parseTime: func(v any) (time.Time, error) {
+				ms, ok := v.(float64)
+				if !ok {
+					return time.Time{}, fmt.Errorf("expected number, got %T", v)
+				}
+
+				return time.UnixMilli(int64(ms)), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithWriter(&buf),
+				logging.WithFormat(logging.FormatJSON),
+				logging.WithTimeFormat(tt.layout),
+			)
+
+			before := time.Now().Add(-time.Second)
+
+			logger.Info(context.Background(), "test message", slog.Time("created_at", before))
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse log output %q: %v", buf.String(), err)
+			}
+
+			got, err := tt.parseTime(entry["time"])
+			if err != nil {
+				t.Fatalf("Unexpected time field %v: %v", entry["time"], err)
+			}
+
+			if got.Before(before) || got.After(time.Now().Add(time.Second)) {
+				t.Errorf("Unexpected time: %v", got)
+			}
+
+			// Time attributes other than the record time keep the default format
+			if _, err := time.Parse(time.RFC3339Nano, fmt.Sprint(entry["created_at"])); err != nil {
+				t.Errorf("Expected created_at in RFC3339, got %v", entry["created_at"])
+			}
+		})
+	}
+}
+
+func TestLogger_OnError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoke callback with message and error", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			gotMsg string
+			gotErr error
+		)
+
+		var buf bytes.Buffer
+
+		logger := logging.New(
+			logging.WithWriter(&buf),
+			logging.WithOnError(func(_ context.Context, msg string, err error) {
+				gotMsg = msg
+				gotErr = err
+			}),
+		)
+		wantErr := errors.New("database error")
+
+		// The callback is carried over to derived loggers
+		logger.With(slog.String("component", "test")).Error(context.Background(), "query failed", wantErr)
+
+		if gotMsg != "query failed" {
+			t.Errorf("Unexpected message: want %q, got %q", "query failed", gotMsg)
+		}
+
+		if !errors.Is(gotErr, wantErr) {
+			t.Errorf("Unexpected error: want %v, got %v", wantErr, gotErr)
+		}
+
+		if !strings.Contains(buf.String(), "query failed") {
+			t.Errorf("Expected message to be logged, got %q", buf.String())
+		}
+	})
+
+	t.Run("recover panicking callback", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+
+		logger := logging.New(
+			logging.WithWriter(&buf),
+			logging.WithOnError(func(context.Context, string, error) {
+				panic("callback failed")
+			}),
+		)
+
+		logger.Error(context.Background(), "query failed", errors.New("database error"))
+
+		if !strings.Contains(buf.String(), "Panic recovered in OnError callback") {
+			t.Errorf("Expected recovered panic to be logged, got %q", buf.String())
+		}
+
+		if !strings.Contains(buf.String(), "query failed") {
+			t.Errorf("Expected message to be logged despite the panic, got %q", buf.String())
+		}
+	})
+}
+
+func TestLogger_AttrsFromContext(t *testing.T) {
+	t.Parallel()
+
+	tenantMember, err := baggage.NewMember("tenant_id", "tenant-1")
+	if err != nil {
+		t.Fatalf("Failed to create baggage member: %v", err)
+	}
+
+	userMember, err := baggage.NewMember("user_id", "user-1")
+	if err != nil {
+		t.Fatalf("Failed to create baggage member: %v", err)
+	}
+
+	sessionMember, err := baggage.NewMember("session_id", "session-1")
+	if err != nil {
+		t.Fatalf("Failed to create baggage member: %v", err)
+	}
+
+	bag, err := baggage.New(tenantMember, userMember, sessionMember)
+	if err != nil {
+		t.Fatalf("Failed to create baggage: %v", err)
+	}
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	var buf bytes.Buffer
+
+	logger := logging.New(
+		logging.WithWriter(&buf),
+		logging.WithFormat(logging.FormatJSON),
+		logging.WithAttrsFromContext("tenant_id", "user_id", "request_origin"),
+	)
+
+	// The keys are carried over to derived loggers
+	logger.With(slog.String("component", "test")).Info(ctx, "test message")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to unmarshal log entry: %v", err)
+	}
+
+	if entry["tenant_id"] != "tenant-1" {
+		t.Errorf("Unexpected tenant_id: want %q, got %v", "tenant-1", entry["tenant_id"])
+	}
+
+	if entry["user_id"] != "user-1" {
+		t.Errorf("Unexpected user_id: want %q, got %v", "user-1", entry["user_id"])
+	}
+
+	if _, ok := entry["session_id"]; ok {
+		t.Errorf("Expected session_id not to be logged since it is not configured, got %v", entry["session_id"])
+	}
+
+	if _, ok := entry["request_origin"]; ok {
+		t.Errorf("Expected request_origin not to be logged since it is missing from the baggage, got %v", entry["request_origin"])
+	}
+}
+
+func TestLogger_FormatGCP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		log          func(ctx context.Context, logger *logging.Logger)
+		wantSeverity string
+		wantMessage  string
+	}{
+		{
+			name:         "map debug level to DEBUG severity",
+			log:          func(ctx context.Context, logger *logging.Logger) { logger.Debug(ctx, "debug message") },
+			wantSeverity: "DEBUG",
+			wantMessage:  "debug message",
+		},
+		{
+			name:         "map info level to INFO severity",
+			log:          func(ctx context.Context, logger *logging.Logger) { logger.Info(ctx, "info message") },
+			wantSeverity: "INFO",
+			wantMessage:  "info message",
+		},
+		{
+			name:         "map warn level to WARNING severity",
+			log:          func(ctx context.Context, logger *logging.Logger) { logger.Warn(ctx, "warn message") },
+			wantSeverity: "WARNING",
+			wantMessage:  "warn message",
+		},
+		{
+			name: "map error level to ERROR severity",
+			log: func(ctx context.Context, logger *logging.Logger) {
+				logger.Error(ctx, "error message", errors.New("boom"))
+			},
+			wantSeverity: "ERROR",
+			wantMessage:  "error message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(
+				logging.WithWriter(&buf),
+				logging.WithFormat(logging.FormatGCP),
+				logging.WithGCPProjectID("my-project"),
+				logging.WithLevel(slog.LevelDebug),
+			)
+
+			ctx := contextWithTrace("0123456789abcdef0123456789abcdef", "0123456789abcdef")
+
+			tt.log(ctx, logger)
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse log output %q: %v", buf.String(), err)
+			}
+
+			want := map[string]any{
+				"severity":                      tt.wantSeverity,
+				"message":                       tt.wantMessage,
+				"logging.googleapis.com/trace":  "projects/my-project/traces/0123456789abcdef0123456789abcdef",
+				"logging.googleapis.com/spanId": "0123456789abcdef",
+			}
+
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("Unexpected %s: want %v, got %v", key, value, entry[key])
+				}
+			}
+
+			for _, key := range []string{"level", "msg", "trace_id", "span_id"} {
+				if _, ok := entry[key]; ok {
+					t.Errorf("Expected %s to be renamed, got %v", key, entry[key])
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_FormatGCP_WithoutProjectID(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	logger := logging.New(logging.WithWriter(&buf), logging.WithFormat(logging.FormatGCP))
+
+	logger.Info(contextWithTrace("0123456789abcdef0123456789abcdef", "0123456789abcdef"), "info message")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output %q: %v", buf.String(), err)
+	}
+
+	// Cloud Logging expects the resource name of the trace, which needs the project
+	if _, ok := entry["logging.googleapis.com/trace"]; ok {
+		t.Errorf("Expected no trace field without a project ID, got %v", entry["logging.googleapis.com/trace"])
+	}
+
+	if entry["trace_id"] != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("Unexpected trace_id: want %v, got %v", "0123456789abcdef0123456789abcdef", entry["trace_id"])
+	}
+}
+
+func TestLogger_Source(t *testing.T) {
+	t.Parallel()
+
+	_, thisFile, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("Failed to get the path of the test file")
+	}
+
+	// The directory containing pkg, i.e. the repository root
+	root := filepath.Dir(filepath.Dir(filepath.Dir(thisFile)))
+
+	tests := []struct {
+		name     string
+		opts     []logging.Option
+		wantFile string
+	}{
+		{
+			name:     "report absolute path of the caller",
+			opts:     []logging.Option{logging.WithSource()},
+			wantFile: thisFile,
+		},
+		{
+			name:     "trim build path prefix",
+			opts:     []logging.Option{logging.WithSource(), logging.WithTrimSourcePath(root)},
+			wantFile: "pkg/logging/slog_logger_test.go",
+		},
+		{
+			name:     "keep path outside prefix",
+			opts:     []logging.Option{logging.WithSource(), logging.WithTrimSourcePath("/nonexistent")},
+			wantFile: thisFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(append(tt.opts, logging.WithWriter(&buf), logging.WithFormat(logging.FormatJSON))...)
+
+			_, _, wantLine, _ := runtime.Caller(0)
+			logger.Info(context.Background(), "test message") // Must stay on the line right after runtime.Caller
+			wantLine++
+
+			var entry struct {
+				Source struct {
+					File string `json:"file"`
+					Line int    `json:"line"`
+				} `json:"source"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse log output %q: %v", buf.String(), err)
+			}
+
+			if entry.Source.File != tt.wantFile {
+				t.Errorf("Unexpected source file: want %q, got %q", tt.wantFile, entry.Source.File)
+			}
+
+			if entry.Source.Line != wantLine {
+				t.Errorf("Unexpected source line: want %d, got %d", wantLine, entry.Source.Line)
+			}
+		})
+	}
+}
+
+func TestLogger_BaseAttrs(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	logger := logging.New(
+		logging.WithWriter(&buf),
+		logging.WithFormat(logging.FormatJSON),
+		logging.WithLevel(slog.LevelDebug),
+		logging.WithBaseAttrs(slog.String("service", "scaffold"), slog.String("version", "1.2.3")),
+	)
+
+	ctx := context.Background()
+
+	logger.Debug(ctx, "debug message")
+	logger.Info(ctx, "info message")
+	logger.Warn(ctx, "warn message")
+	logger.Error(ctx, "error message", errors.New("boom"))
+	logger.With(slog.String("component", "test")).Info(ctx, "derived message")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse log line %q: %v", line, err)
+		}
+
+		if entry["service"] != "scaffold" {
+			t.Errorf("Unexpected service in %q: want %q, got %v", line, "scaffold", entry["service"])
+		}
+
+		if entry["version"] != "1.2.3" {
+			t.Errorf("Unexpected version in %q: want %q, got %v", line, "1.2.3", entry["version"])
+		}
+	}
+}
+
+func TestLogger_TraceAttrs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []logging.Option
+		wantTrace bool
+	}{
+		{
+			name:      "include trace attributes by default",
+			opts:      nil,
+			wantTrace: true,
+		},
+		{
+			name:      "omit trace attributes when disabled",
+			opts:      []logging.Option{logging.WithTraceAttrs(false)},
+			wantTrace: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			logger := logging.New(append(tt.opts, logging.WithWriter(&buf), logging.WithFormat(logging.FormatJSON))...)
+
+			ctx := contextWithTrace("0123456789abcdef0123456789abcdef", "0123456789abcdef")
+
+			// The setting is carried over to derived loggers
+			logger.With(slog.String("component", "test")).Info(ctx, "test message")
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse log output %q: %v", buf.String(), err)
+			}
+
+			for _, key := range []string{"trace_id", "span_id"} {
+				if _, ok := entry[key]; ok != tt.wantTrace {
+					t.Errorf("Unexpected presence of %s: want %t, got %t", key, tt.wantTrace, ok)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// NewTestLogger returns a logger for tests that writes JSON to the returned buffer at Debug level.
+// The time and duration_ms attributes are dropped, so that the output is stable and can be compared
+// as a whole. opts are applied after these defaults.
+//
+// Example:
+//
+//	logger, buf := logging.NewTestLogger()
+//	logger.Info(ctx, "User created", attr.UserID("123"))
+//
+//	assert.JSONEq(t, `{"level":"INFO","msg":"User created","user_id":"123"}`, buf.String())
+//
+// The buffer is not safe for concurrent use, so read it only after logging has finished.
+func NewTestLogger(opts ...Option) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+
+	defaults := []Option{
+		WithLevel(slog.LevelDebug),
+		WithFormat(FormatJSON),
+		WithWriter(buf),
+		WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == attr.DurationMsKey {
+				return slog.Attr{}
+			}
+			return a
+		}),
+	}
+
+	return New(append(defaults, opts...)...), buf
+}
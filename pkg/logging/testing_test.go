@@ -0,0 +1,59 @@
+package logging_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []logging.Option
+		log  func(logger *logging.Logger)
+		want string
+	}{
+		{
+			name: "capture log without time and duration",
+			log: func(logger *logging.Logger) {
+				logger.Info(context.Background(), "Request handled", attr.UserID("123"), attr.DurationMs(150))
+			},
+			want: `{"level":"INFO","msg":"Request handled","user_id":"123"}`,
+		},
+		{
+			name: "capture debug log",
+			log: func(logger *logging.Logger) {
+				logger.Debug(context.Background(), "Cache miss")
+			},
+			want: `{"level":"DEBUG","msg":"Cache miss"}`,
+		},
+		{
+			name: "apply options over defaults",
+			opts: []logging.Option{logging.WithLevel(slog.LevelWarn)},
+			log: func(logger *logging.Logger) {
+				logger.Info(context.Background(), "Dropped")
+				logger.Warn(context.Background(), "Kept")
+			},
+			want: `{"level":"WARN","msg":"Kept"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger, buf := logging.NewTestLogger(tc.opts...)
+
+			tc.log(logger)
+
+			assert.JSONEq(t, tc.want, buf.String())
+		})
+	}
+}
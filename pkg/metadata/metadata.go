@@ -0,0 +1,56 @@
+// Package metadata carries request header values in a context so that layers without access
+// to the transport, such as use cases, can read them.
+//
+// # Basic Usage
+//
+// Store header values in the context at the transport boundary:
+//
+//	ctx = metadata.NewContext(ctx, metadata.Metadata{"X-Tenant-Id": "tenant-123"})
+//
+// Read them anywhere downstream:
+//
+//	tenantID := metadata.Value(ctx, "X-Tenant-Id")
+//
+// Keys are canonicalized like HTTP header names, so lookups are case-insensitive.
+package metadata
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+// Metadata maps header names to their values.
+type Metadata map[string]string
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying md merged over any metadata already in ctx.
+func NewContext(ctx context.Context, md Metadata) context.Context {
+	merged := make(Metadata, len(md))
+
+	if existing, ok := FromContext(ctx); ok {
+		maps.Copy(merged, existing)
+	}
+
+	for key, value := range md {
+		merged[http.CanonicalHeaderKey(key)] = value
+	}
+
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// FromContext returns the metadata stored in ctx, if any.
+// The returned map must not be modified.
+func FromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(contextKey{}).(Metadata)
+
+	return md, ok
+}
+
+// Value returns the value stored in ctx for the header key, or an empty string if absent.
+func Value(ctx context.Context, key string) string {
+	md, _ := FromContext(ctx)
+
+	return md[http.CanonicalHeaderKey(key)]
+}
@@ -0,0 +1,62 @@
+package metadata_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/metadata"
+)
+
+func TestValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		key  string
+		want string
+	}{
+		{
+			name: "return stored value",
+			ctx: func() context.Context {
+				return metadata.NewContext(context.Background(), metadata.Metadata{"X-Tenant-Id": "tenant-123"})
+			},
+			key:  "X-Tenant-Id",
+			want: "tenant-123",
+		},
+		{
+			name: "look up keys case-insensitively",
+			ctx: func() context.Context {
+				return metadata.NewContext(context.Background(), metadata.Metadata{"x-tenant-id": "tenant-123"})
+			},
+			key:  "X-TENANT-ID",
+			want: "tenant-123",
+		},
+		{
+			name: "merge over existing metadata",
+			ctx: func() context.Context {
+				ctx := metadata.NewContext(context.Background(), metadata.Metadata{"X-Tenant-Id": "tenant-123"})
+
+				return metadata.NewContext(ctx, metadata.Metadata{"X-Request-Id": "req-456"})
+			},
+			key:  "X-Tenant-Id",
+			want: "tenant-123",
+		},
+		{
+			name: "return empty string without metadata",
+			ctx:  context.Background,
+			key:  "X-Tenant-Id",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, metadata.Value(tt.ctx(), tt.key))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package safego runs fire-and-forget work, such as notifications and event delivery, in goroutines
+// that cannot crash the process. A panic in a goroutine started with the go statement terminates the
+// program, since nothing up its stack can recover it, so background work should be started with Go:
+//
+//	safego.Go(ctx, logger, func(ctx context.Context) {
+//		// Work that may panic
+//	})
+//
+// A panic is recovered and logged at Error with its stack trace.
+package safego
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging/attr"
+)
+
+// Go runs fn in a new goroutine, recovering and logging a panic with logger.
+// The attributes are added to the log of a panic, e.g. to identify the work that panicked.
+func Go(ctx context.Context, logger *logging.Logger, fn func(ctx context.Context), attrs ...slog.Attr) {
+	go func() {
+		defer Recover(ctx, logger, attrs...)
+
+		fn(ctx)
+	}()
+}
+
+// Recover recovers a panic and logs it at Error with its stack trace and the attributes.
+// It must be deferred directly, e.g. by goroutines that also signal completion:
+//
+//	go func() {
+//		defer wg.Done()
+//		defer safego.Recover(ctx, logger)
+//		// Work that may panic
+//	}()
+func Recover(ctx context.Context, logger *logging.Logger, attrs ...slog.Attr) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	logger.Error(ctx, "Panic recovered in goroutine", fmt.Errorf("panic: %v", p),
+		append(attrs, attr.Stacktrace(string(debug.Stack())))...,
+	)
+}
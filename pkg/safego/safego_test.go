@@ -0,0 +1,124 @@
+package safego_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"github.com/pannpers/go-backend-scaffold/pkg/safego"
+)
+
+type ctxKey struct{}
+
+// recordHandler is a slog.Handler that sends every record to a channel,
+// so tests can wait for records logged by other goroutines.
+type recordHandler struct {
+	records chan slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records <- r
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+func TestGo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("run fn with ctx", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan any, 1)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		safego.Go(ctx, logging.New(), func(ctx context.Context) {
+			got <- ctx.Value(ctxKey{})
+		})
+
+		select {
+		case v := <-got:
+			assert.Equal(t, "value", v)
+		case <-time.After(time.Second):
+			t.Fatal("expected fn to run")
+		}
+	})
+
+	t.Run("recover and log panic with stack trace", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &recordHandler{records: make(chan slog.Record, 1)}
+
+		safego.Go(context.Background(), logging.New(logging.WithHandler(handler)), func(context.Context) {
+			panic("notifier failed")
+		}, slog.String("task", "notify"))
+
+		var record slog.Record
+		select {
+		case record = <-handler.records:
+		case <-time.After(time.Second):
+			t.Fatal("expected the panic to be logged")
+		}
+
+		assert.Equal(t, slog.LevelError, record.Level)
+		assert.Equal(t, "Panic recovered in goroutine", record.Message)
+
+		attrs := make(map[string]string)
+		record.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value.String()
+			return true
+		})
+
+		assert.Equal(t, "panic: notifier failed", attrs["error"])
+		assert.Equal(t, "notify", attrs["task"])
+		assert.True(t, strings.HasPrefix(attrs["stacktrace"], "goroutine "), "unexpected stacktrace: %q", attrs["stacktrace"])
+		assert.Contains(t, attrs["stacktrace"], "safego_test.TestGo")
+	})
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	logger, logBuffer := logging.NewTestLogger()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer safego.Recover(context.Background(), logger)
+
+		panic("handler failed")
+	}()
+
+	<-done
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &entry))
+
+	assert.Equal(t, "Panic recovered in goroutine", entry["msg"])
+	assert.Equal(t, "panic: handler failed", entry["error"])
+	assert.Contains(t, entry["stacktrace"], "goroutine ")
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	t.Parallel()
+
+	logger, logBuffer := logging.NewTestLogger()
+
+	func() {
+		defer safego.Recover(context.Background(), logger)
+	}()
+
+	assert.Empty(t, logBuffer.String())
+}
@@ -0,0 +1,200 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/embedded"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc/credentials"
+)
+
+// StartRuntimeMetrics records Go runtime metrics (GC, goroutines, memory) with the given meter, using
+// the OpenTelemetry runtime instrumentation. The returned function stops recording; it does not need
+// to be called if the meter provider of meter is shut down.
+func StartRuntimeMetrics(meter otelmetric.Meter) (stop func() error, err error) {
+	provider := &runtimeMeterProvider{meter: meter}
+
+	if err := runtime.Start(runtime.WithMeterProvider(provider)); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to start runtime metrics: %w", err), provider.stop())
+	}
+
+	return provider.stop, nil
+}
+
+// runtimeMeterProvider is the meter provider the runtime instrumentation is started with.
+// The instrumentation only accepts a provider and does not stop, so it hands out meter for every scope
+// and keeps the callbacks registered with it, which stop unregisters.
+type runtimeMeterProvider struct {
+	embedded.MeterProvider
+
+	meter otelmetric.Meter
+
+	mu            sync.Mutex
+	registrations []otelmetric.Registration
+}
+
+// Meter implements otelmetric.MeterProvider.
+func (p *runtimeMeterProvider) Meter(string, ...otelmetric.MeterOption) otelmetric.Meter {
+	return &registeringMeter{Meter: p.meter, provider: p}
+}
+
+// stop unregisters the callbacks registered so far.
+func (p *runtimeMeterProvider) stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs error
+
+	for _, registration := range p.registrations {
+		errs = errors.Join(errs, registration.Unregister())
+	}
+
+	p.registrations = nil
+
+	return errs
+}
+
+// registeringMeter is a meter that keeps the callbacks registered with it in its provider.
+type registeringMeter struct {
+	otelmetric.Meter
+
+	provider *runtimeMeterProvider
+}
+
+// RegisterCallback implements otelmetric.Meter.
+func (m *registeringMeter) RegisterCallback(
+	f otelmetric.Callback,
+	instruments ...otelmetric.Observable,
+) (otelmetric.Registration, error) {
+	registration, err := m.Meter.RegisterCallback(f, instruments...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.provider.mu.Lock()
+	m.provider.registrations = append(m.provider.registrations, registration)
+	m.provider.mu.Unlock()
+
+	return registration, nil
+}
+
+// buildInfoMetric is the name of the constant gauge describing the running build.
+const buildInfoMetric = "app.build.info"
+
+// RegisterBuildInfo registers the app.build.info gauge, which always reports 1 and is labeled
+// with the given version and commit and the Go version the binary was built with.
+// Following the Prometheus build_info convention, it lets dashboards tell which build is deployed.
+func RegisterBuildInfo(meter otelmetric.Meter, version, commit string) error {
+	attrs := otelmetric.WithAttributes(
+		attribute.String("version", version),
+		attribute.String("commit", commit),
+		attribute.String("go_version", GoVersion()),
+	)
+
+	_, err := meter.Int64ObservableGauge(buildInfoMetric,
+		otelmetric.WithDescription("Build information of the running service, always 1."),
+		otelmetric.WithInt64Callback(func(_ context.Context, o otelmetric.Int64Observer) error {
+			o.Observe(1, attrs)
+
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register build info metric: %w", err)
+	}
+
+	return nil
+}
+
+// BuildCommit returns the VCS revision embedded in the binary, or "unknown" if it was built without VCS information.
+func BuildCommit() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && setting.Value != "" {
+				return setting.Value
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+// GoVersion returns the Go version the binary was built with.
+func GoVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.GoVersion != "" {
+		return info.GoVersion
+	}
+
+	return goruntime.Version()
+}
+
+// newMeterProvider creates a meter provider with a periodic reader per configured OTLP endpoint
+// in addition to the given readers.
+func newMeterProvider(
+	ctx context.Context,
+	cfg *config.Config,
+	protocol string,
+	exporterOpts ExporterOptions,
+	res *resource.Resource,
+	readers []metric.Reader,
+) (*metric.MeterProvider, error) {
+	meterProviderOpts := []metric.Option{
+		metric.WithResource(res),
+	}
+
+	for _, reader := range readers {
+		meterProviderOpts = append(meterProviderOpts, metric.WithReader(reader))
+	}
+
+	exporters := make([]metric.Exporter, 0, len(cfg.Telemetry.GetOTLPEndpoints()))
+
+	for _, endpoint := range cfg.Telemetry.GetOTLPEndpoints() {
+		exporter, err := newMetricExporter(ctx, protocol, endpoint, exporterOpts)
+		if err != nil {
+			// Release exporters created so far since the meter provider will not own them
+			for _, created := range exporters {
+				_ = created.Shutdown(ctx)
+			}
+
+			return nil, fmt.Errorf("failed to create OTLP metric exporter for %s: %w", endpoint, err)
+		}
+
+		exporters = append(exporters, exporter)
+		meterProviderOpts = append(meterProviderOpts, metric.WithReader(metric.NewPeriodicReader(exporter)))
+	}
+
+	return metric.NewMeterProvider(meterProviderOpts...), nil
+}
+
+// newMetricExporter creates an OTLP metric exporter for the given protocol and endpoint.
+func newMetricExporter(ctx context.Context, protocol, endpoint string, opts ExporterOptions) (metric.Exporter, error) {
+	tlsConfig := opts.tlsConfig()
+
+	if protocol == ProtocolGRPC {
+		exporterOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint)}
+		if tlsConfig != nil {
+			exporterOpts = append(exporterOpts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		return otlpmetricgrpc.New(ctx, exporterOpts...)
+	}
+
+	exporterOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+	if tlsConfig != nil {
+		exporterOpts = append(exporterOpts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	return otlpmetrichttp.New(ctx, exporterOpts...)
+}
@@ -0,0 +1,157 @@
+package telemetry_test
+
+import (
+	"context"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
+)
+
+func TestStartRuntimeMetrics(t *testing.T) {
+	t.Parallel()
+
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+	})
+
+	stop, err := telemetry.StartRuntimeMetrics(provider.Meter("test"))
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	for _, name := range []string{"go.goroutine.count", "go.memory.used", "go.memory.gc.goal"} {
+		_, ok := findMetric(rm, name)
+		assert.True(t, ok, "expected runtime metric %s to be recorded", name)
+	}
+
+	goroutines, ok := findMetric(rm, "go.goroutine.count")
+	require.True(t, ok)
+
+	sum, ok := goroutines.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "expected goroutine count to be an int64 sum, got %T", goroutines.Data)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Positive(t, sum.DataPoints[0].Value)
+
+	// Stopping unregisters the callbacks, so later collections record no runtime metrics
+	require.NoError(t, stop())
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	_, ok = findMetric(rm, "go.goroutine.count")
+	assert.False(t, ok, "expected runtime metrics to stop being recorded")
+}
+
+func TestRegisterBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+	})
+
+	require.NoError(t, telemetry.RegisterBuildInfo(provider.Meter("test"), "1.2.3", "abc123"))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	m, ok := findMetric(rm, "app.build.info")
+	require.True(t, ok, "expected build info metric to be recorded")
+
+	gauge, ok := m.Data.(metricdata.Gauge[int64])
+	require.True(t, ok, "expected build info to be an int64 gauge, got %T", m.Data)
+	require.Len(t, gauge.DataPoints, 1)
+
+	info, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+
+	dp := gauge.DataPoints[0]
+	assert.Equal(t, int64(1), dp.Value)
+	assert.Equal(t, attribute.NewSet(
+		attribute.String("version", "1.2.3"),
+		attribute.String("commit", "abc123"),
+		attribute.String("go_version", info.GoVersion),
+	), dp.Attributes)
+}
+
+// TestSetupTelemetry_Metrics is not parallel because it relies on the global meter provider.
+func TestSetupTelemetry_Metrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		metricsEnabled bool
+		wantRuntime    bool
+	}{
+		{
+			name:           "record runtime metrics when metrics are enabled",
+			metricsEnabled: true,
+			wantRuntime:    true,
+		},
+		{
+			name:           "install no meter provider when metrics are disabled",
+			metricsEnabled: false,
+			wantRuntime:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := metric.NewManualReader()
+
+			cfg := &config.Config{
+				ShutdownTimeout: time.Second,
+				Telemetry: config.TelemetryConfig{
+					MetricsEnabled: tt.metricsEnabled,
+				},
+			}
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), cfg, telemetry.WithMetricReader(reader))
+			require.NoError(t, err)
+
+			var rm metricdata.ResourceMetrics
+
+			collectErr := reader.Collect(context.Background(), &rm)
+
+			require.NoError(t, closer.Close())
+
+			if !tt.wantRuntime {
+				// The reader is never registered with a meter provider
+				assert.Error(t, collectErr)
+
+				return
+			}
+
+			require.NoError(t, collectErr)
+			_, ok := findMetric(rm, "go.goroutine.count")
+			assert.True(t, ok, "expected runtime metrics to be recorded")
+
+			// Closing shuts down the meter provider, which stops runtime metrics
+			assert.ErrorIs(t, reader.Collect(context.Background(), &rm), metric.ErrReaderShutdown)
+		})
+	}
+}
+
+// findMetric returns the collected metric with the given name.
+func findMetric(rm metricdata.ResourceMetrics, name string) (metricdata.Metrics, bool) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+
+	return metricdata.Metrics{}, false
+}
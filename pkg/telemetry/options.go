@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// Supported OTLP exporter protocols.
+const (
+	// ProtocolHTTP exports traces with OTLP over HTTP.
+	ProtocolHTTP = "http"
+	// ProtocolGRPC exports traces with OTLP over gRPC.
+	ProtocolGRPC = "grpc"
+)
+
+// ExporterOptions configures how an exporter connects to its OTLP endpoint.
+type ExporterOptions struct {
+	// InsecureSkipVerify disables verification of the TLS certificate of the endpoint,
+	// e.g. for a collector with a self-signed certificate outside production.
+	InsecureSkipVerify bool
+}
+
+// tlsConfig returns the TLS configuration for the exporter, or nil to use the default one.
+func (o ExporterOptions) tlsConfig() *tls.Config {
+	if !o.InsecureSkipVerify {
+		return nil
+	}
+
+	// Opted in with OTLP_INSECURE for collectors with self-signed certificates
+	return &tls.Config{InsecureSkipVerify: true}
+}
+
+// ExporterFactory creates a span exporter that sends traces to the given OTLP endpoint.
+type ExporterFactory func(ctx context.Context, endpoint string, opts ExporterOptions) (trace.SpanExporter, error)
+
+// Option defines a function that configures telemetry setup.
+type Option func(*options)
+
+// options holds all the telemetry setup configuration.
+type options struct {
+	exporterFactories map[string]ExporterFactory
+	resourceAttrs     []attribute.KeyValue
+	metricReaders     []metric.Reader
+	logger            *logging.Logger
+}
+
+// defaultOptions returns the default telemetry setup options.
+func defaultOptions() *options {
+	return &options{
+		exporterFactories: map[string]ExporterFactory{
+			ProtocolHTTP: newHTTPExporter,
+			ProtocolGRPC: newGRPCExporter,
+		},
+		logger: logging.New(),
+	}
+}
+
+// WithExporterFactory overrides how span exporters are created for the given OTLP protocol.
+// This is mainly useful for tests that need to observe exported spans without a collector.
+func WithExporterFactory(protocol string, f ExporterFactory) Option {
+	return func(o *options) {
+		if f != nil {
+			o.exporterFactories[protocol] = f
+		}
+	}
+}
+
+// WithResourceAttributes adds attributes to the telemetry resource attached to all spans.
+// They take precedence over attributes with the same key from configuration.
+func WithResourceAttributes(attrs ...attribute.KeyValue) Option {
+	return func(o *options) {
+		o.resourceAttrs = append(o.resourceAttrs, attrs...)
+	}
+}
+
+// WithMetricReader registers an additional metric reader on the meter provider when metrics are enabled.
+// This is mainly useful for tests that collect measurements with a metric.ManualReader.
+func WithMetricReader(reader metric.Reader) Option {
+	return func(o *options) {
+		if reader != nil {
+			o.metricReaders = append(o.metricReaders, reader)
+		}
+	}
+}
+
+// WithLogger sets the logger used to report the telemetry configuration and setup warnings.
+func WithLogger(logger *logging.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// newHTTPExporter creates an OTLP/HTTP span exporter for the given endpoint.
+func newHTTPExporter(ctx context.Context, endpoint string, opts ExporterOptions) (trace.SpanExporter, error) {
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+
+	if tlsConfig := opts.tlsConfig(); tlsConfig != nil {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	return otlptracehttp.New(ctx, exporterOpts...)
+}
+
+// newGRPCExporter creates an OTLP/gRPC span exporter for the given endpoint.
+func newGRPCExporter(ctx context.Context, endpoint string, opts ExporterOptions) (trace.SpanExporter, error) {
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+
+	if tlsConfig := opts.tlsConfig(); tlsConfig != nil {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	return otlptracegrpc.New(ctx, exporterOpts...)
+}
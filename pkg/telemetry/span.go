@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the instrumentation scope of spans and metrics recorded by this service.
+const instrumentationName = "github.com/pannpers/go-backend-scaffold"
+
+// StartSpan starts a span with the given name as a child of the span in ctx, using the global tracer provider.
+// The caller must end the returned span, typically with defer span.End().
+//
+// Example:
+//
+//	ctx, span := telemetry.StartSpan(ctx, "UserUseCase.GetUser")
+//	defer span.End()
+func StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name)
+}
+
+// RecordError records err on the span and marks the span as failed. It does nothing if err is nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+}
@@ -0,0 +1,51 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
+)
+
+// TestStartSpan is not parallel because it replaces the global tracer provider.
+func TestStartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	ctx, parent := telemetry.StartSpan(context.Background(), "parent")
+
+	_, child := telemetry.StartSpan(ctx, "child")
+	telemetry.RecordError(child, errors.New("boom"))
+	child.End()
+
+	telemetry.RecordError(parent, nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, otelcodes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+
+	assert.Equal(t, "parent", spans[1].Name())
+	assert.Equal(t, otelcodes.Unset, spans[1].Status().Code)
+	assert.Empty(t, spans[1].Events())
+}
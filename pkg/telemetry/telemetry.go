@@ -1,73 +1,316 @@
-// Package telemetry provides OpenTelemetry tracing setup and configuration.
+// Package telemetry provides OpenTelemetry tracing and metrics setup and configuration.
 package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // SetupTelemetry initializes OpenTelemetry tracing and returns a closer for shutdown.
-// If telemetry OTLP endpoint is not configured, tracer is initialized without exporter
-// to disable sending trace info to OTEL collector.
-func SetupTelemetry(ctx context.Context, cfg *config.Config) (io.Closer, error) {
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(cfg.Telemetry.ServiceName),
-			semconv.ServiceVersionKey.String(cfg.Telemetry.ServiceVersion),
-		),
-	)
+// A batch span processor is registered for each configured OTLP endpoint, so traces can be
+// sent to several collectors at once. If no endpoint is configured, tracer is initialized
+// without exporter to disable sending trace info to OTEL collector, or, if the sample ratio is also 0,
+// a no-op tracer provider is installed so that no span is created.
+// The sample ratio defaults to 1 only when the config is loaded, so a config built by hand with
+// SampleRatio left at 0 and no endpoint gets the no-op tracer provider; set it to trace locally.
+// When metrics are enabled, a meter provider is also installed and Go runtime metrics and build info are recorded.
+func SetupTelemetry(ctx context.Context, cfg *config.Config, opts ...Option) (io.Closer, error) {
+	o := defaultOptions()
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	res, err := newResource(ctx, cfg, o.resourceAttrs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+		return nil, err
+	}
+
+	protocol := cfg.Telemetry.OTLPProtocol
+	if protocol == "" {
+		protocol = ProtocolHTTP
+	}
+
+	exporterFactory, ok := o.exporterFactories[protocol]
+	if !ok {
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s", protocol)
+	}
+
+	exporterOpts := ExporterOptions{InsecureSkipVerify: cfg.Telemetry.OTLPInsecure}
+
+	if exporterOpts.InsecureSkipVerify && cfg.IsProduction() {
+		o.logger.Warn(ctx, "TLS certificate verification of OTLP endpoints is disabled in production",
+			slog.Any("endpoints", redactEndpoints(cfg.Telemetry.GetOTLPEndpoints())),
+		)
+	}
+
+	closer := &telemetryCloser{shutdownTimeout: cfg.ShutdownTimeout}
+
+	if tracingDisabled(cfg) {
+		// Spans would be created only to be dropped, so do not create them at all.
+		// The no-op tracer still propagates incoming trace contexts, e.g. to trace IDs in logs.
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	} else {
+		tracerProvider, err := newTracerProvider(ctx, cfg, res, exporterFactory, exporterOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		// Set the global tracer provider
+		otel.SetTracerProvider(tracerProvider)
+
+		closer.tracerProvider = tracerProvider
+	}
+
+	if !cfg.Telemetry.MetricsEnabled {
+		logConfiguration(ctx, o.logger, cfg, protocol)
+
+		return closer, nil
+	}
+
+	meterProvider, err := newMeterProvider(ctx, cfg, protocol, exporterOpts, res, o.metricReaders)
+	if err != nil {
+		return nil, errors.Join(err, closer.Close())
+	}
+
+	closer.meterProvider = meterProvider
+
+	// Set the global meter provider
+	otel.SetMeterProvider(meterProvider)
+
+	meter := meterProvider.Meter(instrumentationName)
+
+	closer.stopRuntimeMetrics, err = StartRuntimeMetrics(meter)
+	if err != nil {
+		return nil, errors.Join(err, closer.Close())
 	}
 
+	if err := RegisterBuildInfo(meter, cfg.Telemetry.ServiceVersion, BuildCommit()); err != nil {
+		return nil, errors.Join(err, closer.Close())
+	}
+
+	logConfiguration(ctx, o.logger, cfg, protocol)
+
+	return closer, nil
+}
+
+// logConfiguration logs how telemetry was set up at Info, so that a missing or misdirected export
+// can be diagnosed from the startup logs. Credentials in endpoints are redacted.
+func logConfiguration(ctx context.Context, logger *logging.Logger, cfg *config.Config, protocol string) {
+	logger.Info(ctx, "Telemetry configured",
+		slog.Any("endpoints", redactEndpoints(cfg.Telemetry.GetOTLPEndpoints())),
+		slog.String("protocol", protocol),
+		slog.Float64("sample_ratio", cfg.Telemetry.SampleRatio),
+		slog.Bool("tracing_enabled", !tracingDisabled(cfg)),
+		slog.Bool("metrics_enabled", cfg.Telemetry.MetricsEnabled),
+	)
+}
+
+// redactedValue replaces credentials in redacted endpoints.
+const redactedValue = "xxxxx"
+
+// redactEndpoints returns endpoints with the credentials they may embed redacted: the user info,
+// e.g. "user:token@collector:4318", and the query values of URLs, e.g. "?api_key=token".
+func redactEndpoints(endpoints []string) []string {
+	redacted := make([]string, len(endpoints))
+
+	for i, endpoint := range endpoints {
+		redacted[i] = redactEndpoint(endpoint)
+	}
+
+	return redacted
+}
+
+// redactEndpoint redacts the credentials of a single endpoint, see redactEndpoints.
+func redactEndpoint(endpoint string) string {
+	// Endpoints are usually host:port without a scheme, which url.Parse does not read as a host
+	if u, err := url.Parse(endpoint); err == nil && u.Scheme != "" && u.Host != "" {
+		if u.User != nil {
+			u.User = url.User(redactedValue)
+		}
+
+		if u.RawQuery != "" {
+			query := u.Query()
+			for key := range query {
+				query.Set(key, redactedValue)
+			}
+
+			u.RawQuery = query.Encode()
+		}
+
+		return u.String()
+	}
+
+	if at := strings.LastIndex(endpoint, "@"); at >= 0 {
+		return redactedValue + endpoint[at:]
+	}
+
+	return endpoint
+}
+
+// tracingDisabled reports whether no span would ever be exported, since there is no endpoint
+// to export to and no trace is sampled.
+func tracingDisabled(cfg *config.Config) bool {
+	return len(cfg.Telemetry.GetOTLPEndpoints()) == 0 && cfg.Telemetry.SampleRatio <= 0
+}
+
+// newTracerProvider creates a tracer provider sampling cfg.Telemetry.SampleRatio of traces with
+// a batch span processor for each configured OTLP endpoint.
+func newTracerProvider(
+	ctx context.Context,
+	cfg *config.Config,
+	res *resource.Resource,
+	exporterFactory ExporterFactory,
+	exporterOpts ExporterOptions,
+) (*trace.TracerProvider, error) {
 	tracerProviderOpts := []trace.TracerProviderOption{
 		trace.WithResource(res),
-		trace.WithSampler(trace.AlwaysSample()),
+		trace.WithSampler(newSampler(cfg.Telemetry.SampleRatio)),
 	}
 
-	// disable to export traces to OTEL collector for local development
-	if cfg.Telemetry.OTLPEndpoint != "" {
-		exporter, err := otlptracehttp.New(ctx,
-			otlptracehttp.WithEndpoint(cfg.Telemetry.OTLPEndpoint),
-		)
+	// No endpoints disables exporting traces to OTEL collector for local development
+	exporters := make([]trace.SpanExporter, 0, len(cfg.Telemetry.GetOTLPEndpoints()))
+
+	for _, endpoint := range cfg.Telemetry.GetOTLPEndpoints() {
+		exporter, err := exporterFactory(ctx, endpoint, exporterOpts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+			// Release exporters created so far since the tracer provider will not own them
+			for _, created := range exporters {
+				_ = created.Shutdown(ctx)
+			}
+
+			return nil, fmt.Errorf("failed to create OTLP exporter for %s: %w", endpoint, err)
 		}
 
+		exporters = append(exporters, exporter)
 		tracerProviderOpts = append(tracerProviderOpts, trace.WithBatcher(exporter))
 	}
 
-	tracerProvider := trace.NewTracerProvider(tracerProviderOpts...)
+	return trace.NewTracerProvider(tracerProviderOpts...), nil
+}
 
-	// Set the global tracer provider
-	otel.SetTracerProvider(tracerProvider)
+// newSampler returns the sampler for ratio, sampling that ratio of root traces and following the
+// sampling decision of the parent for the others. TraceIDRatioBased samples every trace at 1 or above
+// and none at 0 or below, so a sampled incoming trace is still recorded at a ratio of 0.
+func newSampler(ratio float64) trace.Sampler {
+	return trace.ParentBased(trace.TraceIDRatioBased(ratio))
+}
+
+// ForceFlush exports the spans buffered by the batch span processors of the global tracer provider
+// without shutting it down. Short-lived processes such as CLI commands and tests can call it to make sure
+// their spans are exported before exiting, since the batcher only exports periodically.
+// It is a no-op if the global tracer provider was not set up by SetupTelemetry.
+func ForceFlush(ctx context.Context) error {
+	tracerProvider, ok := otel.GetTracerProvider().(*trace.TracerProvider)
+	if !ok {
+		return nil
+	}
 
-	return &tracerCloser{provider: tracerProvider, shutdownTimeout: cfg.ShutdownTimeout}, nil
+	if err := tracerProvider.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("failed to flush tracer provider: %w", err)
+	}
+
+	return nil
 }
 
-// tracerCloser implements io.Closer for shutting down the tracer provider
-type tracerCloser struct {
-	provider        *trace.TracerProvider
-	shutdownTimeout time.Duration
+// newResource creates the telemetry resource describing this service.
+// Attributes are merged in order of precedence: service identity and deployment environment,
+// then APP_TELEMETRY_RESOURCE_ATTRS, then attributes given with WithResourceAttributes.
+func newResource(ctx context.Context, cfg *config.Config, extraAttrs []attribute.KeyValue) (*resource.Resource, error) {
+	configAttrs, err := parseResourceAttrs(cfg.Telemetry.ResourceAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(cfg.Telemetry.ServiceName),
+		semconv.ServiceVersionKey.String(cfg.Telemetry.ServiceVersion),
+		semconv.DeploymentEnvironmentKey.String(cfg.Environment),
+	}
+	attrs = append(attrs, configAttrs...)
+	attrs = append(attrs, extraAttrs...)
+
+	res, err := resource.New(ctx, resource.WithAttributes(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	return res, nil
+}
+
+// parseResourceAttrs parses key=value pairs into resource attributes.
+func parseResourceAttrs(pairs []string) ([]attribute.KeyValue, error) {
+	attrs := make([]attribute.KeyValue, 0, len(pairs))
+
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid resource attribute %q: expected key=value", pair)
+		}
+
+		attrs = append(attrs, attribute.String(key, strings.TrimSpace(value)))
+	}
+
+	return attrs, nil
+}
+
+// telemetryCloser implements io.Closer for shutting down the tracer and meter providers
+type telemetryCloser struct {
+	tracerProvider     *trace.TracerProvider // nil when tracing is disabled
+	meterProvider      *metric.MeterProvider // nil when metrics are disabled
+	stopRuntimeMetrics func() error          // nil when metrics are disabled
+	shutdownTimeout    time.Duration
 }
 
-// Close shuts down the tracer provider and flushes any remaining spans
-func (tc *tracerCloser) Close() error {
+// Close stops runtime metrics and shuts down the meter provider, flushing remaining
+// measurements, then the tracer provider, flushing any remaining spans and shutting down
+// the span processors of every exporter
+func (tc *telemetryCloser) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), tc.shutdownTimeout)
 	defer cancel()
 
-	if err := tc.provider.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown tracer provider: %w", err)
+	var errs error
+
+	if tc.stopRuntimeMetrics != nil {
+		if err := tc.stopRuntimeMetrics(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to stop runtime metrics: %w", err))
+		}
 	}
 
-	return nil
+	if tc.meterProvider != nil {
+		if err := tc.meterProvider.Shutdown(ctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to shutdown meter provider: %w", err))
+		}
+	}
+
+	if tc.tracerProvider != nil {
+		if err := tc.tracerProvider.Shutdown(ctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to shutdown tracer provider: %w", err))
+		}
+	}
+
+	return errs
 }
@@ -1,13 +1,24 @@
 package telemetry_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pannpers/go-backend-scaffold/pkg/config"
+	"github.com/pannpers/go-backend-scaffold/pkg/logging"
 	"github.com/pannpers/go-backend-scaffold/pkg/telemetry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 func TestSetupTelemetry(t *testing.T) {
@@ -58,4 +69,546 @@ func TestSetupTelemetry(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+// stubExporter records exported spans and whether it has been shut down.
+type stubExporter struct {
+	mu       sync.Mutex
+	spans    []trace.ReadOnlySpan
+	shutdown bool
+}
+
+func (e *stubExporter) ExportSpans(_ context.Context, spans []trace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+
+	return nil
+}
+
+func (e *stubExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.shutdown = true
+
+	return nil
+}
+
+// stubExporterFactory returns an exporter factory creating one stubExporter per endpoint.
+func stubExporterFactory(exporters map[string]*stubExporter) telemetry.ExporterFactory {
+	return func(_ context.Context, endpoint string, _ telemetry.ExporterOptions) (trace.SpanExporter, error) {
+		exporter := &stubExporter{}
+		exporters[endpoint] = exporter
+
+		return exporter, nil
+	}
+}
+
+// TestSetupTelemetry_MultipleEndpoints is not parallel because it relies on the global tracer provider.
+func TestSetupTelemetry_MultipleEndpoints(t *testing.T) {
+	tests := []struct {
+		name          string
+		telemetry     config.TelemetryConfig
+		wantEndpoints []string
+	}{
+		{
+			name: "register a processor per endpoint",
+			telemetry: config.TelemetryConfig{
+				OTLPEndpoint:  "old-collector:4318",
+				OTLPEndpoints: []string{"new-collector:4318"},
+			},
+			wantEndpoints: []string{"old-collector:4318", "new-collector:4318"},
+		},
+		{
+			name: "register no processor without endpoints",
+			telemetry: config.TelemetryConfig{
+				OTLPEndpoint: "",
+			},
+			wantEndpoints: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporters := map[string]*stubExporter{}
+			cfg := &config.Config{
+				ShutdownTimeout: time.Second,
+				Telemetry:       tt.telemetry,
+			}
+			cfg.Telemetry.SampleRatio = 1
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+				telemetry.WithExporterFactory(telemetry.ProtocolHTTP, stubExporterFactory(exporters)),
+			)
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+			span.End()
+
+			require.NoError(t, closer.Close())
+
+			assert.Len(t, exporters, len(tt.wantEndpoints))
+
+			for _, endpoint := range tt.wantEndpoints {
+				exporter, ok := exporters[endpoint]
+				require.True(t, ok, "expected exporter for %s", endpoint)
+				assert.Len(t, exporter.spans, 1, "expected span exported to %s", endpoint)
+				assert.True(t, exporter.shutdown, "expected exporter for %s to be shut down", endpoint)
+			}
+		})
+	}
+}
+
+// TestForceFlush is not parallel because it relies on the global tracer provider.
+func TestForceFlush(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg := &config.Config{
+		ShutdownTimeout: time.Second,
+		Telemetry:       config.TelemetryConfig{OTLPEndpoint: "collector:4318", SampleRatio: 1},
+	}
+
+	closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+		telemetry.WithExporterFactory(telemetry.ProtocolHTTP,
+			func(context.Context, string, telemetry.ExporterOptions) (trace.SpanExporter, error) {
+				return exporter, nil
+			},
+		),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+
+	// The batcher holds the span until its next export interval
+	assert.Empty(t, exporter.GetSpans())
+
+	require.NoError(t, telemetry.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "test-span", spans[0].Name)
+
+	// The in-memory exporter drops its spans on shutdown, so only check that closing still succeeds
+	require.NoError(t, closer.Close())
+}
+
+func TestSetupTelemetry_ExporterError(t *testing.T) {
+	t.Parallel()
+
+	created := &stubExporter{}
+	calls := 0
+
+	cfg := &config.Config{
+		Telemetry: config.TelemetryConfig{
+			OTLPEndpoints: []string{"ok-collector:4318", "broken-collector:4318"},
+		},
+	}
+
+	closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+		telemetry.WithExporterFactory(telemetry.ProtocolHTTP, func(_ context.Context, endpoint string, _ telemetry.ExporterOptions) (trace.SpanExporter, error) {
+			calls++
+			if calls == 1 {
+				return created, nil
+			}
+
+			return nil, errors.New("connection refused")
+		}),
+	)
+
+	require.Error(t, err)
+	assert.Nil(t, closer)
+	assert.Contains(t, err.Error(), "broken-collector:4318")
+	assert.True(t, created.shutdown, "expected already created exporter to be shut down")
+}
+
+func TestSetupTelemetry_Protocol(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		protocol     string
+		wantProtocol string
+		wantErr      bool
+	}{
+		{
+			name:         "use HTTP exporter by default",
+			protocol:     "",
+			wantProtocol: telemetry.ProtocolHTTP,
+		},
+		{
+			name:         "use HTTP exporter when configured",
+			protocol:     "http",
+			wantProtocol: telemetry.ProtocolHTTP,
+		},
+		{
+			name:         "use gRPC exporter when configured",
+			protocol:     "grpc",
+			wantProtocol: telemetry.ProtocolGRPC,
+		},
+		{
+			name:     "return error for unsupported protocol",
+			protocol: "thrift",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotProtocols []string
+
+			factoryFor := func(protocol string) telemetry.ExporterFactory {
+				return func(context.Context, string, telemetry.ExporterOptions) (trace.SpanExporter, error) {
+					gotProtocols = append(gotProtocols, protocol)
+
+					return &stubExporter{}, nil
+				}
+			}
+
+			cfg := &config.Config{
+				ShutdownTimeout: time.Second,
+				Telemetry: config.TelemetryConfig{
+					OTLPEndpoint: "collector:4317",
+					OTLPProtocol: tt.protocol,
+				},
+			}
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+				telemetry.WithExporterFactory(telemetry.ProtocolHTTP, factoryFor(telemetry.ProtocolHTTP)),
+				telemetry.WithExporterFactory(telemetry.ProtocolGRPC, factoryFor(telemetry.ProtocolGRPC)),
+			)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, closer)
+				assert.Empty(t, gotProtocols)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.wantProtocol}, gotProtocols)
+			assert.NoError(t, closer.Close())
+		})
+	}
+}
+
+// TestSetupTelemetry_ResourceAttributes is not parallel because it relies on the global tracer provider.
+func TestSetupTelemetry_ResourceAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		opts      []telemetry.Option
+		wantAttrs map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "set deployment environment from config",
+			cfg: &config.Config{
+				Environment: "staging",
+			},
+			wantAttrs: map[string]string{
+				"deployment.environment": "staging",
+			},
+		},
+		{
+			name: "merge attributes from config and options",
+			cfg: &config.Config{
+				Environment: "production",
+				Telemetry: config.TelemetryConfig{
+					ResourceAttrs: []string{"service.namespace=platform", "team = backend"},
+				},
+			},
+			opts: []telemetry.Option{
+				telemetry.WithResourceAttributes(attribute.String("team", "core")),
+			},
+			wantAttrs: map[string]string{
+				"deployment.environment": "production",
+				"service.namespace":      "platform",
+				"team":                   "core",
+			},
+		},
+		{
+			name: "return error for malformed attribute",
+			cfg: &config.Config{
+				Telemetry: config.TelemetryConfig{
+					ResourceAttrs: []string{"service.namespace"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporters := map[string]*stubExporter{}
+
+			tt.cfg.ShutdownTimeout = time.Second
+			tt.cfg.Telemetry.OTLPEndpoint = "collector:4318"
+			tt.cfg.Telemetry.SampleRatio = 1
+
+			opts := append([]telemetry.Option{
+				telemetry.WithExporterFactory(telemetry.ProtocolHTTP, stubExporterFactory(exporters)),
+			}, tt.opts...)
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), tt.cfg, opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, closer)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+			span.End()
+
+			require.NoError(t, closer.Close())
+
+			spans := exporters["collector:4318"].spans
+			require.Len(t, spans, 1)
+
+			for key, want := range tt.wantAttrs {
+				got, ok := spans[0].Resource().Set().Value(attribute.Key(key))
+				require.True(t, ok, "expected resource attribute %s", key)
+				assert.Equal(t, want, got.AsString())
+			}
+		})
+	}
+}
+
+// TestSetupTelemetry_SampleRatio is not parallel because it relies on the global tracer provider.
+func TestSetupTelemetry_SampleRatio(t *testing.T) {
+	tests := []struct {
+		name        string
+		endpoint    string
+		sampleRatio float64
+		wantNoop    bool
+		wantSampled bool
+	}{
+		{
+			name:        "install no-op tracer provider without endpoint and sampling",
+			sampleRatio: 0,
+			wantNoop:    true,
+		},
+		{
+			name:        "sample without endpoint",
+			sampleRatio: 1,
+			wantSampled: true,
+		},
+		{
+			name:        "sample nothing with endpoint",
+			endpoint:    "collector:4318",
+			sampleRatio: 0,
+			wantSampled: false,
+		},
+		{
+			name:        "sample everything with endpoint",
+			endpoint:    "collector:4318",
+			sampleRatio: 1,
+			wantSampled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporters := map[string]*stubExporter{}
+
+			cfg := &config.Config{
+				ShutdownTimeout: time.Second,
+				Telemetry: config.TelemetryConfig{
+					OTLPEndpoint: tt.endpoint,
+					SampleRatio:  tt.sampleRatio,
+				},
+			}
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+				telemetry.WithExporterFactory(telemetry.ProtocolHTTP, stubExporterFactory(exporters)),
+			)
+			require.NoError(t, err)
+
+			_, isSDK := otel.GetTracerProvider().(*trace.TracerProvider)
+			assert.Equal(t, tt.wantNoop, !isSDK)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+			assert.Equal(t, tt.wantSampled, span.SpanContext().IsSampled())
+			assert.Equal(t, !tt.wantNoop, span.SpanContext().IsValid(), "no-op spans must not be created")
+			span.End()
+
+			require.NoError(t, closer.Close())
+		})
+	}
+}
+
+// TestSetupTelemetry_ParentSampling is not parallel because it relies on the global tracer provider.
+func TestSetupTelemetry_ParentSampling(t *testing.T) {
+	tests := []struct {
+		name          string
+		sampleRatio   float64
+		parentSampled bool
+		wantSampled   bool
+	}{
+		{
+			name:          "follow sampled parent at ratio 0",
+			sampleRatio:   0,
+			parentSampled: true,
+			wantSampled:   true,
+		},
+		{
+			name:          "follow unsampled parent at ratio 1",
+			sampleRatio:   1,
+			parentSampled: false,
+			wantSampled:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ShutdownTimeout: time.Second,
+				Telemetry: config.TelemetryConfig{
+					OTLPEndpoint: "collector:4318",
+					SampleRatio:  tt.sampleRatio,
+				},
+			}
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+				telemetry.WithExporterFactory(telemetry.ProtocolHTTP, stubExporterFactory(map[string]*stubExporter{})),
+			)
+			require.NoError(t, err)
+
+			var flags oteltrace.TraceFlags
+			if tt.parentSampled {
+				flags = oteltrace.FlagsSampled
+			}
+
+			parent := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+				TraceID:    oteltrace.TraceID{1},
+				SpanID:     oteltrace.SpanID{1},
+				TraceFlags: flags,
+				Remote:     true,
+			})
+			ctx := oteltrace.ContextWithRemoteSpanContext(context.Background(), parent)
+
+			_, span := otel.Tracer("test").Start(ctx, "test-span")
+			assert.Equal(t, tt.wantSampled, span.SpanContext().IsSampled())
+			span.End()
+
+			require.NoError(t, closer.Close())
+		})
+	}
+}
+
+// TestSetupTelemetry_Insecure is not parallel because it relies on the global tracer provider.
+func TestSetupTelemetry_Insecure(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		insecure    bool
+		wantWarning bool
+	}{
+		{
+			name:        "skip TLS verification when enabled",
+			environment: "staging",
+			insecure:    true,
+			wantWarning: false,
+		},
+		{
+			name:        "verify TLS by default",
+			environment: "staging",
+			insecure:    false,
+			wantWarning: false,
+		},
+		{
+			name:        "warn when enabled in production",
+			environment: "production",
+			insecure:    true,
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				logBuffer bytes.Buffer
+				gotOpts   []telemetry.ExporterOptions
+			)
+
+			cfg := &config.Config{
+				Environment:     tt.environment,
+				ShutdownTimeout: time.Second,
+				Telemetry: config.TelemetryConfig{
+					OTLPEndpoint: "collector:4318",
+					OTLPInsecure: tt.insecure,
+				},
+			}
+
+			closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+				telemetry.WithLogger(logging.New(logging.WithWriter(&logBuffer), logging.WithFormat(logging.FormatJSON))),
+				telemetry.WithExporterFactory(telemetry.ProtocolHTTP,
+					func(_ context.Context, _ string, opts telemetry.ExporterOptions) (trace.SpanExporter, error) {
+						gotOpts = append(gotOpts, opts)
+
+						return &stubExporter{}, nil
+					},
+				),
+			)
+			require.NoError(t, err)
+			require.NoError(t, closer.Close())
+
+			assert.Equal(t, []telemetry.ExporterOptions{{InsecureSkipVerify: tt.insecure}}, gotOpts)
+
+			if tt.wantWarning {
+				assert.Contains(t, logBuffer.String(), `"level":"WARN"`)
+				assert.Contains(t, logBuffer.String(), "TLS certificate verification of OTLP endpoints is disabled")
+			} else {
+				assert.NotContains(t, logBuffer.String(), `"level":"WARN"`)
+			}
+		})
+	}
+}
+
+// TestSetupTelemetry_LogsConfiguration is not parallel because it relies on the global tracer provider.
+func TestSetupTelemetry_LogsConfiguration(t *testing.T) {
+	var logBuffer bytes.Buffer
+
+	cfg := &config.Config{
+		ShutdownTimeout: time.Second,
+		Telemetry: config.TelemetryConfig{
+			OTLPEndpoint:  "collector:4318",
+			OTLPEndpoints: []string{"user:secret-token@collector-b:4318", "https://collector-c:4318?api_key=secret-key"},
+			OTLPProtocol:  telemetry.ProtocolHTTP,
+			SampleRatio:   0.25,
+		},
+	}
+
+	closer, err := telemetry.SetupTelemetry(context.Background(), cfg,
+		telemetry.WithLogger(logging.New(logging.WithWriter(&logBuffer), logging.WithFormat(logging.FormatJSON))),
+		telemetry.WithExporterFactory(telemetry.ProtocolHTTP,
+			func(context.Context, string, telemetry.ExporterOptions) (trace.SpanExporter, error) {
+				return &stubExporter{}, nil
+			},
+		),
+	)
+	require.NoError(t, err)
+	require.NoError(t, closer.Close())
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &entry))
+
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "Telemetry configured", entry["msg"])
+	assert.Equal(t, []any{
+		"collector:4318",
+		"xxxxx@collector-b:4318",
+		"https://collector-c:4318?api_key=xxxxx",
+	}, entry["endpoints"])
+	assert.Equal(t, "http", entry["protocol"])
+	assert.Equal(t, 0.25, entry["sample_ratio"])
+	assert.Equal(t, true, entry["tracing_enabled"])
+	assert.Equal(t, false, entry["metrics_enabled"])
+	assert.NotContains(t, logBuffer.String(), "secret")
+}
@@ -0,0 +1,42 @@
+// Package tenant carries the tenant of a request in its context.
+// Repositories scope every query by this tenant, so each request sees only its tenant's data.
+//
+// The tenant is normally taken from the X-Tenant-Id header, which the server stores in the
+// context with a required header interceptor. It can also be set explicitly:
+//
+//	ctx = tenant.NewContext(ctx, "tenant-123")
+//
+//	if id, ok := tenant.FromContext(ctx); ok {
+//		// Scope queries by id
+//	}
+package tenant
+
+import (
+	"context"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/metadata"
+)
+
+// Header is the request header identifying the tenant.
+const Header = "X-Tenant-Id"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID of ctx. A tenant set with NewContext takes precedence over
+// the Header value in the request metadata. It reports false if ctx has no tenant.
+func FromContext(ctx context.Context) (string, bool) {
+	if id, ok := ctx.Value(contextKey{}).(string); ok && id != "" {
+		return id, true
+	}
+
+	if id := metadata.Value(ctx, Header); id != "" {
+		return id, true
+	}
+
+	return "", false
+}
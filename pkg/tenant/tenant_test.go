@@ -0,0 +1,97 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pannpers/go-backend-scaffold/pkg/metadata"
+	"github.com/pannpers/go-backend-scaffold/pkg/tenant"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		ctx    func() context.Context
+		want   string
+		wantOK bool
+	}{
+		{
+			name: "return tenant set explicitly",
+			ctx: func() context.Context {
+				return tenant.NewContext(context.Background(), "tenant-123")
+			},
+			want:   "tenant-123",
+			wantOK: true,
+		},
+		{
+			name: "return tenant from request metadata",
+			ctx: func() context.Context {
+				return metadata.NewContext(context.Background(), metadata.Metadata{tenant.Header: "tenant-456"})
+			},
+			want:   "tenant-456",
+			wantOK: true,
+		},
+		{
+			name: "prefer tenant set explicitly over request metadata",
+			ctx: func() context.Context {
+				ctx := metadata.NewContext(context.Background(), metadata.Metadata{tenant.Header: "tenant-456"})
+
+				return tenant.NewContext(ctx, "tenant-123")
+			},
+			want:   "tenant-123",
+			wantOK: true,
+		},
+		{
+			name:   "report false without tenant",
+			ctx:    context.Background,
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name: "report false for empty tenant",
+			ctx: func() context.Context {
+				return tenant.NewContext(context.Background(), "")
+			},
+			want:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tenant.FromContext(tt.ctx())
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestNewContext_NoCollision(t *testing.T) {
+	t.Parallel()
+
+	type stringKey string
+
+	// Values stored under the same names with other key types must not be seen as the tenant
+	ctx := context.WithValue(context.Background(), stringKey("tenant"), "tenant-string")
+	ctx = metadata.NewContext(ctx, metadata.Metadata{"X-Request-Id": "request-789"})
+
+	_, ok := tenant.FromContext(ctx)
+	assert.False(t, ok, "expected no tenant from values with other keys")
+
+	ctx = tenant.NewContext(ctx, "tenant-123")
+
+	got, ok := tenant.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-123", got)
+
+	// Setting the tenant leaves the other values intact
+	assert.Equal(t, "tenant-string", ctx.Value(stringKey("tenant")))
+	assert.Equal(t, "request-789", metadata.Value(ctx, "X-Request-Id"))
+}